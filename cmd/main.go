@@ -5,11 +5,17 @@ import (
 	"concall-analyser/internal/controller"
 	"concall-analyser/internal/db"
 	"concall-analyser/internal/interfaces"
+	"concall-analyser/internal/middleware"
 	"concall-analyser/internal/repository/mongo"
 	"concall-analyser/internal/service/analytics"
+	"concall-analyser/internal/service/auth"
+	"concall-analyser/internal/service/eventbus"
+	"concall-analyser/internal/service/scheduler"
+	"concall-analyser/internal/service/webhook"
 	"concall-analyser/internal/usecase"
 	ws "concall-analyser/internal/websocket"
 	"context"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
@@ -35,6 +41,9 @@ type App struct {
 	Usecase     interfaces.Usecase
 	MongoClient *db.MongoClient
 	Config      *config.Config
+	Scheduler   scheduler.Scheduler
+	Retrier     scheduler.Scheduler
+	Webhooks    webhook.Dispatcher
 }
 
 func main() {
@@ -50,7 +59,7 @@ func main() {
 		}
 	}()
 
-	gracefulShutdown(srv, mongoClient)
+	gracefulShutdown(srv, mongoClient, app.Scheduler, app.Retrier, app.Webhooks)
 
 }
 
@@ -59,26 +68,87 @@ func NewApp() *App {
 
 	client, db := GetMongo(cfg)
 
-	// Initialize WebSocket hub for real-time updates
-	hub := ws.NewHub()
+	// Initialize WebSocket hub for real-time updates, fed by a bus shared by all replicas
+	busCtx, cancelBus := context.WithTimeout(context.Background(), 30*time.Second)
+	bus, err := eventbus.New(busCtx, cfg, db)
+	cancelBus()
+	if err != nil {
+		log.Fatalf("❌ Failed to create event bus: %v", err)
+	}
+	hub := ws.NewHub(bus, ws.HubOptions{
+		ClientBuffer:     cfg.WSClientBuffer,
+		BroadcastBuffer:  cfg.WSBroadcastBuffer,
+		SlowClientPolicy: cfg.WSSlowClientPolicy,
+		ReplayBuffer:     cfg.SSEReplayBuffer,
+		Watchlists:       mongo.NewWatchlistRepository(db),
+	})
 	go hub.Run()
-	log.Println("✅ WebSocket hub started")
+	log.Printf("✅ WebSocket hub started on the %s bus", bus.Name())
 
 	// Initialize analytics service (shared between usecase and middleware)
 	analyticsRepo := mongo.NewAnalyticsRepository(db)
 	analyticsService := analytics.NewAnalyticsService(analyticsRepo, hub)
 
-	usecaseInstance, err := usecase.NewConcallFetcher(db, cfg, analyticsService)
+	authService, err := newAuthService(cfg, db)
+	if err != nil {
+		log.Fatalf("❌ Failed to initialize authentication: %v", err)
+	}
+
+	// Send new summaries to registered webhooks, retrying failed deliveries in the background
+	webhooks := webhook.NewDispatcher(
+		mongo.NewWebhookRepository(db),
+		mongo.NewWebhookDeliveryRepository(db),
+		mongo.NewWatchlistRepository(db),
+		webhook.Options{
+			MaxAttempts:   cfg.WebhookMaxAttempts,
+			Timeout:       cfg.WebhookTimeout,
+			RetryInterval: cfg.WebhookRetryInterval,
+		},
+	)
+
+	usecaseInstance, err := usecase.NewConcallFetcher(db, cfg, analyticsService, authService, webhooks, hub)
 	if err != nil {
 		log.Fatalf("❌ Failed to create usecase: %v", err)
 	}
-	router := gin.Default()
+
+	// Start scheduled ingestion when enabled
+	var fetchScheduler scheduler.Scheduler
+	if cfg.ScheduleEnabled {
+		fetchScheduler, err = scheduler.NewScheduler(cfg.FetchSchedule, cfg.FetchLookbackDays, usecaseInstance, mongo.NewIngestionCursorRepository(db))
+		if err != nil {
+			log.Fatalf("❌ Failed to create scheduler: %v", err)
+		}
+		fetchScheduler.Start()
+	}
+
+	// Pick up a job that a previous process left unfinished, once its lease expires
+	go scheduler.ResumeInterrupted(context.Background(), usecaseInstance, cfg.LockTTL)
+
+	// Index and read the sector of companies stored before the company master list existed
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Hour)
+		defer cancel()
+		if err := usecaseInstance.SyncCompanies(ctx); err != nil {
+			log.Printf("⚠️ Failed to sync companies: %v", err)
+		}
+	}()
+
+	// Resume interrupted jobs and retry failed announcements in the background
+	var retrier scheduler.Scheduler
+	if cfg.RetryEnabled {
+		retrier = scheduler.NewRetrier(cfg.RetryInterval, usecaseInstance)
+		retrier.Start()
+	}
+	webhooks.Start()
+	// gin's default logger would write access_token query parameters to the logs
+	router := gin.New()
+	router.Use(middleware.Logger(), gin.Recovery())
 
 	// Enable CORS for API routes
-	router.Use(corsMiddleware())
+	router.Use(corsMiddleware(cfg.CORSAllowedOrigins))
 
 	// Register API routes (prefixed with /api) and WebSocket endpoint
-	controller.RegisterRoutes(router, usecaseInstance, analyticsService, hub)
+	controller.RegisterRoutes(router, usecaseInstance, analyticsService, authService, cfg.AnonymousRole, hub)
 
 	// Serve static frontend assets
 	router.Static("/static", "./frontend/build/static")
@@ -103,14 +173,56 @@ func NewApp() *App {
 		Usecase:     usecaseInstance,
 		MongoClient: client,
 		Config:      cfg,
+		Scheduler:   fetchScheduler,
+		Retrier:     retrier,
+		Webhooks:    webhooks,
+	}
+}
+
+// newAuthService creates the auth service and the first admin from ADMIN_EMAIL
+// and ADMIN_PASSWORD when there are no users yet
+func newAuthService(cfg *config.Config, db *db.MongoDB) (auth.AuthService, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	userRepo := mongo.NewUserRepository(db)
+	if err := userRepo.EnsureIndexes(ctx); err != nil {
+		return nil, fmt.Errorf("failed to ensure user indexes: %w", err)
+	}
+	apiKeyRepo := mongo.NewAPIKeyRepository(db)
+	if err := apiKeyRepo.EnsureIndexes(ctx); err != nil {
+		return nil, fmt.Errorf("failed to ensure api key indexes: %w", err)
+	}
+
+	authService := auth.NewAuthService(userRepo, apiKeyRepo, []byte(cfg.JWTSecret), cfg.SessionTTL)
+	if cfg.AdminEmail != "" && cfg.AdminPassword != "" {
+		if err := authService.EnsureAdmin(ctx, cfg.AdminEmail, cfg.AdminPassword); err != nil {
+			return nil, err
+		}
 	}
+	return authService, nil
 }
 
-func corsMiddleware() gin.HandlerFunc {
+// corsMiddleware lets browsers on the allowed origins call the API with
+// credentials. Credentials cannot be combined with a wildcard, so "*" allows
+// any origin without them.
+func corsMiddleware(allowedOrigins []string) gin.HandlerFunc {
+	allowed := make(map[string]bool, len(allowedOrigins))
+	for _, origin := range allowedOrigins {
+		allowed[origin] = true
+	}
+
 	return func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
+		origin := c.GetHeader("Origin")
+		switch {
+		case origin != "" && allowed[origin]:
+			c.Writer.Header().Set("Access-Control-Allow-Origin", origin)
+			c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
+			c.Writer.Header().Add("Vary", "Origin")
+		case allowed["*"]:
+			c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
+		}
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, X-API-Key, accept, origin, Cache-Control, X-Requested-With, Last-Event-ID")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE, PATCH")
 
 		if c.Request.Method == "OPTIONS" {
@@ -148,13 +260,19 @@ func GetMongo(cfg *config.Config) (*db.MongoClient, *db.MongoDB) {
 	return mongoClient, mongoDB
 }
 
-func gracefulShutdown(srv *http.Server, client *db.MongoClient) {
+func gracefulShutdown(srv *http.Server, client *db.MongoClient, schedulers ...scheduler.Scheduler) {
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
 	log.Println("🛑 Shutting down server gracefully...")
 
+	for _, s := range schedulers {
+		if s != nil {
+			s.Stop()
+		}
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 3600*time.Second)
 	defer cancel()
 
@@ -1,15 +1,25 @@
 package config
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 	"strings"
+	"time"
+
+	"concall-analyser/internal/domain"
 
 	"github.com/joho/godotenv"
 	"github.com/spf13/viper"
 )
 
+// minLLMChunkChars is the smallest LLM_MAX_CHUNK_CHARS that leaves room for
+// more than page headers in a chunk
+const minLLMChunkChars = 1000
+
 type Config struct {
 	Port        string
 	MongoURI    string
@@ -20,6 +30,87 @@ type Config struct {
 	BaseURL     string
 	DestDir     string
 	MaxWorkers  int
+
+	// LLMProvider selects the summarizer backend: "gemini" or "openai" (any OpenAI-compatible endpoint)
+	LLMProvider string
+	LLMModel    string
+	LLMBaseURL  string
+	LLMAPIKey   string
+	LLMRPM      int // LLM requests per minute shared by all workers
+	LLMBurst    int
+	// LLMMaxChunkChars caps the transcript text sent in one call; longer transcripts are split by page
+	LLMMaxChunkChars int
+
+	// TargetFiscalYears overrides the fiscal years guidance is extracted for (e.g. FY26,FY27).
+	// When empty they are derived from each announcement's date.
+	TargetFiscalYears  []string
+	FiscalYearHorizons int
+
+	// StorageBackend selects where raw transcript PDFs are archived: "local" (under ArchiveDir) or "s3"
+	StorageBackend string
+	ArchiveDir     string
+	S3Endpoint     string
+	S3Bucket       string
+	S3AccessKey    string
+	S3SecretKey    string
+	S3Region       string
+	S3UseSSL       bool
+
+	// ScheduleEnabled turns on the in-process fetch scheduler. FetchSchedule is a
+	// five-field cron spec in IST, e.g. "0 19 * * *" for 7pm every day.
+	ScheduleEnabled   bool
+	FetchSchedule     string
+	FetchLookbackDays int
+
+	// LockTTL is how long the ingestion lock survives without a heartbeat, e.g. after a crash
+	LockTTL time.Duration
+
+	// Failed announcements are retried in the background every RetryInterval, waiting
+	// RetryBaseDelay doubled per attempt, until RetryMaxAttempts is reached
+	RetryEnabled     bool
+	RetryInterval    time.Duration
+	RetryBaseDelay   time.Duration
+	RetryMaxAttempts int
+
+	// EventBus carries real-time updates between replicas: "mongo" (change streams,
+	// needs a replica set), "memory" (this replica only) or "auto" to pick mongo when available
+	EventBus string
+
+	// WebSocket buffers: messages queued per client and waiting to be routed. A client
+	// whose buffer is full has messages dropped ("drop") or is disconnected ("disconnect").
+	WSClientBuffer     int
+	WSBroadcastBuffer  int
+	WSSlowClientPolicy string
+	// SSEReplayBuffer is how many recent messages /api/events keeps for clients resuming with Last-Event-ID
+	SSEReplayBuffer int
+
+	// JWTSecret signs session tokens, which stay valid for SessionTTL. Required in prod;
+	// locally a random secret is generated, so sessions end when the server restarts.
+	JWTSecret  string
+	SessionTTL time.Duration
+	// AdminEmail and AdminPassword create the first admin when there are no users yet
+	AdminEmail    string
+	AdminPassword string
+	// AnonymousRole is what requests without credentials may do; empty requires a login for everything
+	AnonymousRole domain.Role
+	// CORSAllowedOrigins are the origins allowed to call the API from a browser with credentials
+	CORSAllowedOrigins []string
+
+	// Webhook deliveries are tried WebhookMaxAttempts times, each bounded by WebhookTimeout;
+	// due retries are looked for every WebhookRetryInterval
+	WebhookMaxAttempts   int
+	WebhookTimeout       time.Duration
+	WebhookRetryInterval time.Duration
+
+	// SMTP server email digests are sent through after each ingestion run; digests
+	// are only sent when SMTPHost is set
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	SMTPFrom     string
+	// SiteURL is the public address of the site, used for links in emails
+	SiteURL string
 }
 
 // LoadConfig loads environment-specific config safely
@@ -37,6 +128,7 @@ func LoadConfig() (*Config, error) {
 	}
 
 	viper.AutomaticEnv() // read environment variables for prod in containerized application
+	viper.SetDefault("RETRY_ENABLED", true)
 
 	cfg := &Config{
 		Env:         strings.ToLower(env),
@@ -47,6 +139,60 @@ func LoadConfig() (*Config, error) {
 		BaseURL:     viper.GetString("BASE_URL"),
 		DestDir:     viper.GetString("DEST_DIR"),
 		MaxWorkers:  viper.GetInt("MAX_WORKERS"),
+
+		LLMProvider: strings.ToLower(viper.GetString("LLM_PROVIDER")),
+		LLMModel:    viper.GetString("LLM_MODEL"),
+		LLMBaseURL:  viper.GetString("LLM_BASE_URL"),
+		LLMAPIKey:   viper.GetString("LLM_API_KEY"),
+		LLMRPM:      viper.GetInt("LLM_RPM"),
+		LLMBurst:    viper.GetInt("LLM_BURST"),
+
+		LLMMaxChunkChars: viper.GetInt("LLM_MAX_CHUNK_CHARS"),
+
+		FiscalYearHorizons: viper.GetInt("FISCAL_YEAR_HORIZONS"),
+
+		StorageBackend: strings.ToLower(viper.GetString("STORAGE_BACKEND")),
+		ArchiveDir:     viper.GetString("ARCHIVE_DIR"),
+		S3Endpoint:     viper.GetString("S3_ENDPOINT"),
+		S3Bucket:       viper.GetString("S3_BUCKET"),
+		S3AccessKey:    viper.GetString("S3_ACCESS_KEY"),
+		S3SecretKey:    viper.GetString("S3_SECRET_KEY"),
+		S3Region:       viper.GetString("S3_REGION"),
+		S3UseSSL:       viper.GetBool("S3_USE_SSL"),
+
+		ScheduleEnabled:   viper.GetBool("SCHEDULE_ENABLED"),
+		FetchSchedule:     viper.GetString("FETCH_SCHEDULE"),
+		FetchLookbackDays: viper.GetInt("FETCH_LOOKBACK_DAYS"),
+
+		LockTTL: time.Duration(viper.GetInt("LOCK_TTL_SECONDS")) * time.Second,
+
+		RetryEnabled:     viper.GetBool("RETRY_ENABLED"),
+		RetryInterval:    time.Duration(viper.GetInt("RETRY_INTERVAL_MINUTES")) * time.Minute,
+		RetryBaseDelay:   time.Duration(viper.GetInt("RETRY_BASE_DELAY_MINUTES")) * time.Minute,
+		RetryMaxAttempts: viper.GetInt("RETRY_MAX_ATTEMPTS"),
+
+		EventBus: strings.ToLower(viper.GetString("EVENT_BUS")),
+
+		WSClientBuffer:     viper.GetInt("WS_CLIENT_BUFFER"),
+		WSBroadcastBuffer:  viper.GetInt("WS_BROADCAST_BUFFER"),
+		WSSlowClientPolicy: strings.ToLower(viper.GetString("WS_SLOW_CLIENT_POLICY")),
+		SSEReplayBuffer:    viper.GetInt("SSE_REPLAY_BUFFER"),
+
+		JWTSecret:     viper.GetString("JWT_SECRET"),
+		SessionTTL:    time.Duration(viper.GetInt("SESSION_TTL_HOURS")) * time.Hour,
+		AdminEmail:    viper.GetString("ADMIN_EMAIL"),
+		AdminPassword: viper.GetString("ADMIN_PASSWORD"),
+
+		WebhookMaxAttempts:   viper.GetInt("WEBHOOK_MAX_ATTEMPTS"),
+		WebhookTimeout:       time.Duration(viper.GetInt("WEBHOOK_TIMEOUT_SECONDS")) * time.Second,
+		WebhookRetryInterval: time.Duration(viper.GetInt("WEBHOOK_RETRY_INTERVAL_SECONDS")) * time.Second,
+
+		SMTPHost:     viper.GetString("SMTP_HOST"),
+		SMTPPort:     viper.GetInt("SMTP_PORT"),
+		SMTPUsername: viper.GetString("SMTP_USERNAME"),
+		SMTPPassword: viper.GetString("SMTP_PASSWORD"),
+		SMTPFrom:     viper.GetString("SMTP_FROM"),
+		SiteURL:      viper.GetString("SITE_URL"),
 	}
 
 	// Set hostname dynamically based on environment
@@ -76,9 +222,142 @@ func LoadConfig() (*Config, error) {
 	if cfg.MaxWorkers == 0 {
 		cfg.MaxWorkers = 20
 	}
+	if cfg.MaxWorkers < 1 {
+		return nil, fmt.Errorf("invalid MAX_WORKERS %d (expected at least 1)", cfg.MaxWorkers)
+	}
+	if cfg.LLMProvider == "" {
+		cfg.LLMProvider = "gemini"
+	}
+	if cfg.LLMAPIKey == "" && cfg.LLMProvider == "gemini" {
+		cfg.LLMAPIKey = cfg.APIKey
+	}
+	if viper.IsSet("LLM_RPM") && cfg.LLMRPM <= 0 {
+		return nil, fmt.Errorf("invalid LLM_RPM %q (expected a positive number)", viper.GetString("LLM_RPM"))
+	}
+	if cfg.LLMRPM == 0 {
+		cfg.LLMRPM = 60
+	}
+	if cfg.LLMBurst == 0 {
+		cfg.LLMBurst = 5
+	}
+	if cfg.LLMMaxChunkChars == 0 {
+		cfg.LLMMaxChunkChars = 60000
+	}
+	if cfg.LLMMaxChunkChars < minLLMChunkChars {
+		return nil, fmt.Errorf("invalid LLM_MAX_CHUNK_CHARS %d (expected at least %d)", cfg.LLMMaxChunkChars, minLLMChunkChars)
+	}
+	if cfg.FiscalYearHorizons == 0 {
+		cfg.FiscalYearHorizons = 2
+	}
+	if cfg.StorageBackend == "" {
+		cfg.StorageBackend = "local"
+	}
+	if cfg.ArchiveDir == "" {
+		cfg.ArchiveDir = filepath.Join(cfg.DestDir, "archive")
+	}
+	if cfg.FetchSchedule == "" {
+		cfg.FetchSchedule = "0 19 * * *"
+	}
+	if cfg.FetchLookbackDays == 0 {
+		cfg.FetchLookbackDays = 3
+	}
+	// A lock TTL or retry interval of zero or less would panic the tickers built from them
+	if viper.IsSet("LOCK_TTL_SECONDS") && cfg.LockTTL <= 0 {
+		return nil, fmt.Errorf("invalid LOCK_TTL_SECONDS %q (expected a positive number)", viper.GetString("LOCK_TTL_SECONDS"))
+	}
+	if cfg.LockTTL == 0 {
+		cfg.LockTTL = 60 * time.Second
+	}
+	if viper.IsSet("RETRY_INTERVAL_MINUTES") && cfg.RetryInterval <= 0 {
+		return nil, fmt.Errorf("invalid RETRY_INTERVAL_MINUTES %q (expected a positive number)", viper.GetString("RETRY_INTERVAL_MINUTES"))
+	}
+	if cfg.RetryInterval == 0 {
+		cfg.RetryInterval = 10 * time.Minute
+	}
+	if cfg.RetryBaseDelay == 0 {
+		cfg.RetryBaseDelay = 5 * time.Minute
+	}
+	if cfg.RetryMaxAttempts == 0 {
+		cfg.RetryMaxAttempts = 5
+	}
+	if cfg.EventBus == "" {
+		cfg.EventBus = "auto"
+	}
+	switch cfg.WSSlowClientPolicy {
+	case "", "drop", "disconnect":
+	default:
+		return nil, fmt.Errorf("invalid WS_SLOW_CLIENT_POLICY %q (expected drop or disconnect)", cfg.WSSlowClientPolicy)
+	}
+	if cfg.JWTSecret == "" {
+		if cfg.Env == "prod" {
+			return nil, fmt.Errorf("JWT_SECRET environment variable must be set for production")
+		}
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, fmt.Errorf("failed to generate JWT secret: %w", err)
+		}
+		cfg.JWTSecret = hex.EncodeToString(secret)
+		log.Println("⚠️ JWT_SECRET not set, sessions will not survive a restart")
+	}
+	if cfg.SessionTTL == 0 {
+		cfg.SessionTTL = 12 * time.Hour
+	}
+	switch role := strings.ToLower(viper.GetString("ANONYMOUS_ROLE")); role {
+	case "":
+		cfg.AnonymousRole = domain.RoleViewer
+	case "none":
+	default:
+		parsed, err := domain.ParseRole(role)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ANONYMOUS_ROLE: %w", err)
+		}
+		cfg.AnonymousRole = parsed
+	}
+	if cfg.WebhookMaxAttempts == 0 {
+		cfg.WebhookMaxAttempts = 6
+	}
+	if cfg.WebhookTimeout == 0 {
+		cfg.WebhookTimeout = 10 * time.Second
+	}
+	if cfg.WebhookRetryInterval == 0 {
+		cfg.WebhookRetryInterval = 30 * time.Second
+	}
+	if cfg.SMTPPort == 0 {
+		cfg.SMTPPort = 587
+	}
+	if cfg.SMTPHost != "" && cfg.SMTPFrom == "" {
+		if cfg.Env == "prod" {
+			return nil, fmt.Errorf("SMTP_FROM environment variable must be set when SMTP_HOST is")
+		}
+		cfg.SMTPFrom = "Concall-Analyser <digest@localhost>"
+	}
+	if cfg.SiteURL == "" {
+		if cfg.Env == "prod" {
+			cfg.SiteURL = "https://" + cfg.Host
+		} else {
+			cfg.SiteURL = "http://" + cfg.Host
+		}
+	}
+	if origins := viper.GetString("CORS_ALLOWED_ORIGINS"); origins != "" {
+		for _, origin := range strings.Split(origins, ",") {
+			if origin = strings.TrimSpace(origin); origin != "" {
+				cfg.CORSAllowedOrigins = append(cfg.CORSAllowedOrigins, origin)
+			}
+		}
+	} else if cfg.Env == "local" {
+		// The React dev server
+		cfg.CORSAllowedOrigins = []string{"http://localhost:3000"}
+	}
+	if fys := viper.GetString("TARGET_FISCAL_YEARS"); fys != "" {
+		parsed, err := domain.ParseFiscalYears(fys)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TARGET_FISCAL_YEARS: %w", err)
+		}
+		cfg.TargetFiscalYears = parsed
+	}
 
 	// Log safe info only
-	log.Printf("📦 Loaded Config: Env=%s, Port=%s, DB=%s", cfg.Env, cfg.Port, cfg.MongoDBName)
+	log.Printf("📦 Loaded Config: Env=%s, Port=%s, DB=%s, LLM=%s, Storage=%s", cfg.Env, cfg.Port, cfg.MongoDBName, cfg.LLMProvider, cfg.StorageBackend)
 
 	return cfg, nil
 }
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// setRequired sets the environment a production config needs
+func setRequired(t *testing.T) {
+	t.Helper()
+	t.Setenv("CONFIG_ENV", "prod")
+	t.Setenv("HOST", "concalls.example.com")
+	t.Setenv("MONGO_URI", "mongodb://localhost:27017")
+	t.Setenv("MONGO_DB", "concalls")
+	t.Setenv("JWT_SECRET", "secret")
+}
+
+func TestLoadConfigLockTTL(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{value: "", want: 60 * time.Second},
+		{value: "90", want: 90 * time.Second},
+		{value: "0", wantErr: true},
+		{value: "-30", wantErr: true},
+		{value: "soon", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			setRequired(t)
+			t.Setenv("LOCK_TTL_SECONDS", tt.value)
+
+			cfg, err := LoadConfig()
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "LOCK_TTL_SECONDS") {
+					t.Fatalf("LoadConfig() error = %v, want one naming LOCK_TTL_SECONDS", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadConfig() error = %v", err)
+			}
+			if cfg.LockTTL != tt.want {
+				t.Errorf("LockTTL = %v, want %v", cfg.LockTTL, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadConfigRetryIntervalAndRPM(t *testing.T) {
+	tests := []struct {
+		key     string
+		value   string
+		check   func(*Config) bool
+		wantErr bool
+	}{
+		{key: "RETRY_INTERVAL_MINUTES", value: "", check: func(c *Config) bool { return c.RetryInterval == 10*time.Minute }},
+		{key: "RETRY_INTERVAL_MINUTES", value: "5", check: func(c *Config) bool { return c.RetryInterval == 5*time.Minute }},
+		{key: "RETRY_INTERVAL_MINUTES", value: "0", wantErr: true},
+		{key: "RETRY_INTERVAL_MINUTES", value: "-10", wantErr: true},
+		{key: "LLM_RPM", value: "", check: func(c *Config) bool { return c.LLMRPM == 60 }},
+		{key: "LLM_RPM", value: "15", check: func(c *Config) bool { return c.LLMRPM == 15 }},
+		{key: "LLM_RPM", value: "0", wantErr: true},
+		{key: "LLM_RPM", value: "-1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key+"="+tt.value, func(t *testing.T) {
+			setRequired(t)
+			t.Setenv(tt.key, tt.value)
+
+			cfg, err := LoadConfig()
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), tt.key) {
+					t.Fatalf("LoadConfig() error = %v, want one naming %s", err, tt.key)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadConfig() error = %v", err)
+			}
+			if !tt.check(cfg) {
+				t.Errorf("LoadConfig() with %s=%q = %+v", tt.key, tt.value, cfg)
+			}
+		})
+	}
+}
@@ -3,7 +3,9 @@ import './App.css';
 import ConcallList from './components/ConcallList';
 import SearchBar from './components/SearchBar';
 import Analytics from './components/Analytics';
+import IngestionProgress from './components/IngestionProgress';
 import { fetchConcalls, searchConcalls } from './services/api';
+import { subscribe } from './services/socket';
 
 function App() {
   const [concalls, setConcalls] = useState([]);
@@ -47,6 +49,30 @@ function App() {
     loadConcalls(1);
   }, []);
 
+  // Show newly stored guidance on the first page without reloading
+  useEffect(() => {
+    if (isSearchMode || currentPage !== 1) {
+      return undefined;
+    }
+
+    return subscribe('ingestion', (envelope) => {
+      if (envelope.type !== 'concall_added') {
+        return;
+      }
+      const concall = envelope.data?.concall;
+      if (!concall || concall.guidance === 'NA') {
+        return;
+      }
+      setConcalls((current) => {
+        if (current.some((c) => c.news_id && c.news_id === concall.news_id)) {
+          return current;
+        }
+        return [concall, ...current];
+      });
+      setTotal((current) => current + 1);
+    });
+  }, [isSearchMode, currentPage]);
+
   const handlePageChange = (newPage) => {
     if (newPage >= 1 && newPage <= totalPages) {
       if (isSearchMode && searchQuery) {
@@ -83,6 +109,7 @@ function App() {
         <div className="analytics">
           <Analytics />
         </div>
+        <IngestionProgress />
         <SearchBar 
           onSearch={handleSearch}
           onClear={handleClearSearch}
@@ -1,5 +1,6 @@
 import React, { useState, useEffect, useRef } from 'react';
 import { getAnalytics } from '../services/api';
+import { subscribe } from '../services/socket';
 import './Analytics.css';
 
 let analyticsCache = {
@@ -7,11 +8,7 @@ let analyticsCache = {
   loading: false,
   error: null,
   promise: null,
-  subscribers: new Set(),
-  wsConnection: null,
-  wsReconnectAttempts: 0,
-  maxReconnectAttempts: 5,
-  reconnectDelay: 3000
+  subscribers: new Set()
 };
 
 function notifySubscribers(data, error) {
@@ -24,76 +21,11 @@ function notifySubscribers(data, error) {
   });
 }
 
-function getWebSocketURL() {
-  const protocol = window.location.protocol === 'https:' ? 'wss:' : 'ws:';
-  
-  let host;
-  if (window.location.hostname === 'localhost' && window.location.port === '3000') {
-    host = 'localhost:8080';
-  } else {
-    host = window.location.host;
-  }
-  
-  const url = `${protocol}//${host}/ws/analytics`;
-  console.log('WebSocket URL:', url);
-  return url;
-}
-
-function connectWebSocket() {
-  if (analyticsCache.wsConnection && analyticsCache.wsConnection.readyState === WebSocket.OPEN) {
-    return;
-  }
-
-  try {
-    const ws = new WebSocket(getWebSocketURL());
-    
-    ws.onopen = () => {
-      console.log('WebSocket connected for analytics');
-      analyticsCache.wsReconnectAttempts = 0;
-    };
-
-    ws.onmessage = (event) => {
-      try {
-        console.log('WebSocket message received:', event.data);
-        const update = JSON.parse(event.data);
-        console.log('Parsed update:', update);
-        if (update.type === 'analytics_update' && update.total_visits !== undefined) {
-          console.log('Updating analytics with total_visits:', update.total_visits);
-          analyticsCache.data = { total_visits: update.total_visits };
-          notifySubscribers(analyticsCache.data, null);
-        } else {
-          console.warn('Unexpected message format:', update);
-        }
-      } catch (err) {
-        console.error('Error parsing WebSocket message:', err, event.data);
-      }
-    };
-
-    ws.onerror = (error) => {
-      console.error('WebSocket error:', error);
-      console.error('WebSocket URL:', getWebSocketURL());
-    };
-
-    ws.onclose = () => {
-      console.log('WebSocket disconnected');
-      analyticsCache.wsConnection = null;
-      
-      if (analyticsCache.wsReconnectAttempts < analyticsCache.maxReconnectAttempts) {
-        analyticsCache.wsReconnectAttempts++;
-        const delay = analyticsCache.reconnectDelay * analyticsCache.wsReconnectAttempts;
-        console.log(`Attempting to reconnect WebSocket in ${delay}ms (attempt ${analyticsCache.wsReconnectAttempts})`);
-        setTimeout(() => {
-          connectWebSocket();
-        }, delay);
-      } else {
-        console.log('Max WebSocket reconnect attempts reached');
-      }
-    };
-
-    analyticsCache.wsConnection = ws;
-  } catch (err) {
-    console.error('Failed to create WebSocket connection:', err);
-  }
+function handleAnalyticsUpdate(envelope) {
+  const update = envelope.data;
+  if (envelope.type !== 'analytics_update' || update?.total_visits === undefined) return;
+  analyticsCache.data = { total_visits: update.total_visits };
+  notifySubscribers(analyticsCache.data, null);
 }
 
 function Analytics() {
@@ -160,11 +92,12 @@ function Analytics() {
       })();
     }
 
-    connectWebSocket();
+    const unsubscribe = subscribe('analytics', handleAnalyticsUpdate);
 
     return () => {
       mountedRef.current = false;
       analyticsCache.subscribers.delete(setState);
+      unsubscribe();
     };
   }, []);
 
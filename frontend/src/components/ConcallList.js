@@ -40,16 +40,33 @@ function ConcallList({ concalls, loading, isSearchMode, searchQuery, total }) {
               <span className="date-badge">{concall.date}</span>
             </div>
             <div className="card-body">
-              <div className="guidance-section">
-                <span className="guidance-label">FY26 Guidance:</span>
-                <p className={`guidance-text ${concall.guidance === 'NA' ? 'no-guidance' : ''}`}>
-                  {concall.guidance === 'NA' ? (
-                    <span className="na-text">No guidance provided</span>
-                  ) : (
-                    concall.guidance
-                  )}
-                </p>
-              </div>
+              {concall.guidance_by_fy && concall.guidance_by_fy.length > 0 ? (
+                <div className="guidance-fy-grid">
+                  {concall.guidance_by_fy.map((fy) => (
+                    <div key={fy.fiscal_year} className="guidance-section">
+                      <span className="guidance-label">{fy.fiscal_year} Guidance:</span>
+                      <p className={`guidance-text ${fy.summary === 'NA' ? 'no-guidance' : ''}`}>
+                        {fy.summary === 'NA' ? (
+                          <span className="na-text">No guidance provided</span>
+                        ) : (
+                          fy.summary
+                        )}
+                      </p>
+                    </div>
+                  ))}
+                </div>
+              ) : (
+                <div className="guidance-section">
+                  <span className="guidance-label">FY26 Guidance:</span>
+                  <p className={`guidance-text ${concall.guidance === 'NA' ? 'no-guidance' : ''}`}>
+                    {concall.guidance === 'NA' ? (
+                      <span className="na-text">No guidance provided</span>
+                    ) : (
+                      concall.guidance
+                    )}
+                  </p>
+                </div>
+              )}
             </div>
           </div>
         ))}
@@ -0,0 +1,93 @@
+import React, { useState, useEffect } from 'react';
+import { subscribe } from '../services/socket';
+import './IngestionProgress.css';
+
+// How long the result of a finished run stays on screen
+const FINISHED_DISPLAY_MS = 10000;
+
+function IngestionProgress() {
+  const [run, setRun] = useState(null);
+
+  useEffect(() => {
+    let hideTimer = null;
+
+    const onProgress = (event) => {
+      setRun((current) => ({
+        ...(current && current.jobId === event.job_id ? current : {}),
+        jobId: event.job_id,
+        state: 'running',
+        done: event.done ?? 0,
+        total: event.total ?? 0,
+        last: event.concall?.name || event.name || current?.last
+      }));
+    };
+
+    const handlers = {
+      run_started: (event) => {
+        clearTimeout(hideTimer);
+        setRun({ jobId: event.job_id, state: 'running', done: 0, total: event.total, failed: 0 });
+      },
+      announcement_downloaded: onProgress,
+      announcement_summarized: onProgress,
+      announcement_failed: (event) => {
+        onProgress(event);
+        setRun((current) => ({ ...current, failed: (current.failed || 0) + 1 }));
+      },
+      run_finished: (event) => {
+        setRun({
+          jobId: event.job_id,
+          state: event.state,
+          done: event.totals.processed,
+          total: event.totals.total,
+          succeeded: event.totals.succeeded,
+          failed: event.totals.failed
+        });
+        clearTimeout(hideTimer);
+        hideTimer = setTimeout(() => setRun(null), FINISHED_DISPLAY_MS);
+      }
+    };
+
+    const unsubscribe = subscribe('ingestion', (envelope) => {
+      const handler = handlers[envelope.type];
+      if (handler) handler(envelope.data);
+    });
+
+    return () => {
+      clearTimeout(hideTimer);
+      unsubscribe();
+    };
+  }, []);
+
+  if (!run) {
+    return null;
+  }
+
+  const percent = run.total > 0 ? Math.min(100, Math.round((run.done / run.total) * 100)) : 100;
+  const running = run.state === 'running';
+
+  return (
+    <div className="ingestion-progress">
+      <div className="ingestion-progress-header">
+        <span className="ingestion-progress-title">
+          {running ? '⚙️ Ingesting concalls' : `🏁 Ingestion ${run.state}`}
+        </span>
+        <span className="ingestion-progress-count">
+          {run.done}/{run.total}
+          {run.failed > 0 && <span className="ingestion-progress-failed"> · {run.failed} failed</span>}
+        </span>
+      </div>
+      <div className="ingestion-progress-track">
+        <div
+          className={`ingestion-progress-bar ${running ? '' : 'finished'}`}
+          style={{ width: `${percent}%` }}
+        />
+      </div>
+      {running && run.last && <div className="ingestion-progress-last">Latest: {run.last}</div>}
+      {!running && run.succeeded !== undefined && (
+        <div className="ingestion-progress-last">{run.succeeded} new summaries</div>
+      )}
+    </div>
+  );
+}
+
+export default IngestionProgress;
@@ -1,18 +1,79 @@
-import React, { useState } from 'react';
+import React, { useEffect, useRef, useState } from 'react';
+import { suggestCompanies } from '../services/api';
 import './SearchBar.css';
 
+// Wait for a pause in typing before asking for suggestions
+const SUGGEST_DELAY_MS = 250;
+
 function SearchBar({ onSearch, onClear, isSearchMode, searchQuery }) {
   const [inputValue, setInputValue] = useState(searchQuery || '');
+  const [suggestions, setSuggestions] = useState([]);
+  const [showSuggestions, setShowSuggestions] = useState(false);
+  const [activeIndex, setActiveIndex] = useState(-1);
+  const latestQuery = useRef('');
+
+  useEffect(() => {
+    const q = inputValue.trim();
+    latestQuery.current = q;
+    if (q.length < 2) {
+      setSuggestions([]);
+      return undefined;
+    }
+
+    const timer = setTimeout(async () => {
+      try {
+        const result = await suggestCompanies(q);
+        // Ignore answers to queries the user has typed past
+        if (latestQuery.current === q) {
+          setSuggestions(result.data || []);
+          setActiveIndex(-1);
+        }
+      } catch (err) {
+        setSuggestions([]);
+      }
+    }, SUGGEST_DELAY_MS);
+    return () => clearTimeout(timer);
+  }, [inputValue]);
+
+  const search = (value) => {
+    setShowSuggestions(false);
+    if (value.trim()) {
+      onSearch(value.trim());
+    }
+  };
 
   const handleSubmit = (e) => {
     e.preventDefault();
-    if (inputValue.trim()) {
-      onSearch(inputValue.trim());
+    if (showSuggestions && activeIndex >= 0 && suggestions[activeIndex]) {
+      handleSelect(suggestions[activeIndex]);
+      return;
+    }
+    search(inputValue);
+  };
+
+  const handleSelect = (company) => {
+    setInputValue(company.name);
+    search(company.name);
+  };
+
+  const handleKeyDown = (e) => {
+    if (!showSuggestions || suggestions.length === 0) {
+      return;
+    }
+    if (e.key === 'ArrowDown') {
+      e.preventDefault();
+      setActiveIndex((index) => (index + 1) % suggestions.length);
+    } else if (e.key === 'ArrowUp') {
+      e.preventDefault();
+      setActiveIndex((index) => (index <= 0 ? suggestions.length - 1 : index - 1));
+    } else if (e.key === 'Escape') {
+      setShowSuggestions(false);
     }
   };
 
   const handleClear = () => {
     setInputValue('');
+    setSuggestions([]);
     onClear();
   };
 
@@ -24,9 +85,16 @@ function SearchBar({ onSearch, onClear, isSearchMode, searchQuery }) {
           <input
             type="text"
             className="search-input"
-            placeholder="Search by company name..."
+            placeholder="Search by company name, symbol or scrip code..."
             value={inputValue}
-            onChange={(e) => setInputValue(e.target.value)}
+            autoComplete="off"
+            onChange={(e) => {
+              setInputValue(e.target.value);
+              setShowSuggestions(true);
+            }}
+            onKeyDown={handleKeyDown}
+            onFocus={() => setShowSuggestions(true)}
+            onBlur={() => setShowSuggestions(false)}
           />
           {isSearchMode && (
             <button
@@ -38,6 +106,27 @@ function SearchBar({ onSearch, onClear, isSearchMode, searchQuery }) {
               ✕
             </button>
           )}
+          {showSuggestions && suggestions.length > 0 && (
+            <ul className="suggestions">
+              {suggestions.map((company, index) => (
+                <li
+                  key={company.scrip_code}
+                  className={`suggestion${index === activeIndex ? ' active' : ''}`}
+                  // Select before the input's blur hides the list
+                  onMouseDown={(e) => {
+                    e.preventDefault();
+                    handleSelect(company);
+                  }}
+                  onMouseEnter={() => setActiveIndex(index)}
+                >
+                  <span className="suggestion-name">{company.name}</span>
+                  <span className="suggestion-meta">
+                    {[company.symbol, company.scrip_code, company.sector].filter(Boolean).join(' · ')}
+                  </span>
+                </li>
+              ))}
+            </ul>
+          )}
         </div>
         <button type="submit" className="search-btn">
           Search
@@ -53,4 +142,3 @@ function SearchBar({ onSearch, onClear, isSearchMode, searchQuery }) {
 }
 
 export default SearchBar;
-
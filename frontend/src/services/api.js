@@ -21,6 +21,12 @@ export const searchConcalls = async (name, page = 1, limit = 12) => {
   return handleResponse(response);
 };
 
+export const suggestCompanies = async (q, limit = 8) => {
+  const url = `${API_BASE_URL}/search/suggest?q=${encodeURIComponent(q)}&limit=${limit}`;
+  const response = await fetch(url);
+  return handleResponse(response);
+};
+
 // Prevent duplicate analytics requests within a short time window
 let lastAnalyticsCall = null;
 const ANALYTICS_CALL_COOLDOWN = 5000; // 5 seconds
@@ -0,0 +1,194 @@
+// A single real-time connection shared by every component. Components subscribe
+// to topics; the server only sends messages for the topics subscribed to, each
+// wrapped in an envelope: {"v": 1, "type": ..., "topics": [...], "data": ..., "ts": ...}
+//
+// The connection is a WebSocket. Where WebSocket upgrades are blocked it falls
+// back to the Server-Sent Events stream at /api/events, which resumes from the
+// last event it saw.
+const ENVELOPE_VERSION = 1;
+const API_BASE_URL = process.env.REACT_APP_API_URL || '/api';
+// Failed WebSocket attempts without ever connecting before falling back to SSE
+const WS_ATTEMPTS_BEFORE_SSE = 2;
+
+const socketState = {
+  connection: null,
+  everConnected: false,
+  reconnectAttempts: 0,
+  maxReconnectAttempts: 5,
+  reconnectDelay: 3000,
+  // topic -> set of handlers
+  handlers: new Map(),
+  requestId: 0,
+  eventSource: null,
+  lastEventId: ''
+};
+
+function getWebSocketURL() {
+  const protocol = window.location.protocol === 'https:' ? 'wss:' : 'ws:';
+
+  let host;
+  if (window.location.hostname === 'localhost' && window.location.port === '3000') {
+    host = 'localhost:8080';
+  } else {
+    host = window.location.host;
+  }
+
+  return `${protocol}//${host}/ws/analytics`;
+}
+
+function send(action, topics) {
+  if (socketState.eventSource) {
+    // An event stream's topics are fixed when it is opened
+    openEventSource();
+    return;
+  }
+
+  const ws = socketState.connection;
+  if (!ws || ws.readyState !== WebSocket.OPEN || topics.length === 0) {
+    return;
+  }
+  socketState.requestId++;
+  ws.send(JSON.stringify({ action, topics, id: String(socketState.requestId) }));
+}
+
+function dispatch(envelope) {
+  if (envelope.v !== ENVELOPE_VERSION) {
+    console.warn('Unsupported WebSocket envelope version:', envelope);
+    return;
+  }
+
+  switch (envelope.type) {
+    case 'subscribed':
+    case 'unsubscribed':
+      console.log(`WebSocket ${envelope.type}:`, envelope.data?.topics);
+      return;
+    case 'error':
+      console.error('WebSocket request rejected:', envelope.data?.error);
+      return;
+    case 'resync':
+      console.warn('Missed real-time updates while disconnected; reload to catch up');
+      return;
+    default:
+      break;
+  }
+
+  // A message published to several topics arrives once; hand it to each handler once
+  const delivered = new Set();
+  (envelope.topics || []).forEach((topic) => {
+    const handlers = socketState.handlers.get(topic);
+    if (!handlers) return;
+    handlers.forEach((handler) => {
+      if (delivered.has(handler)) return;
+      delivered.add(handler);
+      handler(envelope);
+    });
+  });
+}
+
+function openEventSource() {
+  if (socketState.eventSource) {
+    socketState.eventSource.close();
+  }
+
+  const params = new URLSearchParams();
+  params.set('topics', Array.from(socketState.handlers.keys()).join(','));
+  if (socketState.lastEventId) {
+    params.set('last_event_id', socketState.lastEventId);
+  }
+
+  const es = new EventSource(`${API_BASE_URL}/events?${params.toString()}`);
+  es.onmessage = (event) => {
+    if (event.lastEventId) {
+      socketState.lastEventId = event.lastEventId;
+    }
+    try {
+      dispatch(JSON.parse(event.data));
+    } catch (err) {
+      console.error('Error parsing event stream message:', err, event.data);
+    }
+  };
+  es.onerror = () => {
+    // EventSource reconnects by itself, sending Last-Event-ID
+    console.warn('Event stream interrupted, reconnecting');
+  };
+  socketState.eventSource = es;
+}
+
+function connect() {
+  if (socketState.connection || socketState.eventSource) {
+    return;
+  }
+
+  try {
+    const ws = new WebSocket(getWebSocketURL());
+
+    ws.onopen = () => {
+      console.log('WebSocket connected');
+      socketState.everConnected = true;
+      socketState.reconnectAttempts = 0;
+      send('subscribe', Array.from(socketState.handlers.keys()));
+    };
+
+    ws.onmessage = (event) => {
+      // The server may batch several envelopes into one frame, one per line
+      event.data.split('\n').forEach((line) => {
+        if (!line.trim()) return;
+        try {
+          dispatch(JSON.parse(line));
+        } catch (err) {
+          console.error('Error parsing WebSocket message:', err, line);
+        }
+      });
+    };
+
+    ws.onerror = (error) => {
+      console.error('WebSocket error:', error);
+    };
+
+    ws.onclose = () => {
+      console.log('WebSocket disconnected');
+      socketState.connection = null;
+
+      if (!socketState.everConnected && socketState.reconnectAttempts + 1 >= WS_ATTEMPTS_BEFORE_SSE) {
+        console.log('WebSocket looks blocked, falling back to Server-Sent Events');
+        openEventSource();
+        return;
+      }
+
+      if (socketState.reconnectAttempts < socketState.maxReconnectAttempts) {
+        socketState.reconnectAttempts++;
+        const delay = socketState.reconnectDelay * socketState.reconnectAttempts;
+        console.log(`Attempting to reconnect WebSocket in ${delay}ms (attempt ${socketState.reconnectAttempts})`);
+        setTimeout(connect, delay);
+      } else {
+        console.log('Max WebSocket reconnect attempts reached');
+      }
+    };
+
+    socketState.connection = ws;
+  } catch (err) {
+    console.error('Failed to create WebSocket connection:', err);
+  }
+}
+
+// subscribe calls handler with every envelope published to topic and returns
+// a function that removes it again. Topics are "analytics", "ingestion",
+// "company:<scrip code>" and "watchlist:<id>".
+export function subscribe(topic, handler) {
+  if (!socketState.handlers.has(topic)) {
+    socketState.handlers.set(topic, new Set());
+    send('subscribe', [topic]);
+  }
+  socketState.handlers.get(topic).add(handler);
+  connect();
+
+  return () => {
+    const handlers = socketState.handlers.get(topic);
+    if (!handlers) return;
+    handlers.delete(handler);
+    if (handlers.size === 0) {
+      socketState.handlers.delete(topic);
+      send('unsubscribe', [topic]);
+    }
+  };
+}
@@ -1,11 +1,14 @@
 package controller
 
 import (
+	"concall-analyser/internal/domain"
 	"concall-analyser/internal/interfaces"
 	"concall-analyser/internal/middleware"
 	"concall-analyser/internal/service/analytics"
+	"concall-analyser/internal/service/auth"
 	ws "concall-analyser/internal/websocket"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
@@ -17,9 +20,17 @@ var upgrader = websocket.Upgrader{
 	},
 }
 
-func RegisterRoutes(r *gin.Engine, u interfaces.Usecase, analyticsService analytics.AnalyticsService, hub *ws.Hub) {
+// RegisterRoutes wires the API. Requests are authenticated by session token or
+// API key and each route requires a role: viewers read, analysts also follow
+// jobs and failures, and only admins spend LLM credits or delete data.
+func RegisterRoutes(r *gin.Engine, u interfaces.Usecase, analyticsService analytics.AnalyticsService, authService auth.AuthService, anonymousRole domain.Role, hub *ws.Hub) {
 	r.Use(middleware.AnalyticsMiddleware(analyticsService))
 
+	authenticate := middleware.Authenticate(authService, anonymousRole)
+	viewer := middleware.RequireRole(domain.RoleViewer)
+	analyst := middleware.RequireRole(domain.RoleAnalyst)
+	admin := middleware.RequireRole(domain.RoleAdmin)
+
 	// Simple health check endpoint that does not touch the database.
 	// Useful for uptime pings (e.g., keeping Render free-tier dynos warm).
 	r.GET("/healthz", func(c *gin.Context) {
@@ -28,21 +39,92 @@ func RegisterRoutes(r *gin.Engine, u interfaces.Usecase, analyticsService analyt
 		})
 	})
 
-	r.GET("/ws/analytics", func(c *gin.Context) {
+	r.GET("/ws/analytics", authenticate, viewer, func(c *gin.Context) {
 		conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
 		if err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to upgrade connection"})
 			return
 		}
-		ws.ServeWs(hub, conn)
+		ws.ServeWs(hub, conn, middleware.CurrentPrincipal(c))
+	})
+
+	// Counters of delivered, coalesced and dropped real-time messages
+	r.GET("/ws/stats", authenticate, analyst, func(c *gin.Context) {
+		c.JSON(http.StatusOK, hub.Stats())
 	})
 
-	api := r.Group("/api")
+	api := r.Group("/api", authenticate)
 	{
-		api.GET("/fetch_concalls", u.FetchConcallDataHandler)
-		api.GET("/list_concalls", u.ListConcallHandler)
-		api.GET("/find_concalls", u.FindConcallHandler)
-		api.DELETE("/cleanup_concalls", u.CleanupConcallHandler)
-		api.GET("/analytics", u.GetAnalyticsHandler)
+		api.POST("/auth/login", u.LoginHandler)
+		api.GET("/auth/me", viewer, u.CurrentUserHandler)
+
+		// Server-Sent Events alternative to /ws/analytics, e.g. /api/events?topics=analytics,ingestion
+		api.GET("/events", viewer, func(c *gin.Context) {
+			var topics []string
+			if t := c.Query("topics"); t != "" {
+				topics = strings.Split(t, ",")
+			}
+			lastEventID := c.GetHeader("Last-Event-ID")
+			if lastEventID == "" {
+				// EventSource only sends the header when it reconnects by itself
+				lastEventID = c.Query("last_event_id")
+			}
+			if err := ws.ServeSSE(hub, c.Writer, c.Request, middleware.CurrentPrincipal(c), topics, lastEventID); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to open event stream", "details": err.Error()})
+			}
+		})
+		api.GET("/list_concalls", viewer, u.ListConcallHandler)
+		api.GET("/sectors", viewer, u.ListSectorsHandler)
+		api.GET("/find_concalls", viewer, u.FindConcallHandler)
+		api.GET("/search/suggest", viewer, u.SuggestCompaniesHandler)
+		api.GET("/analytics", viewer, u.GetAnalyticsHandler)
+		api.GET("/concalls/:news_id/document", viewer, u.DownloadDocumentHandler)
+		api.GET("/concalls/:news_id/versions", viewer, u.GetConcallVersionsHandler)
+
+		// Watchlists belong to the user who created them
+		watchlists := api.Group("/watchlists", viewer, middleware.RequireLogin())
+		{
+			watchlists.GET("", u.ListWatchlistsHandler)
+			watchlists.POST("", u.CreateWatchlistHandler)
+			watchlists.GET("/:id", u.GetWatchlistHandler)
+			watchlists.PATCH("/:id", u.RenameWatchlistHandler)
+			watchlists.DELETE("/:id", u.DeleteWatchlistHandler)
+			watchlists.POST("/:id/companies", u.AddWatchlistCompanyHandler)
+			watchlists.DELETE("/:id/companies/:scrip_code", u.RemoveWatchlistCompanyHandler)
+			watchlists.GET("/:id/concalls", u.WatchlistConcallsHandler)
+		}
+
+		// Email digests are sent to the address a user logs in with
+		digest := api.Group("/digest", viewer, middleware.RequireLogin())
+		{
+			digest.GET("/subscription", u.GetDigestSubscriptionHandler)
+			digest.PUT("/subscription", u.UpdateDigestSubscriptionHandler)
+			digest.DELETE("/subscription", u.DeleteDigestSubscriptionHandler)
+			digest.GET("/preview", u.PreviewDigestHandler)
+		}
+
+		api.GET("/jobs/:id", analyst, u.GetJobHandler)
+		api.GET("/ingestion/failures", analyst, u.ListIngestionFailuresHandler)
+
+		api.GET("/fetch_concalls", admin, u.FetchConcallDataHandler)
+		api.DELETE("/cleanup_concalls", admin, u.CleanupConcallHandler)
+		api.DELETE("/jobs/:id", admin, u.CancelJobHandler)
+		api.POST("/concalls/reprocess", admin, u.ReprocessConcallsHandler)
+		api.POST("/ingestion/retry", admin, u.RetryIngestionHandler)
+		api.POST("/auth/users", admin, u.CreateUserHandler)
+		api.GET("/auth/api_keys", admin, u.ListAPIKeysHandler)
+		api.POST("/auth/api_keys", admin, u.CreateAPIKeyHandler)
+		api.DELETE("/auth/api_keys/:id", admin, u.RevokeAPIKeyHandler)
+
+		webhooks := api.Group("/webhooks", admin)
+		{
+			webhooks.GET("", u.ListWebhooksHandler)
+			webhooks.POST("", u.CreateWebhookHandler)
+			webhooks.GET("/:id", u.GetWebhookHandler)
+			webhooks.PATCH("/:id", u.UpdateWebhookHandler)
+			webhooks.DELETE("/:id", u.DeleteWebhookHandler)
+			webhooks.GET("/:id/deliveries", u.ListWebhookDeliveriesHandler)
+			webhooks.POST("/:id/test", u.TestWebhookHandler)
+		}
 	}
 }
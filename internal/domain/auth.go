@@ -0,0 +1,82 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	// ErrUserNotFound is returned when no user matches the lookup
+	ErrUserNotFound = errors.New("user not found")
+	// ErrUserExists is returned when creating a user whose email is taken
+	ErrUserExists = errors.New("user already exists")
+	// ErrAPIKeyNotFound is returned when no active API key matches the lookup
+	ErrAPIKeyNotFound = errors.New("api key not found")
+)
+
+// Role decides what a user or API key may do. Each role includes the ones below it.
+type Role string
+
+const (
+	// RoleViewer reads concalls, analytics and real-time updates
+	RoleViewer Role = "viewer"
+	// RoleAnalyst also follows jobs and ingestion failures
+	RoleAnalyst Role = "analyst"
+	// RoleAdmin also runs ingestion, cleanup and reprocessing and manages users and API keys
+	RoleAdmin Role = "admin"
+)
+
+var roleRanks = map[Role]int{
+	RoleViewer:  1,
+	RoleAnalyst: 2,
+	RoleAdmin:   3,
+}
+
+// ParseRole validates a role name
+func ParseRole(s string) (Role, error) {
+	role := Role(s)
+	if _, ok := roleRanks[role]; !ok {
+		return "", fmt.Errorf("invalid role %q (expected viewer, analyst or admin)", s)
+	}
+	return role, nil
+}
+
+// Allows reports whether r grants everything required grants
+func (r Role) Allows(required Role) bool {
+	rank, ok := roleRanks[r]
+	return ok && rank >= roleRanks[required]
+}
+
+// User is someone who logs in with an email and password
+type User struct {
+	ID           primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Email        string             `bson:"email" json:"email"`
+	PasswordHash string             `bson:"password_hash" json:"-"`
+	Role         Role               `bson:"role" json:"role"`
+	CreatedAt    time.Time          `bson:"created_at" json:"created_at"`
+	LastLoginAt  *time.Time         `bson:"last_login_at,omitempty" json:"last_login_at,omitempty"`
+}
+
+// APIKey authenticates automation. Only the SHA-256 of the key is stored; the
+// key itself is shown once, when it is created.
+type APIKey struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Name       string             `bson:"name" json:"name"`
+	Prefix     string             `bson:"prefix" json:"prefix"` // first characters of the key, to tell keys apart
+	Hash       string             `bson:"hash" json:"-"`
+	Role       Role               `bson:"role" json:"role"`
+	CreatedBy  string             `bson:"created_by" json:"created_by"`
+	CreatedAt  time.Time          `bson:"created_at" json:"created_at"`
+	LastUsedAt *time.Time         `bson:"last_used_at,omitempty" json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time         `bson:"revoked_at,omitempty" json:"revoked_at,omitempty"`
+}
+
+// Principal is who a request was authenticated as
+type Principal struct {
+	Subject string `json:"subject"` // user email or API key name
+	Role    Role   `json:"role"`
+	Method  string `json:"method"` // "session", "api_key" or "anonymous"
+}
@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ErrCompanyNotFound is returned when no company matches the lookup
+var ErrCompanyNotFound = errors.New("company not found")
+
+// Company is a listed company whose concalls are stored, with the
+// classification BSE gives it
+type Company struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"-"`
+	ScripCode int                `bson:"scrip_code" json:"scrip_code"`
+	Name      string             `bson:"name" json:"name"`
+	Symbol    string             `bson:"symbol,omitempty" json:"symbol,omitempty"`
+	Sector    string             `bson:"sector,omitempty" json:"sector,omitempty"`
+	Industry  string             `bson:"industry,omitempty" json:"industry,omitempty"`
+	// ProfileFetchedAt is when the classification was last read from BSE
+	ProfileFetchedAt *time.Time `bson:"profile_fetched_at,omitempty" json:"profile_fetched_at,omitempty"`
+	// SearchName and Trigrams are derived from Name for search, see NormalizeCompanyName
+	SearchName string    `bson:"search_name,omitempty" json:"-"`
+	Trigrams   []string  `bson:"trigrams,omitempty" json:"-"`
+	UpdatedAt  time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// ScoredCompany is a company found by a search with the score the store gave it:
+// the text score for text searches and the number of shared trigrams otherwise
+type ScoredCompany struct {
+	Company `bson:",inline"`
+	Score   float64 `bson:"score"`
+}
+
+// companyNameNoise are words too common in listed company names to tell them apart
+var companyNameNoise = map[string]bool{
+	"ltd": true, "limited": true, "the": true, "and": true, "co": true, "pvt": true, "private": true,
+}
+
+// NormalizeCompanyName lowercases a company name, drops punctuation, the "-$"
+// suffix BSE adds and words like "Ltd", and collapses spaces, so "The Tata
+// Power Co. Ltd.-$" becomes "tata power"
+func NormalizeCompanyName(name string) string {
+	name = strings.TrimSuffix(strings.TrimSpace(name), "-$")
+	fields := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	words := fields[:0]
+	for _, word := range fields {
+		if !companyNameNoise[word] {
+			words = append(words, word)
+		}
+	}
+	if len(words) == 0 {
+		// A name made only of noise words is still a name
+		return strings.Join(fields, " ")
+	}
+	return strings.Join(words, " ")
+}
+
+// Trigrams splits a normalized name into the three-letter sequences of its
+// words, each padded with two spaces in front and one behind, so short words
+// and word starts count too: "tata" gives "  t", " ta", "tat", "ata" and "ta ".
+// Each trigram appears once.
+func Trigrams(normalized string) []string {
+	seen := make(map[string]bool)
+	var trigrams []string
+	for _, word := range strings.Fields(normalized) {
+		padded := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(padded); i++ {
+			trigram := string(padded[i : i+3])
+			if !seen[trigram] {
+				seen[trigram] = true
+				trigrams = append(trigrams, trigram)
+			}
+		}
+	}
+	return trigrams
+}
+
+// SectorCount is a sector with the number of companies in it
+type SectorCount struct {
+	Sector    string `bson:"_id" json:"sector"`
+	Companies int    `bson:"companies" json:"companies"`
+}
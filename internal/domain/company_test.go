@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeCompanyName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "The Tata Power Co. Ltd.-$", want: "tata power"},
+		{name: "RELIANCE INDUSTRIES LTD.", want: "reliance industries"},
+		{name: "HDFC Bank Limited", want: "hdfc bank"},
+		{name: "Larsen & Toubro Ltd", want: "larsen toubro"},
+		{name: "  Bajaj-Auto   Private Ltd  ", want: "bajaj auto"},
+		{name: "3M India Ltd", want: "3m india"},
+		{name: "Nestlé India Ltd", want: "nestlé india"},
+		// Only noise words, kept rather than emptied
+		{name: "The Co Ltd.", want: "the co ltd"},
+		{name: "-$", want: ""},
+		{name: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeCompanyName(tt.name); got != tt.want {
+			t.Errorf("NormalizeCompanyName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestTrigrams(t *testing.T) {
+	tests := []struct {
+		normalized string
+		want       []string
+	}{
+		{normalized: "tata", want: []string{"  t", " ta", "tat", "ata", "ta "}},
+		{normalized: "tata power", want: []string{"  t", " ta", "tat", "ata", "ta ", "  p", " po", "pow", "owe", "wer", "er "}},
+		{normalized: "ab", want: []string{"  a", " ab", "ab "}},
+		{normalized: "a", want: []string{"  a", " a "}},
+		// Repeated words and trigrams appear once
+		{normalized: "aa aa", want: []string{"  a", " aa", "aa "}},
+		// Trigrams are made of runes, not bytes
+		{normalized: "né", want: []string{"  n", " né", "né "}},
+		{normalized: "", want: nil},
+	}
+
+	for _, tt := range tests {
+		if got := Trigrams(tt.normalized); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Trigrams(%q) = %q, want %q", tt.normalized, got, tt.want)
+		}
+	}
+}
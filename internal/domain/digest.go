@@ -0,0 +1,22 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ErrDigestSubscriptionNotFound is returned when an email has no digest subscription
+var ErrDigestSubscriptionNotFound = errors.New("digest subscription not found")
+
+// DigestSubscription asks for an email digest of the new guidance after each
+// ingestion run, limited to the companies on a watchlist when WatchlistID is set
+type DigestSubscription struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Email       string             `bson:"email" json:"email"`
+	WatchlistID string             `bson:"watchlist_id,omitempty" json:"watchlist_id,omitempty"`
+	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time          `bson:"updated_at" json:"updated_at"`
+	LastSentAt  *time.Time         `bson:"last_sent_at,omitempty" json:"last_sent_at,omitempty"`
+}
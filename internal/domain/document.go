@@ -0,0 +1,22 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrDocumentNotFound is returned when no archived document matches the lookup
+var ErrDocumentNotFound = errors.New("document not found")
+
+// Document describes a raw transcript PDF kept in the archive. It is keyed by the
+// SHA-256 of the file contents, so a PDF that BSE serves twice is stored once.
+type Document struct {
+	ID             string    `bson:"_id" json:"id"` // hex encoded SHA-256
+	Backend        string    `bson:"backend" json:"backend"`
+	Key            string    `bson:"key" json:"key"`
+	Size           int64     `bson:"size" json:"size"`
+	ContentType    string    `bson:"content_type" json:"content_type"`
+	NewsIDs        []string  `bson:"news_ids" json:"news_ids"`
+	AttachmentName string    `bson:"attachment_name,omitempty" json:"attachment_name,omitempty"`
+	CreatedAt      time.Time `bson:"created_at" json:"created_at"`
+}
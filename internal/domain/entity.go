@@ -42,17 +42,51 @@ type Announcement struct {
 	AudioVideoFile   *string `json:"AUDIO_VIDEO_FILE"`
 }
 
-// ConcallSummary represents the processed concall data to be stored in MongoDB
+// ConcallSummary represents the processed concall data to be stored in MongoDB.
+// Each summary belongs to exactly one BSE transcript, identified by NewsID.
 type ConcallSummary struct {
-	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
-	Name      string             `bson:"name" json:"name"`
-	Date      string             `bson:"date" json:"date"`
-	Guidance  string             `bson:"guidance" json:"guidance"`
-	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+	ID             primitive.ObjectID   `bson:"_id,omitempty" json:"id,omitempty"`
+	NewsID         string               `bson:"news_id,omitempty" json:"news_id,omitempty"`
+	ScripCode      int                  `bson:"scrip_code,omitempty" json:"scrip_code,omitempty"`
+	QuarterID      string               `bson:"quarter_id,omitempty" json:"quarter_id,omitempty"`
+	AttachmentName string               `bson:"attachment_name,omitempty" json:"attachment_name,omitempty"`
+	Name           string               `bson:"name" json:"name"`
+	Date           string               `bson:"date" json:"date"`
+	Guidance       string               `bson:"guidance" json:"guidance"`
+	GuidanceItems  []GuidanceItem       `bson:"guidance_items,omitempty" json:"guidance_items,omitempty"`
+	FiscalYears    []string             `bson:"fiscal_years,omitempty" json:"fiscal_years,omitempty"`
+	GuidanceByFY   []FiscalYearGuidance `bson:"guidance_by_fy,omitempty" json:"guidance_by_fy,omitempty"`
+	DocumentID     string               `bson:"document_id,omitempty" json:"document_id,omitempty"` // SHA-256 of the archived PDF
+	PromptVersion  string               `bson:"prompt_version,omitempty" json:"prompt_version,omitempty"`
+	LLMProvider    string               `bson:"llm_provider,omitempty" json:"llm_provider,omitempty"`
+	LLMModel       string               `bson:"llm_model,omitempty" json:"llm_model,omitempty"`
+	CreatedAt      time.Time            `bson:"created_at" json:"created_at"`
+	ReprocessedAt  *time.Time           `bson:"reprocessed_at,omitempty" json:"reprocessed_at,omitempty"`
 }
 
 type ConcallLite struct {
-	Name     string `bson:"name" json:"name"`
-	Date     string `bson:"date" json:"date"`
-	Guidance string `bson:"guidance" json:"guidance"`
+	NewsID        string               `bson:"news_id,omitempty" json:"news_id,omitempty"`
+	ScripCode     int                  `bson:"scrip_code,omitempty" json:"scrip_code,omitempty"`
+	QuarterID     string               `bson:"quarter_id,omitempty" json:"quarter_id,omitempty"`
+	Name          string               `bson:"name" json:"name"`
+	Date          string               `bson:"date" json:"date"`
+	Guidance      string               `bson:"guidance" json:"guidance"`
+	GuidanceItems []GuidanceItem       `bson:"guidance_items,omitempty" json:"guidance_items,omitempty"`
+	GuidanceByFY  []FiscalYearGuidance `bson:"guidance_by_fy,omitempty" json:"guidance_by_fy,omitempty"`
+	DocumentID    string               `bson:"document_id,omitempty" json:"document_id,omitempty"`
+}
+
+// Lite trims a summary down to what list_concalls returns
+func (s ConcallSummary) Lite() ConcallLite {
+	return ConcallLite{
+		NewsID:        s.NewsID,
+		ScripCode:     s.ScripCode,
+		QuarterID:     s.QuarterID,
+		Name:          s.Name,
+		Date:          s.Date,
+		Guidance:      s.Guidance,
+		GuidanceItems: s.GuidanceItems,
+		GuidanceByFY:  s.GuidanceByFY,
+		DocumentID:    s.DocumentID,
+	}
 }
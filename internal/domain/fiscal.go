@@ -0,0 +1,111 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// fiscalYearReplacer drops the apostrophes and spaces of "FY '26" and turns
+// the dashes of "2025–26" into hyphens
+var fiscalYearReplacer = strings.NewReplacer("'", "", "’", "", " ", "", "\t", "", "–", "-", "—", "-")
+
+// FiscalYearOf returns the Indian fiscal year (April–March) that t falls in,
+// named after the calendar year it ends in: 10 May 2025 is in FY26 (2026)
+func FiscalYearOf(t time.Time) int {
+	if t.Month() >= time.April {
+		return t.Year() + 1
+	}
+	return t.Year()
+}
+
+// FormatFiscalYear renders a fiscal year as "FY26"
+func FormatFiscalYear(fy int) string {
+	return fmt.Sprintf("FY%02d", fy%100)
+}
+
+// ParseFiscalYear accepts "FY26", "fy26", "FY 26", "FY2026", "2026" or the
+// span "2025-26" (also "2025-2026" or "2025–26") and returns the canonical "FY26"
+func ParseFiscalYear(s string) (string, error) {
+	v := fiscalYearReplacer.Replace(strings.ToUpper(s))
+	v = strings.TrimPrefix(v, "FY")
+
+	if start, end, ok := strings.Cut(v, "-"); ok {
+		return parseFiscalYearSpan(s, start, end)
+	}
+
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 || (n >= 100 && (n < 2000 || n > 2099)) {
+		return "", fmt.Errorf("invalid fiscal year %q, expected e.g. FY26", s)
+	}
+	return FormatFiscalYear(n), nil
+}
+
+// parseFiscalYearSpan reads "2025-26" or "2025-2026", where the year ending
+// the span must follow the one starting it
+func parseFiscalYearSpan(s, start, end string) (string, error) {
+	from, err := strconv.Atoi(start)
+	if err != nil || len(start) != 4 || from < 1999 || from > 2098 {
+		return "", fmt.Errorf("invalid fiscal year %q, expected e.g. 2025-26", s)
+	}
+	to, err := strconv.Atoi(end)
+	if err != nil || (len(end) != 2 && len(end) != 4) {
+		return "", fmt.Errorf("invalid fiscal year %q, expected e.g. 2025-26", s)
+	}
+	if len(end) == 2 {
+		to += from / 100 * 100
+		if to <= from {
+			to += 100
+		}
+	}
+	if to != from+1 {
+		return "", fmt.Errorf("invalid fiscal year %q, a fiscal year spans two consecutive years", s)
+	}
+	return FormatFiscalYear(to), nil
+}
+
+// ParseFiscalYears parses a comma separated list of fiscal years, dropping duplicates
+func ParseFiscalYears(s string) ([]string, error) {
+	var fys []string
+	for _, part := range strings.Split(s, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		fy, err := ParseFiscalYear(part)
+		if err != nil {
+			return nil, err
+		}
+		fys = append(fys, fy)
+	}
+	return SortFiscalYears(fys), nil
+}
+
+// TargetFiscalYears returns the fiscal years a transcript announced at t is
+// expected to guide on: the current fiscal year followed by horizons-1 more
+func TargetFiscalYears(t time.Time, horizons int) []string {
+	if horizons < 1 {
+		horizons = 1
+	}
+	current := FiscalYearOf(t)
+	fys := make([]string, 0, horizons)
+	for i := 0; i < horizons; i++ {
+		fys = append(fys, FormatFiscalYear(current+i))
+	}
+	return fys
+}
+
+// SortFiscalYears sorts and de-duplicates canonical fiscal year labels
+func SortFiscalYears(fys []string) []string {
+	seen := make(map[string]bool, len(fys))
+	out := make([]string, 0, len(fys))
+	for _, fy := range fys {
+		if !seen[fy] {
+			seen[fy] = true
+			out = append(out, fy)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
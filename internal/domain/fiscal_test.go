@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFiscalYearOf(t *testing.T) {
+	ist := time.FixedZone("IST", 5*60*60+30*60)
+
+	tests := []struct {
+		name string
+		t    time.Time
+		want string
+	}{
+		{name: "March 31 ends the fiscal year", t: time.Date(2025, time.March, 31, 23, 59, 59, 0, ist), want: "FY25"},
+		{name: "April 1 starts the next", t: time.Date(2025, time.April, 1, 0, 0, 0, 0, ist), want: "FY26"},
+		{name: "January belongs to the year ending in March", t: time.Date(2026, time.January, 15, 12, 0, 0, 0, ist), want: "FY26"},
+		{name: "December", t: time.Date(2025, time.December, 31, 12, 0, 0, 0, ist), want: "FY26"},
+		{name: "century boundary", t: time.Date(2099, time.May, 1, 0, 0, 0, 0, ist), want: "FY00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatFiscalYear(FiscalYearOf(tt.t)); got != tt.want {
+				t.Errorf("FiscalYearOf(%v) = %s, want %s", tt.t, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFiscalYear(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{input: "FY25", want: "FY25"},
+		{input: " fy25 ", want: "FY25"},
+		{input: "FY'25", want: "FY25"},
+		{input: "FY2025", want: "FY25"},
+		{input: "2025", want: "FY25"},
+		{input: "25", want: "FY25"},
+		{input: "2024-25", want: "FY25"},
+		{input: "2024-2025", want: "FY25"},
+		{input: "FY2024-25", want: "FY25"},
+		{input: "1999-00", want: "FY00"},
+		{input: "FY 25", want: "FY25"},
+		{input: "FY 2024 - 25", want: "FY25"},
+		{input: "2024–25", want: "FY25"},
+		{input: "2024-26", wantErr: true},
+		{input: "2025-24", wantErr: true},
+		{input: "24-25", wantErr: true},
+		{input: "2024-", wantErr: true},
+		{input: "FY1995", wantErr: true},
+		{input: "FY-5", wantErr: true},
+		{input: "next year", wantErr: true},
+		{input: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseFiscalYear(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseFiscalYear(%q) = %s, want an error", tt.input, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseFiscalYear(%q) error = %v", tt.input, err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseFiscalYear(%q) = %s, want %s", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTargetFiscalYears(t *testing.T) {
+	march := time.Date(2025, time.March, 31, 0, 0, 0, 0, time.UTC)
+	april := time.Date(2025, time.April, 1, 0, 0, 0, 0, time.UTC)
+
+	if got := TargetFiscalYears(march, 2); len(got) != 2 || got[0] != "FY25" || got[1] != "FY26" {
+		t.Errorf("TargetFiscalYears(31 March, 2) = %v, want [FY25 FY26]", got)
+	}
+	if got := TargetFiscalYears(april, 0); len(got) != 1 || got[0] != "FY26" {
+		t.Errorf("TargetFiscalYears(1 April, 0) = %v, want [FY26]", got)
+	}
+}
@@ -0,0 +1,169 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ErrMalformedGuidance is returned when the model's guidance response cannot be
+// parsed or fails validation, even after retries
+var ErrMalformedGuidance = errors.New("malformed guidance response")
+
+// NoGuidance is the human-readable guidance stored when management gave no numbers
+const NoGuidance = "NA"
+
+type GuidanceMetric string
+
+const (
+	MetricRevenue GuidanceMetric = "revenue"
+	MetricProfit  GuidanceMetric = "profit"
+	MetricEPS     GuidanceMetric = "eps"
+)
+
+// GuidanceMetrics lists every metric the model may report
+var GuidanceMetrics = []GuidanceMetric{MetricRevenue, MetricProfit, MetricEPS}
+
+var fiscalYearPattern = regexp.MustCompile(`^FY\d{2}$`)
+
+// GuidanceItem is one quantified guidance statement, e.g. "FY26 revenue of 1,200-1,300 crore"
+type GuidanceItem struct {
+	Metric     GuidanceMetric `bson:"metric" json:"metric"`
+	FiscalYear string         `bson:"fiscal_year" json:"fiscal_year"`
+	Low        *float64       `bson:"low,omitempty" json:"low,omitempty"`
+	High       *float64       `bson:"high,omitempty" json:"high,omitempty"`
+	Unit       string         `bson:"unit,omitempty" json:"unit,omitempty"`
+	GrowthPct  *float64       `bson:"growth_pct,omitempty" json:"growth_pct,omitempty"`
+	Quote      string         `bson:"quote" json:"quote"`
+}
+
+// FiscalYearGuidance is the human-readable guidance for a single fiscal year
+type FiscalYearGuidance struct {
+	FiscalYear string `bson:"fiscal_year" json:"fiscal_year"`
+	Summary    string `bson:"summary" json:"summary"`
+}
+
+// GuidanceResult is the validated outcome of summarizing one transcript
+type GuidanceResult struct {
+	Summary      string               `json:"summary"`
+	ByFiscalYear []FiscalYearGuidance `json:"by_fiscal_year"`
+	Items        []GuidanceItem       `json:"guidance"`
+}
+
+// Normalize trims fields and canonicalises metric and fiscal year spelling
+func (r *GuidanceResult) Normalize() {
+	r.Summary = strings.TrimSpace(r.Summary)
+	for i := range r.Items {
+		item := &r.Items[i]
+		item.Metric = GuidanceMetric(strings.ToLower(strings.TrimSpace(string(item.Metric))))
+		item.FiscalYear = normalizeFiscalYear(item.FiscalYear)
+		item.Unit = strings.TrimSpace(item.Unit)
+		item.Quote = strings.TrimSpace(item.Quote)
+	}
+	for i := range r.ByFiscalYear {
+		fy := &r.ByFiscalYear[i]
+		fy.FiscalYear = normalizeFiscalYear(fy.FiscalYear)
+		fy.Summary = strings.TrimSpace(fy.Summary)
+		if fy.Summary == "" || strings.EqualFold(fy.Summary, NoGuidance) {
+			fy.Summary = NoGuidance
+		}
+	}
+	if len(r.Items) == 0 && (r.Summary == "" || strings.EqualFold(r.Summary, NoGuidance)) {
+		r.Summary = NoGuidance
+	}
+}
+
+// normalizeFiscalYear rewrites the spellings ParseFiscalYear accepts, such as
+// "FY2026", "FY 26" or "2025-26", as "FY26". Anything else is only uppercased,
+// for Validate to reject.
+func normalizeFiscalYear(s string) string {
+	if fy, err := ParseFiscalYear(s); err == nil {
+		return fy
+	}
+	return strings.ToUpper(strings.TrimSpace(s))
+}
+
+// Validate checks that the result is internally consistent and safe to store
+func (r *GuidanceResult) Validate() error {
+	if r.Summary == "" {
+		return fmt.Errorf("%w: empty summary", ErrMalformedGuidance)
+	}
+	if len(r.Items) > 0 && r.Summary == NoGuidance {
+		return fmt.Errorf("%w: summary is %q but %d guidance items were returned", ErrMalformedGuidance, NoGuidance, len(r.Items))
+	}
+
+	for i, fy := range r.ByFiscalYear {
+		if !fiscalYearPattern.MatchString(fy.FiscalYear) {
+			return fmt.Errorf("%w: per-year summary %d has invalid fiscal year %q", ErrMalformedGuidance, i, fy.FiscalYear)
+		}
+	}
+
+	for i, item := range r.Items {
+		if !isKnownMetric(item.Metric) {
+			return fmt.Errorf("%w: item %d has unknown metric %q", ErrMalformedGuidance, i, item.Metric)
+		}
+		if !fiscalYearPattern.MatchString(item.FiscalYear) {
+			return fmt.Errorf("%w: item %d has invalid fiscal year %q", ErrMalformedGuidance, i, item.FiscalYear)
+		}
+		if item.Low == nil && item.High == nil && item.GrowthPct == nil {
+			return fmt.Errorf("%w: item %d carries no value or growth", ErrMalformedGuidance, i)
+		}
+		if item.Low != nil && item.High != nil && *item.Low > *item.High {
+			return fmt.Errorf("%w: item %d has low %v above high %v", ErrMalformedGuidance, i, *item.Low, *item.High)
+		}
+		if (item.Low != nil || item.High != nil) && item.Unit == "" {
+			return fmt.Errorf("%w: item %d has a value but no unit", ErrMalformedGuidance, i)
+		}
+		if item.Quote == "" {
+			return fmt.Errorf("%w: item %d has no supporting quote", ErrMalformedGuidance, i)
+		}
+	}
+
+	return nil
+}
+
+// RestrictTo keeps only guidance for the requested fiscal years and makes sure
+// every requested year has a per-year summary, defaulting to NA
+func (r *GuidanceResult) RestrictTo(fiscalYears []string) {
+	wanted := make(map[string]bool, len(fiscalYears))
+	for _, fy := range fiscalYears {
+		wanted[fy] = true
+	}
+
+	items := r.Items[:0]
+	for _, item := range r.Items {
+		if wanted[item.FiscalYear] {
+			items = append(items, item)
+		}
+	}
+	r.Items = items
+
+	byFY := make(map[string]string, len(r.ByFiscalYear))
+	for _, fy := range r.ByFiscalYear {
+		if wanted[fy.FiscalYear] {
+			byFY[fy.FiscalYear] = fy.Summary
+		}
+	}
+	r.ByFiscalYear = make([]FiscalYearGuidance, 0, len(fiscalYears))
+	for _, fy := range SortFiscalYears(fiscalYears) {
+		summary, ok := byFY[fy]
+		if !ok {
+			summary = NoGuidance
+		}
+		r.ByFiscalYear = append(r.ByFiscalYear, FiscalYearGuidance{FiscalYear: fy, Summary: summary})
+	}
+
+	if len(r.Items) == 0 {
+		r.Summary = NoGuidance
+	}
+}
+
+func isKnownMetric(m GuidanceMetric) bool {
+	for _, known := range GuidanceMetrics {
+		if m == known {
+			return true
+		}
+	}
+	return false
+}
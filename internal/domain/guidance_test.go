@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func float(v float64) *float64 {
+	return &v
+}
+
+func TestGuidanceResultValidate(t *testing.T) {
+	valid := GuidanceItem{Metric: MetricRevenue, FiscalYear: "FY26", Low: float(100), High: float(120), Unit: "crore", Quote: "100 to 120 crore"}
+
+	tests := []struct {
+		name    string
+		result  GuidanceResult
+		wantErr bool
+	}{
+		{name: "no guidance", result: GuidanceResult{Summary: NoGuidance}},
+		{name: "valid item", result: GuidanceResult{Summary: "Revenue of 100-120 crore", Items: []GuidanceItem{valid}}},
+		{name: "growth only", result: GuidanceResult{Summary: "EPS up 10%", Items: []GuidanceItem{{Metric: MetricEPS, FiscalYear: "FY27", GrowthPct: float(10), Quote: "10%"}}}},
+		{name: "empty summary", result: GuidanceResult{}, wantErr: true},
+		{name: "NA summary with items", result: GuidanceResult{Summary: NoGuidance, Items: []GuidanceItem{valid}}, wantErr: true},
+		{name: "invalid per-year fiscal year", result: GuidanceResult{Summary: NoGuidance, ByFiscalYear: []FiscalYearGuidance{{FiscalYear: "2026", Summary: NoGuidance}}}, wantErr: true},
+		{name: "unknown metric", result: GuidanceResult{Summary: "x", Items: []GuidanceItem{{Metric: "margin", FiscalYear: "FY26", GrowthPct: float(1), Quote: "q"}}}, wantErr: true},
+		{name: "invalid fiscal year", result: GuidanceResult{Summary: "x", Items: []GuidanceItem{{Metric: MetricRevenue, FiscalYear: "FY2026", GrowthPct: float(1), Quote: "q"}}}, wantErr: true},
+		{name: "no value", result: GuidanceResult{Summary: "x", Items: []GuidanceItem{{Metric: MetricRevenue, FiscalYear: "FY26", Quote: "q"}}}, wantErr: true},
+		{name: "low above high", result: GuidanceResult{Summary: "x", Items: []GuidanceItem{{Metric: MetricRevenue, FiscalYear: "FY26", Low: float(2), High: float(1), Unit: "crore", Quote: "q"}}}, wantErr: true},
+		{name: "value without unit", result: GuidanceResult{Summary: "x", Items: []GuidanceItem{{Metric: MetricRevenue, FiscalYear: "FY26", Low: float(2), Quote: "q"}}}, wantErr: true},
+		{name: "no quote", result: GuidanceResult{Summary: "x", Items: []GuidanceItem{{Metric: MetricRevenue, FiscalYear: "FY26", GrowthPct: float(1)}}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.result.Validate()
+			if tt.wantErr && !errors.Is(err, ErrMalformedGuidance) {
+				t.Errorf("Validate() error = %v, want ErrMalformedGuidance", err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("Validate() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestGuidanceResultNormalizeFiscalYears(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+		valid bool
+	}{
+		{input: "FY26", want: "FY26", valid: true},
+		{input: " fy'26 ", want: "FY26", valid: true},
+		{input: "FY2026", want: "FY26", valid: true},
+		{input: "FY 26", want: "FY26", valid: true},
+		{input: "2025-26", want: "FY26", valid: true},
+		{input: "FY2025-26", want: "FY26", valid: true},
+		{input: "2025–2026", want: "FY26", valid: true},
+		{input: "2026", want: "FY26", valid: true},
+		{input: "2025-27", want: "2025-27"},
+		{input: "next year", want: "NEXT YEAR"},
+		{input: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result := GuidanceResult{
+				Summary:      "Revenue growth of 15%",
+				ByFiscalYear: []FiscalYearGuidance{{FiscalYear: tt.input, Summary: "Revenue growth of 15%"}},
+				Items:        []GuidanceItem{{Metric: " Revenue ", FiscalYear: tt.input, GrowthPct: float(15), Quote: "15% growth"}},
+			}
+			result.Normalize()
+
+			if got := result.Items[0].FiscalYear; got != tt.want {
+				t.Errorf("item fiscal year = %q, want %q", got, tt.want)
+			}
+			if got := result.ByFiscalYear[0].FiscalYear; got != tt.want {
+				t.Errorf("per-year fiscal year = %q, want %q", got, tt.want)
+			}
+			err := result.Validate()
+			if tt.valid && err != nil {
+				t.Errorf("Validate() after Normalize() error = %v", err)
+			}
+			if !tt.valid && !errors.Is(err, ErrMalformedGuidance) {
+				t.Errorf("Validate() after Normalize() error = %v, want ErrMalformedGuidance", err)
+			}
+		})
+	}
+}
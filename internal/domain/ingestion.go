@@ -0,0 +1,24 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SourceBSE names the BSE announcements feed in ingestion bookkeeping
+const SourceBSE = "bse"
+
+// ErrCursorNotFound is returned when a source has not been ingested on a schedule yet
+var ErrCursorNotFound = errors.New("ingestion cursor not found")
+
+// SourceCursor records how far scheduled ingestion of a source has got
+type SourceCursor struct {
+	Source string `bson:"_id" json:"source"`
+	// HighWaterMark is the last announcement date (YYYY-MM-DD) a run fully covered
+	HighWaterMark string             `bson:"high_water_mark,omitempty" json:"high_water_mark,omitempty"`
+	LastJobID     primitive.ObjectID `bson:"last_job_id,omitempty" json:"last_job_id,omitempty"`
+	LastState     JobState           `bson:"last_state,omitempty" json:"last_state,omitempty"`
+	LastRunAt     time.Time          `bson:"last_run_at" json:"last_run_at"`
+}
@@ -0,0 +1,104 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ErrJobNotFound is returned when a job ID does not match any stored job
+var ErrJobNotFound = errors.New("job not found")
+
+// JobKind tells what a job runs. Jobs stored before kinds existed are fetch jobs.
+type JobKind string
+
+const (
+	JobKindFetch     JobKind = "fetch"
+	JobKindReprocess JobKind = "reprocess"
+	// JobKindRetry jobs run failed announcements from the ingestion ledger again
+	JobKindRetry JobKind = "retry"
+)
+
+type JobState string
+
+const (
+	JobQueued    JobState = "queued"
+	JobRunning   JobState = "running"
+	JobCompleted JobState = "completed"
+	JobFailed    JobState = "failed"
+	JobCancelled JobState = "cancelled"
+)
+
+// Terminal reports whether a job in this state will not change any more
+func (s JobState) Terminal() bool {
+	return s == JobCompleted || s == JobFailed || s == JobCancelled
+}
+
+type JobItemStatus string
+
+const (
+	ItemPending    JobItemStatus = "pending"
+	ItemProcessing JobItemStatus = "processing"
+	ItemSucceeded  JobItemStatus = "succeeded"
+	ItemSkipped    JobItemStatus = "skipped"
+	ItemFailed     JobItemStatus = "failed"
+)
+
+// FetchStats describes how much of the BSE result set was retrieved for a date range
+type FetchStats struct {
+	PagesFetched  int   `bson:"pages_fetched" json:"pages_fetched"`
+	PagesExpected int   `bson:"pages_expected" json:"pages_expected"`
+	RowsSeen      int   `bson:"rows_seen" json:"rows_seen"`
+	RowsExpected  int   `bson:"rows_expected" json:"rows_expected"`
+	FailedPages   []int `bson:"failed_pages,omitempty" json:"failed_pages,omitempty"`
+}
+
+// Complete reports whether every expected page and row was retrieved
+func (s FetchStats) Complete() bool {
+	return len(s.FailedPages) == 0 && s.PagesFetched >= s.PagesExpected && s.RowsSeen >= s.RowsExpected
+}
+
+// JobProgress holds the per-announcement counters of a job
+type JobProgress struct {
+	Total     int `bson:"total" json:"total"`
+	Processed int `bson:"processed" json:"processed"`
+	Succeeded int `bson:"succeeded" json:"succeeded"`
+	Skipped   int `bson:"skipped" json:"skipped"`
+	Failed    int `bson:"failed" json:"failed"`
+}
+
+// JobItem tracks a single announcement inside a fetch job
+type JobItem struct {
+	NewsID         string        `bson:"news_id" json:"news_id"`
+	Name           string        `bson:"name" json:"name"`
+	AttachmentName string        `bson:"attachment_name,omitempty" json:"attachment_name,omitempty"`
+	Status         JobItemStatus `bson:"status" json:"status"`
+	Error          string        `bson:"error,omitempty" json:"error,omitempty"`
+	UpdatedAt      time.Time     `bson:"updated_at" json:"updated_at"`
+}
+
+// FetchJob is a persisted, asynchronous run of the fetch + summarize pipeline,
+// or of a reprocess over stored concalls
+type FetchJob struct {
+	ID              primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Kind            JobKind            `bson:"kind" json:"kind"`
+	State           JobState           `bson:"state" json:"state"`
+	From            string             `bson:"from" json:"from"`
+	To              string             `bson:"to" json:"to"`
+	FiscalYears     []string           `bson:"fiscal_years,omitempty" json:"fiscal_years,omitempty"`
+	Fetch           *FetchStats        `bson:"fetch,omitempty" json:"fetch,omitempty"`
+	Reprocess       *ReprocessFilter   `bson:"reprocess,omitempty" json:"reprocess,omitempty"`
+	Progress        JobProgress        `bson:"progress" json:"progress"`
+	Items           []JobItem          `bson:"items" json:"items"`
+	Errors          []string           `bson:"errors" json:"errors"`
+	Summaries       []ConcallSummary   `bson:"summaries" json:"summaries"`
+	CancelRequested bool               `bson:"cancel_requested" json:"cancel_requested"`
+	// Resumes counts how often the job was picked up again after its process went away
+	Resumes    int        `bson:"resumes,omitempty" json:"resumes,omitempty"`
+	ResumedAt  *time.Time `bson:"resumed_at,omitempty" json:"resumed_at,omitempty"`
+	CreatedAt  time.Time  `bson:"created_at" json:"created_at"`
+	StartedAt  *time.Time `bson:"started_at,omitempty" json:"started_at,omitempty"`
+	FinishedAt *time.Time `bson:"finished_at,omitempty" json:"finished_at,omitempty"`
+	UpdatedAt  time.Time  `bson:"updated_at" json:"updated_at"`
+}
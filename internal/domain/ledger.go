@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// LedgerState is where an announcement stands in the ingestion pipeline
+type LedgerState string
+
+const (
+	LedgerQueued     LedgerState = "queued"
+	LedgerDownloaded LedgerState = "downloaded"
+	LedgerSummarized LedgerState = "summarized"
+	LedgerFailed     LedgerState = "failed"
+	LedgerSkipped    LedgerState = "skipped"
+	// LedgerDead marks a failure that used up its automatic retries; it is only retried on request
+	LedgerDead LedgerState = "dead"
+)
+
+// LedgerEntry records the processing history of one BSE announcement, keyed by NewsID.
+// The announcement itself is kept so that failures can be retried without asking BSE again.
+type LedgerEntry struct {
+	NewsID       string             `bson:"_id" json:"news_id"`
+	ScripCode    int                `bson:"scrip_code,omitempty" json:"scrip_code,omitempty"`
+	Name         string             `bson:"name" json:"name"`
+	Date         string             `bson:"date" json:"date"`
+	Announcement Announcement       `bson:"announcement" json:"-"`
+	State        LedgerState        `bson:"state" json:"state"`
+	Reason       string             `bson:"reason,omitempty" json:"reason,omitempty"`
+	Attempts     int                `bson:"attempts" json:"attempts"`
+	JobID        primitive.ObjectID `bson:"job_id,omitempty" json:"job_id,omitempty"`
+	NextRetryAt  *time.Time         `bson:"next_retry_at,omitempty" json:"next_retry_at,omitempty"`
+	CreatedAt    time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt    time.Time          `bson:"updated_at" json:"updated_at"`
+}
@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// LockIngestion is the lock shared by every operation that writes summaries in bulk
+// (fetch, cleanup and reprocess), so only one of them runs across all replicas
+const LockIngestion = "ingestion"
+
+// ErrLockLost is returned when a lease expired or was taken over before it was renewed
+var ErrLockLost = errors.New("lock lease lost")
+
+// Lease is a time-limited claim on a named lock, kept alive by heartbeats
+type Lease struct {
+	Name       string    `bson:"_id" json:"name"`
+	Token      string    `bson:"token" json:"-"`
+	Holder     string    `bson:"holder" json:"holder"`
+	Operation  string    `bson:"operation" json:"operation"`
+	JobID      string    `bson:"job_id,omitempty" json:"job_id,omitempty"`
+	AcquiredAt time.Time `bson:"acquired_at" json:"acquired_at"`
+	RenewedAt  time.Time `bson:"renewed_at" json:"renewed_at"`
+	ExpiresAt  time.Time `bson:"expires_at" json:"expires_at"`
+}
+
+// LockHeldError is returned when a lock is already held, carrying the current lease
+type LockHeldError struct {
+	Lease *Lease
+}
+
+func (e *LockHeldError) Error() string {
+	if e.Lease == nil {
+		return "lock is held by another run"
+	}
+	return fmt.Sprintf("lock %q is held by %s for %s until %s",
+		e.Lease.Name, e.Lease.Holder, e.Lease.Operation, e.Lease.ExpiresAt.Format(time.RFC3339))
+}
@@ -2,20 +2,36 @@ package domain
 
 import (
 	"context"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 // ConcallRepository defines the interface for concall data persistence
 type ConcallRepository interface {
-	// FindExistingNames finds all existing names from the given list
-	FindExistingNames(ctx context.Context, names []string) (map[string]bool, error)
+	// EnsureIndexes creates the indexes the collection relies on, including the unique NewsID index
+	EnsureIndexes(ctx context.Context) error
+
+	// FindExistingNewsIDs finds which of the given BSE NewsIDs already have a summary
+	FindExistingNewsIDs(ctx context.Context, newsIDs []string) (map[string]bool, error)
 	
-	// InsertMany inserts multiple concall summaries
+	// InsertMany inserts multiple concall summaries, skipping transcripts that are already stored
 	InsertMany(ctx context.Context, summaries []ConcallSummary) error
+
+	// Upsert stores one summary keyed on its NewsID, so writing the same transcript twice
+	// leaves a single document. It reports whether the summary was inserted rather than
+	// replacing a stored one.
+	Upsert(ctx context.Context, summary *ConcallSummary) (bool, error)
 	
+	// FindSummaries finds full summaries matching the filter with options
+	FindSummaries(ctx context.Context, filter bson.M, opts *options.FindOptions) ([]ConcallSummary, error)
+
+	// ReplaceSummary overwrites a stored summary, matched by its ID
+	ReplaceSummary(ctx context.Context, summary *ConcallSummary) error
+
 	// FindWithFilter finds documents matching the filter with options
 	FindWithFilter(ctx context.Context, filter bson.M, opts *options.FindOptions) ([]ConcallLite, error)
 	
@@ -29,3 +45,288 @@ type ConcallRepository interface {
 	Aggregate(ctx context.Context, pipeline []bson.M) (*mongo.Cursor, error)
 }
 
+// JobRepository defines the interface for fetch job persistence
+type JobRepository interface {
+	// Create stores a new job, assigning its ID
+	Create(ctx context.Context, job *FetchJob) error
+
+	// FindByID returns the job with the given ID or ErrJobNotFound
+	FindByID(ctx context.Context, id primitive.ObjectID) (*FetchJob, error)
+
+	// Update sets the given fields on a job
+	Update(ctx context.Context, id primitive.ObjectID, fields bson.M) error
+
+	// UpdateItem sets the status of one announcement in a job and bumps the progress counters
+	UpdateItem(ctx context.Context, id primitive.ObjectID, newsID string, status JobItemStatus, errMsg string) error
+
+	// RequestCancel flags a job for cancellation, returning false if it has already finished
+	RequestCancel(ctx context.Context, id primitive.ObjectID) (bool, error)
+
+	// AppendSummary adds a stored summary to the job's results
+	AppendSummary(ctx context.Context, id primitive.ObjectID, summary ConcallSummary) error
+
+	// FindUnfinished returns the queued and running jobs, oldest first
+	FindUnfinished(ctx context.Context) ([]FetchJob, error)
+
+	// MarkResumed records that a job was picked up again after it was interrupted
+	MarkResumed(ctx context.Context, id primitive.ObjectID) error
+}
+
+// TranscriptRepository defines the interface for extracted transcript text persistence
+type TranscriptRepository interface {
+	// Upsert stores the transcript, replacing any earlier extraction of the same NewsID
+	Upsert(ctx context.Context, transcript *Transcript) error
+
+	// FindByNewsID returns the stored transcript or ErrTranscriptNotFound
+	FindByNewsID(ctx context.Context, newsID string) (*Transcript, error)
+}
+
+// DocumentRepository defines the interface for archived document metadata persistence
+type DocumentRepository interface {
+	// EnsureIndexes creates the indexes the collection relies on
+	EnsureIndexes(ctx context.Context) error
+
+	// Upsert stores the document metadata, adding its NewsIDs to an already archived copy
+	Upsert(ctx context.Context, doc *Document) error
+
+	// FindByID returns the document with the given SHA-256 or ErrDocumentNotFound
+	FindByID(ctx context.Context, id string) (*Document, error)
+
+	// FindByNewsID returns the document archived for a BSE NewsID or ErrDocumentNotFound
+	FindByNewsID(ctx context.Context, newsID string) (*Document, error)
+}
+
+// SummaryVersionRepository defines the interface for superseded summary persistence
+type SummaryVersionRepository interface {
+	// EnsureIndexes creates the indexes the collection relies on
+	EnsureIndexes(ctx context.Context) error
+
+	// Insert stores a superseded summary, assigning its ID
+	Insert(ctx context.Context, version *SummaryVersion) error
+
+	// FindByNewsID returns the superseded summaries of a transcript, newest first
+	FindByNewsID(ctx context.Context, newsID string) ([]SummaryVersion, error)
+}
+
+// IngestionCursorRepository defines the interface for scheduled ingestion cursor persistence
+type IngestionCursorRepository interface {
+	// Find returns the cursor of a source or ErrCursorNotFound
+	Find(ctx context.Context, source string) (*SourceCursor, error)
+
+	// Save stores the cursor, replacing the previous one
+	Save(ctx context.Context, cursor *SourceCursor) error
+}
+
+// LockRepository defines the interface for lease lock persistence
+type LockRepository interface {
+	// EnsureIndexes creates the TTL index that removes expired leases
+	EnsureIndexes(ctx context.Context) error
+
+	// TryAcquire stores the lease if the lock is free or expired, and otherwise
+	// returns a *LockHeldError with the current lease
+	TryAcquire(ctx context.Context, lease *Lease) error
+
+	// Renew extends a lease held with the given token, returning ErrLockLost if it is gone
+	Renew(ctx context.Context, name, token string, expiresAt time.Time) error
+
+	// SetJob records the job a lease is guarding
+	SetJob(ctx context.Context, name, token, jobID string) error
+
+	// Release removes a lease held with the given token
+	Release(ctx context.Context, name, token string) error
+}
+
+// LedgerRepository defines the interface for per-announcement ingestion ledger persistence
+type LedgerRepository interface {
+	// EnsureIndexes creates the indexes used to list failures and find due retries
+	EnsureIndexes(ctx context.Context) error
+
+	// Enqueue marks announcements as queued by a job, creating entries for new ones
+	Enqueue(ctx context.Context, jobID primitive.ObjectID, announcements []Announcement) error
+
+	// Update sets the given fields on an entry without counting an attempt
+	Update(ctx context.Context, newsID string, fields bson.M) error
+
+	// RecordAttempt moves an entry to the outcome of a processing attempt, counts the
+	// attempt, clears any scheduled retry and returns the updated entry
+	RecordAttempt(ctx context.Context, newsID string, state LedgerState, reason string) (*LedgerEntry, error)
+
+	// Find returns the entries matching the filter with options
+	Find(ctx context.Context, filter bson.M, opts *options.FindOptions) ([]LedgerEntry, error)
+
+	// Count counts the entries matching the filter
+	Count(ctx context.Context, filter bson.M) (int64, error)
+}
+
+// UserRepository defines the interface for user persistence
+type UserRepository interface {
+	// EnsureIndexes creates the unique email index
+	EnsureIndexes(ctx context.Context) error
+
+	// Create stores a new user, assigning its ID, or returns ErrUserExists
+	Create(ctx context.Context, user *User) error
+
+	// FindByEmail returns the user with the given email or ErrUserNotFound
+	FindByEmail(ctx context.Context, email string) (*User, error)
+
+	// Count counts the stored users
+	Count(ctx context.Context) (int64, error)
+
+	// TouchLogin records a successful login
+	TouchLogin(ctx context.Context, id primitive.ObjectID, at time.Time) error
+}
+
+// APIKeyRepository defines the interface for API key persistence
+type APIKeyRepository interface {
+	// EnsureIndexes creates the unique hash index
+	EnsureIndexes(ctx context.Context) error
+
+	// Create stores a new key, assigning its ID
+	Create(ctx context.Context, key *APIKey) error
+
+	// FindActiveByHash returns the unrevoked key with the given hash or ErrAPIKeyNotFound
+	FindActiveByHash(ctx context.Context, hash string) (*APIKey, error)
+
+	// List returns every key, newest first
+	List(ctx context.Context) ([]APIKey, error)
+
+	// Revoke marks a key as revoked, returning ErrAPIKeyNotFound if there is no active key with the ID
+	Revoke(ctx context.Context, id primitive.ObjectID, at time.Time) error
+
+	// TouchUsed records when a key was last used
+	TouchUsed(ctx context.Context, id primitive.ObjectID, at time.Time) error
+}
+
+// WatchlistRepository defines the interface for watchlist persistence
+type WatchlistRepository interface {
+	// EnsureIndexes creates the indexes used to list a user's watchlists and find those following a company
+	EnsureIndexes(ctx context.Context) error
+
+	// Create stores a new watchlist, assigning its ID
+	Create(ctx context.Context, watchlist *Watchlist) error
+
+	// FindByID returns the watchlist with the given ID or ErrWatchlistNotFound
+	FindByID(ctx context.Context, id primitive.ObjectID) (*Watchlist, error)
+
+	// FindByOwner returns the watchlists of a user, oldest first
+	FindByOwner(ctx context.Context, owner string) ([]Watchlist, error)
+
+	// Rename changes the name of a watchlist
+	Rename(ctx context.Context, id primitive.ObjectID, name string) error
+
+	// AddCompany adds a company unless the list already follows it or is full,
+	// and returns the updated watchlist
+	AddCompany(ctx context.Context, id primitive.ObjectID, company WatchedCompany) (*Watchlist, error)
+
+	// RemoveCompany removes a company and returns the updated watchlist
+	RemoveCompany(ctx context.Context, id primitive.ObjectID, scripCode int) (*Watchlist, error)
+
+	// Delete removes a watchlist
+	Delete(ctx context.Context, id primitive.ObjectID) error
+
+	// FindIDsByScripCode returns the IDs of the watchlists following a company
+	FindIDsByScripCode(ctx context.Context, scripCode int) ([]primitive.ObjectID, error)
+}
+
+// WebhookRepository defines the interface for webhook persistence
+type WebhookRepository interface {
+	// Create stores a new webhook, assigning its ID
+	Create(ctx context.Context, webhook *Webhook) error
+
+	// FindByID returns the webhook with the given ID or ErrWebhookNotFound
+	FindByID(ctx context.Context, id primitive.ObjectID) (*Webhook, error)
+
+	// List returns every webhook, oldest first
+	List(ctx context.Context) ([]Webhook, error)
+
+	// FindActive returns the webhooks that are sent events
+	FindActive(ctx context.Context) ([]Webhook, error)
+
+	// Update sets the given fields on a webhook
+	Update(ctx context.Context, id primitive.ObjectID, fields bson.M) error
+
+	// Delete removes a webhook
+	Delete(ctx context.Context, id primitive.ObjectID) error
+}
+
+// WebhookDeliveryRepository defines the interface for webhook delivery log persistence
+type WebhookDeliveryRepository interface {
+	// EnsureIndexes creates the indexes used to list a webhook's deliveries and find due retries,
+	// and the TTL index that expires old deliveries
+	EnsureIndexes(ctx context.Context) error
+
+	// Create stores a new delivery, assigning its ID
+	Create(ctx context.Context, delivery *WebhookDelivery) error
+
+	// RecordAttempt appends an attempt and moves the delivery to state, scheduling the
+	// next attempt at nextAttemptAt when it is still pending
+	RecordAttempt(ctx context.Context, id primitive.ObjectID, attempt DeliveryAttempt, state DeliveryState, nextAttemptAt *time.Time) error
+
+	// ClaimDue takes a pending delivery whose next attempt is due, pushing its next
+	// attempt to leaseUntil so no other replica picks it up, or returns ErrDeliveryNotFound
+	ClaimDue(ctx context.Context, now, leaseUntil time.Time) (*WebhookDelivery, error)
+
+	// FindByWebhook returns the deliveries of a webhook matching the filter with options
+	FindByWebhook(ctx context.Context, webhookID primitive.ObjectID, filter bson.M, opts *options.FindOptions) ([]WebhookDelivery, error)
+
+	// CountByWebhook counts the deliveries of a webhook matching the filter
+	CountByWebhook(ctx context.Context, webhookID primitive.ObjectID, filter bson.M) (int64, error)
+}
+
+// DigestSubscriptionRepository defines the interface for email digest subscription persistence
+type DigestSubscriptionRepository interface {
+	// EnsureIndexes creates the unique index on email
+	EnsureIndexes(ctx context.Context) error
+
+	// Upsert creates or replaces the subscription of subscription.Email
+	Upsert(ctx context.Context, subscription *DigestSubscription) error
+
+	// FindByEmail returns the subscription of an email or ErrDigestSubscriptionNotFound
+	FindByEmail(ctx context.Context, email string) (*DigestSubscription, error)
+
+	// List returns every subscription
+	List(ctx context.Context) ([]DigestSubscription, error)
+
+	// Delete removes the subscription of an email
+	Delete(ctx context.Context, email string) error
+
+	// TouchSent records when a digest was last sent to a subscription
+	TouchSent(ctx context.Context, id primitive.ObjectID, at time.Time) error
+}
+
+// CompanyRepository defines the interface for the company master list
+type CompanyRepository interface {
+	// EnsureIndexes creates the unique index on scrip_code, the case-insensitive index on sector
+	// and the text, name prefix and trigram indexes used by search
+	EnsureIndexes(ctx context.Context) error
+
+	// Upsert creates or updates the company with company.ScripCode
+	Upsert(ctx context.Context, company *Company) error
+
+	// FindByScripCode returns the company with the given scrip code or ErrCompanyNotFound
+	FindByScripCode(ctx context.Context, scripCode int) (*Company, error)
+
+	// FindScripCodesBySector returns the scrip codes of the companies in a sector, ignoring case
+	FindScripCodesBySector(ctx context.Context, sector string) ([]int, error)
+
+	// FindProfiled returns which of the given scrip codes have had their profile read from BSE
+	FindProfiled(ctx context.Context, scripCodes []int) (map[int]bool, error)
+
+	// Sectors lists the known sectors with their company counts, by name
+	Sectors(ctx context.Context) ([]SectorCount, error)
+
+	// InsertMissing stores the companies whose scrip code is not known yet, leaving known ones alone
+	InsertMissing(ctx context.Context, companies []Company) error
+
+	// BackfillSearchKeys derives the search fields of companies stored without them
+	BackfillSearchKeys(ctx context.Context) (int, error)
+
+	// FindByNamePrefix returns companies whose normalized name starts with prefix, by name
+	FindByNamePrefix(ctx context.Context, prefix string, limit int) ([]Company, error)
+
+	// SearchText runs a full-text search over names and symbols, best text score first
+	SearchText(ctx context.Context, query string, limit int) ([]ScoredCompany, error)
+
+	// FindByTrigrams returns the companies sharing the most of the given trigrams, scored by how many they share
+	FindByTrigrams(ctx context.Context, trigrams []string, limit int) ([]ScoredCompany, error)
+}
@@ -0,0 +1,30 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ReprocessFilter selects the stored summaries a reprocess job runs through the
+// summarizer again. Empty fields do not restrict the selection.
+type ReprocessFilter struct {
+	Name          string `bson:"name,omitempty" json:"name,omitempty"`
+	ScripCode     int    `bson:"scrip_code,omitempty" json:"scrip_code,omitempty"`
+	From          string `bson:"from,omitempty" json:"from,omitempty"`
+	To            string `bson:"to,omitempty" json:"to,omitempty"`
+	PromptVersion string `bson:"prompt_version,omitempty" json:"prompt_version,omitempty"`
+	NAOnly        bool   `bson:"na_only,omitempty" json:"na_only,omitempty"`
+	Limit         int    `bson:"limit,omitempty" json:"limit,omitempty"`
+}
+
+// SummaryVersion is a superseded copy of a ConcallSummary, kept when the concall
+// is reprocessed so earlier guidance can be compared with the current one
+type SummaryVersion struct {
+	ID           primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	SummaryID    primitive.ObjectID `bson:"summary_id" json:"summary_id"`
+	NewsID       string             `bson:"news_id" json:"news_id"`
+	Summary      ConcallSummary     `bson:"summary" json:"summary"`
+	SupersededAt time.Time          `bson:"superseded_at" json:"superseded_at"`
+	SupersededBy primitive.ObjectID `bson:"superseded_by_job,omitempty" json:"superseded_by_job,omitempty"`
+}
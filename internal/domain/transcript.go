@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrScannedPDF is returned when a PDF has no usable text layer, typically a scanned document
+var ErrScannedPDF = errors.New("PDF has no extractable text (scanned or empty)")
+
+// ErrTranscriptNotFound is returned when no transcript text is stored for a NewsID
+var ErrTranscriptNotFound = errors.New("transcript not found")
+
+// TranscriptPage is the extracted text of a single PDF page
+type TranscriptPage struct {
+	Number int    `bson:"number" json:"number"`
+	Text   string `bson:"text" json:"text"`
+}
+
+// Transcript is the text extracted from a concall PDF, kept so it can be
+// re-analysed without downloading it from BSE again
+type Transcript struct {
+	NewsID         string           `bson:"_id" json:"news_id"`
+	ScripCode      int              `bson:"scrip_code,omitempty" json:"scrip_code,omitempty"`
+	Name           string           `bson:"name" json:"name"`
+	Date           string           `bson:"date" json:"date"`
+	AttachmentName string           `bson:"attachment_name,omitempty" json:"attachment_name,omitempty"`
+	DocumentID     string           `bson:"document_id,omitempty" json:"document_id,omitempty"`
+	Pages          []TranscriptPage `bson:"pages" json:"pages"`
+	PageCount      int              `bson:"page_count" json:"page_count"`
+	CharCount      int              `bson:"char_count" json:"char_count"`
+	Scanned        bool             `bson:"scanned" json:"scanned"`
+	ExtractedAt    time.Time        `bson:"extracted_at" json:"extracted_at"`
+}
+
+// Text joins the pages, marking where each page starts so quotes can be traced back
+func (t *Transcript) Text() string {
+	var b strings.Builder
+	for _, p := range t.Pages {
+		fmt.Fprintf(&b, "--- Page %d ---\n%s\n", p.Number, p.Text)
+	}
+	return b.String()
+}
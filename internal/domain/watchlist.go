@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MaxWatchlistCompanies caps how many companies one watchlist may follow
+const MaxWatchlistCompanies = 200
+
+var (
+	// ErrWatchlistNotFound is returned when no watchlist matches the lookup
+	ErrWatchlistNotFound = errors.New("watchlist not found")
+	// ErrWatchlistFull is returned when adding a company to a list that already has MaxWatchlistCompanies
+	ErrWatchlistFull = errors.New("watchlist is full")
+)
+
+// Watchlist is a named set of companies a user follows
+type Watchlist struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Owner     string             `bson:"owner" json:"owner"` // Principal.Subject of the creator
+	Name      string             `bson:"name" json:"name"`
+	Companies []WatchedCompany   `bson:"companies" json:"companies"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// WatchedCompany is a company on a watchlist, identified by its BSE scrip code
+type WatchedCompany struct {
+	ScripCode int       `bson:"scrip_code" json:"scrip_code"`
+	Name      string    `bson:"name" json:"name"`
+	AddedAt   time.Time `bson:"added_at" json:"added_at"`
+}
+
+// ScripCodes returns the scrip codes of the companies on the list
+func (w Watchlist) ScripCodes() []int {
+	codes := make([]int, len(w.Companies))
+	for i, company := range w.Companies {
+		codes[i] = company.ScripCode
+	}
+	return codes
+}
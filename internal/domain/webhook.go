@@ -0,0 +1,82 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	// ErrWebhookNotFound is returned when no webhook matches the lookup
+	ErrWebhookNotFound = errors.New("webhook not found")
+	// ErrDeliveryNotFound is returned when no webhook delivery matches the lookup
+	ErrDeliveryNotFound = errors.New("webhook delivery not found")
+)
+
+// Webhook events
+const (
+	// WebhookEventConcallAdded is sent when a concall summary is stored for the first time
+	WebhookEventConcallAdded = "concall.added"
+	// WebhookEventPing is sent by the test-delivery endpoint
+	WebhookEventPing = "ping"
+)
+
+// Webhook is an HTTP endpoint that is sent a signed JSON payload for each new
+// concall summary matching its filter
+type Webhook struct {
+	ID     primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Name   string             `bson:"name" json:"name"`
+	URL    string             `bson:"url" json:"url"`
+	Secret string             `bson:"secret" json:"-"` // HMAC-SHA256 key for the signature header
+	Filter WebhookFilter      `bson:"filter" json:"filter"`
+	Active bool               `bson:"active" json:"active"`
+	// CreatedBy is the Principal.Subject of whoever registered the webhook
+	CreatedBy string    `bson:"created_by" json:"created_by"`
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// WebhookFilter selects the summaries a webhook is sent. Empty fields do not
+// restrict the selection; set fields must all match.
+type WebhookFilter struct {
+	ScripCodes  []int  `bson:"scrip_codes,omitempty" json:"scrip_codes,omitempty"`
+	WatchlistID string `bson:"watchlist_id,omitempty" json:"watchlist_id,omitempty"`
+	// HasGuidance skips summaries whose guidance is "NA"
+	HasGuidance bool `bson:"has_guidance,omitempty" json:"has_guidance,omitempty"`
+}
+
+// DeliveryState is where a webhook delivery stands
+type DeliveryState string
+
+const (
+	// DeliveryPending is waiting for its first or next attempt
+	DeliveryPending DeliveryState = "pending"
+	// DeliverySucceeded got a 2xx response
+	DeliverySucceeded DeliveryState = "succeeded"
+	// DeliveryFailed used up its attempts
+	DeliveryFailed DeliveryState = "failed"
+)
+
+// WebhookDelivery is one payload sent to a webhook, with every attempt made to
+// deliver it
+type WebhookDelivery struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	WebhookID     primitive.ObjectID `bson:"webhook_id" json:"webhook_id"`
+	Event         string             `bson:"event" json:"event"`
+	NewsID        string             `bson:"news_id,omitempty" json:"news_id,omitempty"`
+	Payload       string             `bson:"payload" json:"payload"`
+	State         DeliveryState      `bson:"state" json:"state"`
+	Attempts      []DeliveryAttempt  `bson:"attempts" json:"attempts"`
+	NextAttemptAt *time.Time         `bson:"next_attempt_at,omitempty" json:"next_attempt_at,omitempty"`
+	CreatedAt     time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt     time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// DeliveryAttempt is the outcome of one POST to a webhook
+type DeliveryAttempt struct {
+	At         time.Time `bson:"at" json:"at"`
+	StatusCode int       `bson:"status_code,omitempty" json:"status_code,omitempty"`
+	Error      string    `bson:"error,omitempty" json:"error,omitempty"`
+	DurationMS int64     `bson:"duration_ms" json:"duration_ms"`
+}
@@ -1,13 +1,76 @@
 package interfaces
 
 import (
+	"context"
+	"time"
+
+	"concall-analyser/internal/domain"
+
 	"github.com/gin-gonic/gin"
 )
 
+// Ingestor runs ingestion outside of an HTTP request, e.g. from the scheduler
+type Ingestor interface {
+	// RunFetch runs a fetch job for the date range and returns it once it has finished
+	RunFetch(ctx context.Context, from, to time.Time) (*domain.FetchJob, error)
+
+	// RetryDueFailures runs the failed announcements whose backoff has elapsed and
+	// returns the finished job, or nil when nothing was due
+	RetryDueFailures(ctx context.Context) (*domain.FetchJob, error)
+
+	// ResumeInterruptedJobs starts the oldest job left unfinished by a process that
+	// went away and returns it without waiting, or nil when there is none. While
+	// another run holds the ingestion lock it returns a *domain.LockHeldError.
+	ResumeInterruptedJobs(ctx context.Context) (*domain.FetchJob, error)
+
+	// SyncCompanies adds the companies with stored concalls to the search index
+	// and reads the sector and industry of those that do not have them yet
+	SyncCompanies(ctx context.Context) error
+}
+
 type Usecase interface {
+	Ingestor
+
 	FetchConcallDataHandler(c *gin.Context)
 	ListConcallHandler(c *gin.Context)
 	FindConcallHandler(c *gin.Context)
 	CleanupConcallHandler(c *gin.Context)
 	GetAnalyticsHandler(c *gin.Context)
+	GetJobHandler(c *gin.Context)
+	CancelJobHandler(c *gin.Context)
+	DownloadDocumentHandler(c *gin.Context)
+	ReprocessConcallsHandler(c *gin.Context)
+	GetConcallVersionsHandler(c *gin.Context)
+	ListIngestionFailuresHandler(c *gin.Context)
+	RetryIngestionHandler(c *gin.Context)
+
+	LoginHandler(c *gin.Context)
+	CurrentUserHandler(c *gin.Context)
+	CreateUserHandler(c *gin.Context)
+	CreateAPIKeyHandler(c *gin.Context)
+	ListAPIKeysHandler(c *gin.Context)
+	RevokeAPIKeyHandler(c *gin.Context)
+
+	ListWatchlistsHandler(c *gin.Context)
+	CreateWatchlistHandler(c *gin.Context)
+	GetWatchlistHandler(c *gin.Context)
+	RenameWatchlistHandler(c *gin.Context)
+	DeleteWatchlistHandler(c *gin.Context)
+	AddWatchlistCompanyHandler(c *gin.Context)
+	RemoveWatchlistCompanyHandler(c *gin.Context)
+	WatchlistConcallsHandler(c *gin.Context)
+
+	ListWebhooksHandler(c *gin.Context)
+	CreateWebhookHandler(c *gin.Context)
+	GetWebhookHandler(c *gin.Context)
+	UpdateWebhookHandler(c *gin.Context)
+	DeleteWebhookHandler(c *gin.Context)
+	ListWebhookDeliveriesHandler(c *gin.Context)
+	TestWebhookHandler(c *gin.Context)
+	GetDigestSubscriptionHandler(c *gin.Context)
+	UpdateDigestSubscriptionHandler(c *gin.Context)
+	DeleteDigestSubscriptionHandler(c *gin.Context)
+	PreviewDigestHandler(c *gin.Context)
+	ListSectorsHandler(c *gin.Context)
+	SuggestCompaniesHandler(c *gin.Context)
 }
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// redactedParams are query parameters carrying credentials, masked in access logs
+var redactedParams = []string{"access_token"}
+
+// Logger writes gin's access log with credentials in the query string masked
+func Logger() gin.HandlerFunc {
+	return gin.LoggerWithConfig(gin.LoggerConfig{
+		Formatter: func(param gin.LogFormatterParams) string {
+			if param.Latency > time.Minute {
+				param.Latency = param.Latency.Truncate(time.Second)
+			}
+			return fmt.Sprintf("[GIN] %v | %3d | %13v | %15s | %-7s %#v\n%s",
+				param.TimeStamp.Format("2006/01/02 - 15:04:05"),
+				param.StatusCode,
+				param.Latency,
+				param.ClientIP,
+				param.Method,
+				redactPath(param.Path),
+				param.ErrorMessage,
+			)
+		},
+	})
+}
+
+// redactPath masks the credential parameters of a logged path and query
+func redactPath(path string) string {
+	u, err := url.Parse(path)
+	if err != nil || u.RawQuery == "" {
+		return path
+	}
+
+	query := u.Query()
+	redacted := false
+	for _, name := range redactedParams {
+		if query.Has(name) {
+			query.Set(name, "REDACTED")
+			redacted = true
+		}
+	}
+	if !redacted {
+		return path
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRedactPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "/api/list_concalls?page=2", want: "/api/list_concalls?page=2"},
+		{path: "/ws/analytics?access_token=eyJhbGciOi.secret", want: "/ws/analytics?access_token=REDACTED"},
+		{path: "/api/events?topics=analytics&access_token=ca_secret", want: "/api/events?access_token=REDACTED&topics=analytics"},
+		{path: "/healthz", want: "/healthz"},
+	}
+	for _, tt := range tests {
+		if got := redactPath(tt.path); got != tt.want {
+			t.Errorf("redactPath(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestCredentialOfQueryParameter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		target string
+		want   string
+	}{
+		{target: "/ws/analytics?access_token=token", want: "token"},
+		{target: "/api/events?topics=analytics&access_token=token", want: "token"},
+		{target: "/api/list_concalls?access_token=token", want: ""},
+		{target: "/api/auth/me?access_token=token", want: ""},
+	}
+	for _, tt := range tests {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest("GET", tt.target, nil)
+		if got := credentialOf(c); got != tt.want {
+			t.Errorf("credentialOf(%s) = %q, want %q", tt.target, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"concall-analyser/internal/db"
+	"concall-analyser/internal/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type apiKeyRepository struct {
+	coll *mongo.Collection
+}
+
+// NewAPIKeyRepository creates a new MongoDB implementation of APIKeyRepository
+func NewAPIKeyRepository(db *db.MongoDB) domain.APIKeyRepository {
+	return &apiKeyRepository{
+		coll: db.Collection("api_keys"),
+	}
+}
+
+func (r *apiKeyRepository) EnsureIndexes(ctx context.Context) error {
+	model := mongo.IndexModel{
+		Keys:    bson.D{{Key: "hash", Value: 1}},
+		Options: options.Index().SetName("hash_unique").SetUnique(true),
+	}
+
+	if _, err := r.coll.Indexes().CreateOne(ctx, model); err != nil {
+		return fmt.Errorf("failed to create indexes: %w", err)
+	}
+	return nil
+}
+
+func (r *apiKeyRepository) Create(ctx context.Context, key *domain.APIKey) error {
+	if key.ID.IsZero() {
+		key.ID = primitive.NewObjectID()
+	}
+
+	if _, err := r.coll.InsertOne(ctx, key); err != nil {
+		return fmt.Errorf("failed to insert api key: %w", err)
+	}
+	return nil
+}
+
+func (r *apiKeyRepository) FindActiveByHash(ctx context.Context, hash string) (*domain.APIKey, error) {
+	var key domain.APIKey
+	err := r.coll.FindOne(ctx, bson.M{"hash": hash, "revoked_at": bson.M{"$exists": false}}).Decode(&key)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, domain.ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find api key: %w", err)
+	}
+	return &key, nil
+}
+
+func (r *apiKeyRepository) List(ctx context.Context) ([]domain.APIKey, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	cursor, err := r.coll.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query api keys: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	keys := []domain.APIKey{}
+	if err := cursor.All(ctx, &keys); err != nil {
+		return nil, fmt.Errorf("failed to decode api keys: %w", err)
+	}
+	return keys, nil
+}
+
+func (r *apiKeyRepository) Revoke(ctx context.Context, id primitive.ObjectID, at time.Time) error {
+	filter := bson.M{"_id": id, "revoked_at": bson.M{"$exists": false}}
+	result, err := r.coll.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"revoked_at": at}})
+	if err != nil {
+		return fmt.Errorf("failed to revoke api key: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+func (r *apiKeyRepository) TouchUsed(ctx context.Context, id primitive.ObjectID, at time.Time) error {
+	if _, err := r.coll.UpdateByID(ctx, id, bson.M{"$set": bson.M{"last_used_at": at}}); err != nil {
+		return fmt.Errorf("failed to update api key: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,303 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"concall-analyser/internal/db"
+	"concall-analyser/internal/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// caseInsensitive compares strings ignoring case; queries must use it to be served by the sector index
+var caseInsensitive = &options.Collation{Locale: "en", Strength: 2}
+
+type companyRepository struct {
+	coll *mongo.Collection
+}
+
+// NewCompanyRepository creates a new MongoDB implementation of CompanyRepository
+func NewCompanyRepository(db *db.MongoDB) domain.CompanyRepository {
+	return &companyRepository{
+		coll: db.Collection("companies"),
+	}
+}
+
+func (r *companyRepository) EnsureIndexes(ctx context.Context) error {
+	models := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "scrip_code", Value: 1}},
+			Options: options.Index().SetName("scrip_code_unique").SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "sector", Value: 1}},
+			Options: options.Index().SetName("sector_ci").SetCollation(caseInsensitive),
+		},
+		// Search
+		{
+			Keys: bson.D{{Key: "name", Value: "text"}, {Key: "symbol", Value: "text"}},
+			Options: options.Index().
+				SetName("name_symbol_text").
+				SetWeights(bson.M{"name": 5, "symbol": 10}),
+		},
+		{
+			Keys:    bson.D{{Key: "search_name", Value: 1}},
+			Options: options.Index().SetName("search_name"),
+		},
+		{
+			Keys:    bson.D{{Key: "trigrams", Value: 1}},
+			Options: options.Index().SetName("trigrams"),
+		},
+	}
+
+	if _, err := r.coll.Indexes().CreateMany(ctx, models); err != nil {
+		return fmt.Errorf("failed to create indexes: %w", err)
+	}
+	return nil
+}
+
+func (r *companyRepository) Upsert(ctx context.Context, company *domain.Company) error {
+	company.UpdatedAt = time.Now()
+	company.SearchName = domain.NormalizeCompanyName(company.Name)
+	company.Trigrams = domain.Trigrams(company.SearchName)
+	set := bson.M{
+		"name":        company.Name,
+		"search_name": company.SearchName,
+		"trigrams":    company.Trigrams,
+		"updated_at":  company.UpdatedAt,
+	}
+	if company.Symbol != "" {
+		set["symbol"] = company.Symbol
+	}
+	if company.Sector != "" {
+		set["sector"] = company.Sector
+	}
+	if company.Industry != "" {
+		set["industry"] = company.Industry
+	}
+	if company.ProfileFetchedAt != nil {
+		set["profile_fetched_at"] = company.ProfileFetchedAt
+	}
+
+	update := bson.M{
+		"$set":         set,
+		"$setOnInsert": bson.M{"_id": primitive.NewObjectID()},
+	}
+	_, err := r.coll.UpdateOne(ctx, bson.M{"scrip_code": company.ScripCode}, update, options.Update().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("failed to upsert company %d: %w", company.ScripCode, err)
+	}
+	return nil
+}
+
+func (r *companyRepository) FindByScripCode(ctx context.Context, scripCode int) (*domain.Company, error) {
+	var company domain.Company
+	err := r.coll.FindOne(ctx, bson.M{"scrip_code": scripCode}).Decode(&company)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, domain.ErrCompanyNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find company: %w", err)
+	}
+	return &company, nil
+}
+
+func (r *companyRepository) FindScripCodesBySector(ctx context.Context, sector string) ([]int, error) {
+	opts := options.Find().
+		SetCollation(caseInsensitive).
+		SetProjection(bson.M{"scrip_code": 1})
+	cursor, err := r.coll.Find(ctx, bson.M{"sector": sector}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query companies: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var docs []struct {
+		ScripCode int `bson:"scrip_code"`
+	}
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, fmt.Errorf("failed to decode companies: %w", err)
+	}
+	codes := make([]int, len(docs))
+	for i, doc := range docs {
+		codes[i] = doc.ScripCode
+	}
+	return codes, nil
+}
+
+func (r *companyRepository) FindProfiled(ctx context.Context, scripCodes []int) (map[int]bool, error) {
+	profiled := make(map[int]bool)
+	if len(scripCodes) == 0 {
+		return profiled, nil
+	}
+
+	filter := bson.M{
+		"scrip_code":         bson.M{"$in": scripCodes},
+		"profile_fetched_at": bson.M{"$exists": true},
+	}
+	cursor, err := r.coll.Find(ctx, filter, options.Find().SetProjection(bson.M{"scrip_code": 1}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query companies: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var doc struct {
+			ScripCode int `bson:"scrip_code"`
+		}
+		if err := cursor.Decode(&doc); err == nil {
+			profiled[doc.ScripCode] = true
+		}
+	}
+	return profiled, cursor.Err()
+}
+
+func (r *companyRepository) Sectors(ctx context.Context) ([]domain.SectorCount, error) {
+	pipeline := []bson.M{
+		{"$match": bson.M{"sector": bson.M{"$exists": true, "$ne": ""}}},
+		{"$group": bson.M{"_id": "$sector", "companies": bson.M{"$sum": 1}}},
+		{"$sort": bson.M{"_id": 1}},
+	}
+	cursor, err := r.coll.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate sectors: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	sectors := []domain.SectorCount{}
+	if err := cursor.All(ctx, &sectors); err != nil {
+		return nil, fmt.Errorf("failed to decode sectors: %w", err)
+	}
+	return sectors, nil
+}
+
+func (r *companyRepository) InsertMissing(ctx context.Context, companies []domain.Company) error {
+	if len(companies) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	models := make([]mongo.WriteModel, 0, len(companies))
+	for _, company := range companies {
+		searchName := domain.NormalizeCompanyName(company.Name)
+		insert := bson.M{
+			"_id":         primitive.NewObjectID(),
+			"name":        company.Name,
+			"search_name": searchName,
+			"trigrams":    domain.Trigrams(searchName),
+			"updated_at":  now,
+		}
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"scrip_code": company.ScripCode}).
+			SetUpdate(bson.M{"$setOnInsert": insert}).
+			SetUpsert(true))
+	}
+
+	if _, err := r.coll.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false)); err != nil {
+		return fmt.Errorf("failed to insert companies: %w", err)
+	}
+	return nil
+}
+
+func (r *companyRepository) BackfillSearchKeys(ctx context.Context) (int, error) {
+	cursor, err := r.coll.Find(ctx, bson.M{"trigrams": bson.M{"$exists": false}}, options.Find().SetProjection(bson.M{"name": 1}))
+	if err != nil {
+		return 0, fmt.Errorf("failed to query companies: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var models []mongo.WriteModel
+	for cursor.Next(ctx) {
+		var doc struct {
+			ID   primitive.ObjectID `bson:"_id"`
+			Name string             `bson:"name"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			continue
+		}
+		searchName := domain.NormalizeCompanyName(doc.Name)
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": doc.ID}).
+			SetUpdate(bson.M{"$set": bson.M{"search_name": searchName, "trigrams": domain.Trigrams(searchName)}}))
+	}
+	if err := cursor.Err(); err != nil {
+		return 0, fmt.Errorf("failed to read companies: %w", err)
+	}
+	if len(models) == 0 {
+		return 0, nil
+	}
+
+	if _, err := r.coll.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false)); err != nil {
+		return 0, fmt.Errorf("failed to update companies: %w", err)
+	}
+	return len(models), nil
+}
+
+func (r *companyRepository) FindByNamePrefix(ctx context.Context, prefix string, limit int) ([]domain.Company, error) {
+	// An anchored, case-sensitive regex on the lowercased name is served by the index
+	filter := bson.M{"search_name": bson.M{"$regex": "^" + regexp.QuoteMeta(prefix)}}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "search_name", Value: 1}}).
+		SetLimit(int64(limit))
+	cursor, err := r.coll.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query companies: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	companies := []domain.Company{}
+	if err := cursor.All(ctx, &companies); err != nil {
+		return nil, fmt.Errorf("failed to decode companies: %w", err)
+	}
+	return companies, nil
+}
+
+func (r *companyRepository) SearchText(ctx context.Context, query string, limit int) ([]domain.ScoredCompany, error) {
+	score := bson.M{"$meta": "textScore"}
+	opts := options.Find().
+		SetProjection(bson.M{"score": score}).
+		SetSort(bson.D{{Key: "score", Value: score}}).
+		SetLimit(int64(limit))
+	cursor, err := r.coll.Find(ctx, bson.M{"$text": bson.M{"$search": query}}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search companies: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	companies := []domain.ScoredCompany{}
+	if err := cursor.All(ctx, &companies); err != nil {
+		return nil, fmt.Errorf("failed to decode companies: %w", err)
+	}
+	return companies, nil
+}
+
+func (r *companyRepository) FindByTrigrams(ctx context.Context, trigrams []string, limit int) ([]domain.ScoredCompany, error) {
+	if len(trigrams) == 0 {
+		return []domain.ScoredCompany{}, nil
+	}
+
+	pipeline := []bson.M{
+		{"$match": bson.M{"trigrams": bson.M{"$in": trigrams}}},
+		{"$addFields": bson.M{"score": bson.M{"$size": bson.M{"$setIntersection": bson.A{"$trigrams", trigrams}}}}},
+		{"$sort": bson.D{{Key: "score", Value: -1}, {Key: "search_name", Value: 1}}},
+		{"$limit": limit},
+	}
+	cursor, err := r.coll.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search companies: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	companies := []domain.ScoredCompany{}
+	if err := cursor.All(ctx, &companies); err != nil {
+		return nil, fmt.Errorf("failed to decode companies: %w", err)
+	}
+	return companies, nil
+}
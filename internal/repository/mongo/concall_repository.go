@@ -2,7 +2,9 @@ package mongo
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
 
 	"concall-analyser/internal/db"
 	"concall-analyser/internal/domain"
@@ -12,6 +14,9 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// duplicateKeyCode is the MongoDB server error code for a unique index violation
+const duplicateKeyCode = 11000
+
 type concallRepository struct {
 	coll *mongo.Collection
 }
@@ -23,29 +28,70 @@ func NewConcallRepository(db *db.MongoDB) domain.ConcallRepository {
 	}
 }
 
-func (r *concallRepository) FindExistingNames(ctx context.Context, names []string) (map[string]bool, error) {
-	if len(names) == 0 {
+func (r *concallRepository) EnsureIndexes(ctx context.Context) error {
+	// Summaries written before NewsID was tracked have no news_id, so the unique
+	// index only covers documents that actually carry one
+	models := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "news_id", Value: 1}},
+			Options: options.Index().
+				SetName("news_id_unique").
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{"news_id": bson.M{"$type": "string"}}),
+		},
+		{
+			Keys:    bson.D{{Key: "scrip_code", Value: 1}, {Key: "date", Value: -1}},
+			Options: options.Index().SetName("scrip_code_date"),
+		},
+		// Sorts and filters of list_concalls
+		{
+			Keys:    bson.D{{Key: "date", Value: -1}},
+			Options: options.Index().SetName("date"),
+		},
+		{
+			Keys:    bson.D{{Key: "name", Value: 1}, {Key: "date", Value: -1}},
+			Options: options.Index().SetName("name_date"),
+		},
+		{
+			Keys:    bson.D{{Key: "guidance_items.metric", Value: 1}, {Key: "guidance_items.growth_pct", Value: -1}},
+			Options: options.Index().SetName("guidance_metric_growth"),
+		},
+		{
+			Keys:    bson.D{{Key: "guidance_items.growth_pct", Value: -1}, {Key: "date", Value: -1}},
+			Options: options.Index().SetName("guidance_growth_date"),
+		},
+	}
+
+	if _, err := r.coll.Indexes().CreateMany(ctx, models); err != nil {
+		return fmt.Errorf("failed to create indexes: %w", err)
+	}
+	return nil
+}
+
+func (r *concallRepository) FindExistingNewsIDs(ctx context.Context, newsIDs []string) (map[string]bool, error) {
+	if len(newsIDs) == 0 {
 		return make(map[string]bool), nil
 	}
 
-	filter := bson.M{"name": bson.M{"$in": names}}
-	cursor, err := r.coll.Find(ctx, filter)
+	filter := bson.M{"news_id": bson.M{"$in": newsIDs}}
+	opts := options.Find().SetProjection(bson.M{"news_id": 1})
+	cursor, err := r.coll.Find(ctx, filter, opts)
 	if err != nil {
 		return nil, fmt.Errorf("mongo find error: %w", err)
 	}
 	defer cursor.Close(ctx)
 
-	existingNames := make(map[string]bool)
+	existing := make(map[string]bool)
 	for cursor.Next(ctx) {
 		var doc struct {
-			Name string `bson:"name"`
+			NewsID string `bson:"news_id"`
 		}
 		if err := cursor.Decode(&doc); err == nil {
-			existingNames[doc.Name] = true
+			existing[doc.NewsID] = true
 		}
 	}
 
-	return existingNames, nil
+	return existing, nil
 }
 
 func (r *concallRepository) InsertMany(ctx context.Context, summaries []domain.ConcallSummary) error {
@@ -58,14 +104,96 @@ func (r *concallRepository) InsertMany(ctx context.Context, summaries []domain.C
 		docs[i] = summary
 	}
 
-	_, err := r.coll.InsertMany(ctx, docs)
+	// Unordered so that one transcript that is already stored does not stop the rest
+	_, err := r.coll.InsertMany(ctx, docs, options.InsertMany().SetOrdered(false))
 	if err != nil {
+		if onlyDuplicateKeyErrors(err) {
+			log.Printf("⏭️ Some summaries were already stored, skipped duplicates: %v", err)
+			return nil
+		}
 		return fmt.Errorf("failed to insert summaries: %w", err)
 	}
 
 	return nil
 }
 
+func (r *concallRepository) Upsert(ctx context.Context, summary *domain.ConcallSummary) (bool, error) {
+	if summary.NewsID == "" {
+		if _, err := r.coll.InsertOne(ctx, summary); err != nil {
+			return false, fmt.Errorf("failed to insert summary: %w", err)
+		}
+		return true, nil
+	}
+
+	raw, err := bson.Marshal(summary)
+	if err != nil {
+		return false, fmt.Errorf("failed to encode summary: %w", err)
+	}
+	var fields bson.M
+	if err := bson.Unmarshal(raw, &fields); err != nil {
+		return false, fmt.Errorf("failed to encode summary: %w", err)
+	}
+	delete(fields, "_id")
+	delete(fields, "created_at")
+
+	filter := bson.M{"news_id": summary.NewsID}
+	update := bson.M{
+		"$set":         fields,
+		"$setOnInsert": bson.M{"_id": summary.ID, "created_at": summary.CreatedAt},
+	}
+	opts := options.Update().SetUpsert(true)
+
+	result, err := r.coll.UpdateOne(ctx, filter, update, opts)
+	if mongo.IsDuplicateKeyError(err) {
+		// Two upserts of the same NewsID raced to insert; the retry updates the winner
+		result, err = r.coll.UpdateOne(ctx, filter, update, opts)
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to upsert summary: %w", err)
+	}
+	return result.UpsertedCount > 0, nil
+}
+
+// onlyDuplicateKeyErrors reports whether every write error in a bulk insert is a duplicate key error
+func onlyDuplicateKeyErrors(err error) bool {
+	var bwe mongo.BulkWriteException
+	if !errors.As(err, &bwe) || bwe.WriteConcernError != nil || len(bwe.WriteErrors) == 0 {
+		return false
+	}
+	for _, we := range bwe.WriteErrors {
+		if we.Code != duplicateKeyCode {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *concallRepository) FindSummaries(ctx context.Context, filter bson.M, opts *options.FindOptions) ([]domain.ConcallSummary, error) {
+	cursor, err := r.coll.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query MongoDB: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var results []domain.ConcallSummary
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, fmt.Errorf("failed to decode documents: %w", err)
+	}
+
+	return results, nil
+}
+
+func (r *concallRepository) ReplaceSummary(ctx context.Context, summary *domain.ConcallSummary) error {
+	result, err := r.coll.ReplaceOne(ctx, bson.M{"_id": summary.ID}, summary)
+	if err != nil {
+		return fmt.Errorf("failed to replace summary: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("summary %s no longer exists", summary.ID.Hex())
+	}
+	return nil
+}
+
 func (r *concallRepository) FindWithFilter(ctx context.Context, filter bson.M, opts *options.FindOptions) ([]domain.ConcallLite, error) {
 	cursor, err := r.coll.Find(ctx, filter, opts)
 	if err != nil {
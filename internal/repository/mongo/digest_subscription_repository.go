@@ -0,0 +1,107 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"concall-analyser/internal/db"
+	"concall-analyser/internal/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type digestSubscriptionRepository struct {
+	coll *mongo.Collection
+}
+
+// NewDigestSubscriptionRepository creates a new MongoDB implementation of DigestSubscriptionRepository
+func NewDigestSubscriptionRepository(db *db.MongoDB) domain.DigestSubscriptionRepository {
+	return &digestSubscriptionRepository{
+		coll: db.Collection("digest_subscriptions"),
+	}
+}
+
+func (r *digestSubscriptionRepository) EnsureIndexes(ctx context.Context) error {
+	model := mongo.IndexModel{
+		Keys:    bson.D{{Key: "email", Value: 1}},
+		Options: options.Index().SetName("email_unique").SetUnique(true),
+	}
+
+	if _, err := r.coll.Indexes().CreateOne(ctx, model); err != nil {
+		return fmt.Errorf("failed to create indexes: %w", err)
+	}
+	return nil
+}
+
+func (r *digestSubscriptionRepository) Upsert(ctx context.Context, subscription *domain.DigestSubscription) error {
+	now := time.Now()
+	update := bson.M{
+		"$set": bson.M{"updated_at": now},
+		"$setOnInsert": bson.M{
+			"_id":        primitive.NewObjectID(),
+			"email":      subscription.Email,
+			"created_at": now,
+		},
+	}
+	if subscription.WatchlistID != "" {
+		update["$set"].(bson.M)["watchlist_id"] = subscription.WatchlistID
+	} else {
+		update["$unset"] = bson.M{"watchlist_id": ""}
+	}
+
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	err := r.coll.FindOneAndUpdate(ctx, bson.M{"email": subscription.Email}, update, opts).Decode(subscription)
+	if err != nil {
+		return fmt.Errorf("failed to upsert digest subscription: %w", err)
+	}
+	return nil
+}
+
+func (r *digestSubscriptionRepository) FindByEmail(ctx context.Context, email string) (*domain.DigestSubscription, error) {
+	var subscription domain.DigestSubscription
+	err := r.coll.FindOne(ctx, bson.M{"email": email}).Decode(&subscription)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, domain.ErrDigestSubscriptionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find digest subscription: %w", err)
+	}
+	return &subscription, nil
+}
+
+func (r *digestSubscriptionRepository) List(ctx context.Context) ([]domain.DigestSubscription, error) {
+	cursor, err := r.coll.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query digest subscriptions: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	subscriptions := []domain.DigestSubscription{}
+	if err := cursor.All(ctx, &subscriptions); err != nil {
+		return nil, fmt.Errorf("failed to decode digest subscriptions: %w", err)
+	}
+	return subscriptions, nil
+}
+
+func (r *digestSubscriptionRepository) Delete(ctx context.Context, email string) error {
+	result, err := r.coll.DeleteOne(ctx, bson.M{"email": email})
+	if err != nil {
+		return fmt.Errorf("failed to delete digest subscription: %w", err)
+	}
+	if result.DeletedCount == 0 {
+		return domain.ErrDigestSubscriptionNotFound
+	}
+	return nil
+}
+
+func (r *digestSubscriptionRepository) TouchSent(ctx context.Context, id primitive.ObjectID, at time.Time) error {
+	if _, err := r.coll.UpdateByID(ctx, id, bson.M{"$set": bson.M{"last_sent_at": at}}); err != nil {
+		return fmt.Errorf("failed to update digest subscription: %w", err)
+	}
+	return nil
+}
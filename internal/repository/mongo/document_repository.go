@@ -0,0 +1,79 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+
+	"concall-analyser/internal/db"
+	"concall-analyser/internal/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type documentRepository struct {
+	coll *mongo.Collection
+}
+
+// NewDocumentRepository creates a new MongoDB implementation of DocumentRepository
+func NewDocumentRepository(db *db.MongoDB) domain.DocumentRepository {
+	return &documentRepository{
+		coll: db.Collection("documents"),
+	}
+}
+
+func (r *documentRepository) EnsureIndexes(ctx context.Context) error {
+	model := mongo.IndexModel{
+		Keys:    bson.D{{Key: "news_ids", Value: 1}},
+		Options: options.Index().SetName("news_ids"),
+	}
+
+	if _, err := r.coll.Indexes().CreateOne(ctx, model); err != nil {
+		return fmt.Errorf("failed to create indexes: %w", err)
+	}
+	return nil
+}
+
+func (r *documentRepository) Upsert(ctx context.Context, doc *domain.Document) error {
+	setOnInsert := bson.M{
+		"backend":         doc.Backend,
+		"key":             doc.Key,
+		"size":            doc.Size,
+		"content_type":    doc.ContentType,
+		"attachment_name": doc.AttachmentName,
+		"created_at":      doc.CreatedAt,
+	}
+	update := bson.M{"$setOnInsert": setOnInsert}
+	if len(doc.NewsIDs) > 0 {
+		update["$addToSet"] = bson.M{"news_ids": bson.M{"$each": doc.NewsIDs}}
+	} else {
+		setOnInsert["news_ids"] = []string{}
+	}
+	opts := options.Update().SetUpsert(true)
+
+	if _, err := r.coll.UpdateOne(ctx, bson.M{"_id": doc.ID}, update, opts); err != nil {
+		return fmt.Errorf("failed to upsert document: %w", err)
+	}
+	return nil
+}
+
+func (r *documentRepository) FindByID(ctx context.Context, id string) (*domain.Document, error) {
+	return r.findOne(ctx, bson.M{"_id": id})
+}
+
+func (r *documentRepository) FindByNewsID(ctx context.Context, newsID string) (*domain.Document, error) {
+	return r.findOne(ctx, bson.M{"news_ids": newsID})
+}
+
+func (r *documentRepository) findOne(ctx context.Context, filter bson.M) (*domain.Document, error) {
+	var doc domain.Document
+	err := r.coll.FindOne(ctx, filter).Decode(&doc)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrDocumentNotFound
+		}
+		return nil, fmt.Errorf("failed to get document: %w", err)
+	}
+	return &doc, nil
+}
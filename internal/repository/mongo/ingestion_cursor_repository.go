@@ -0,0 +1,44 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+
+	"concall-analyser/internal/db"
+	"concall-analyser/internal/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ingestionCursorRepository struct {
+	coll *mongo.Collection
+}
+
+// NewIngestionCursorRepository creates a new MongoDB implementation of IngestionCursorRepository
+func NewIngestionCursorRepository(db *db.MongoDB) domain.IngestionCursorRepository {
+	return &ingestionCursorRepository{
+		coll: db.Collection("ingestion_cursors"),
+	}
+}
+
+func (r *ingestionCursorRepository) Find(ctx context.Context, source string) (*domain.SourceCursor, error) {
+	var cursor domain.SourceCursor
+	err := r.coll.FindOne(ctx, bson.M{"_id": source}).Decode(&cursor)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrCursorNotFound
+		}
+		return nil, fmt.Errorf("failed to get ingestion cursor: %w", err)
+	}
+	return &cursor, nil
+}
+
+func (r *ingestionCursorRepository) Save(ctx context.Context, cursor *domain.SourceCursor) error {
+	opts := options.Replace().SetUpsert(true)
+	if _, err := r.coll.ReplaceOne(ctx, bson.M{"_id": cursor.Source}, cursor, opts); err != nil {
+		return fmt.Errorf("failed to save ingestion cursor: %w", err)
+	}
+	return nil
+}
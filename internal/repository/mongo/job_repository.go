@@ -0,0 +1,151 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"concall-analyser/internal/db"
+	"concall-analyser/internal/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type jobRepository struct {
+	coll *mongo.Collection
+}
+
+// NewJobRepository creates a new MongoDB implementation of JobRepository
+func NewJobRepository(db *db.MongoDB) domain.JobRepository {
+	return &jobRepository{
+		coll: db.Collection("fetch_jobs"),
+	}
+}
+
+func (r *jobRepository) Create(ctx context.Context, job *domain.FetchJob) error {
+	if job.ID.IsZero() {
+		job.ID = primitive.NewObjectID()
+	}
+	if job.Items == nil {
+		job.Items = []domain.JobItem{}
+	}
+	if job.Errors == nil {
+		job.Errors = []string{}
+	}
+	if job.Summaries == nil {
+		job.Summaries = []domain.ConcallSummary{}
+	}
+
+	if _, err := r.coll.InsertOne(ctx, job); err != nil {
+		return fmt.Errorf("failed to insert job: %w", err)
+	}
+	return nil
+}
+
+func (r *jobRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*domain.FetchJob, error) {
+	var job domain.FetchJob
+	err := r.coll.FindOne(ctx, bson.M{"_id": id}).Decode(&job)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrJobNotFound
+		}
+		return nil, fmt.Errorf("failed to get job: %w", err)
+	}
+	return &job, nil
+}
+
+func (r *jobRepository) Update(ctx context.Context, id primitive.ObjectID, fields bson.M) error {
+	set := bson.M{"updated_at": time.Now()}
+	for k, v := range fields {
+		set[k] = v
+	}
+
+	if _, err := r.coll.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": set}); err != nil {
+		return fmt.Errorf("failed to update job: %w", err)
+	}
+	return nil
+}
+
+func (r *jobRepository) UpdateItem(ctx context.Context, id primitive.ObjectID, newsID string, status domain.JobItemStatus, errMsg string) error {
+	now := time.Now()
+	update := bson.M{
+		"$set": bson.M{
+			"items.$.status":     status,
+			"items.$.error":      errMsg,
+			"items.$.updated_at": now,
+			"updated_at":         now,
+		},
+	}
+
+	switch status {
+	case domain.ItemSucceeded:
+		update["$inc"] = bson.M{"progress.processed": 1, "progress.succeeded": 1}
+	case domain.ItemSkipped:
+		update["$inc"] = bson.M{"progress.processed": 1, "progress.skipped": 1}
+	case domain.ItemFailed:
+		update["$inc"] = bson.M{"progress.processed": 1, "progress.failed": 1}
+	}
+
+	filter := bson.M{"_id": id, "items.news_id": newsID}
+	if _, err := r.coll.UpdateOne(ctx, filter, update); err != nil {
+		return fmt.Errorf("failed to update job item: %w", err)
+	}
+	return nil
+}
+
+func (r *jobRepository) RequestCancel(ctx context.Context, id primitive.ObjectID) (bool, error) {
+	filter := bson.M{
+		"_id":   id,
+		"state": bson.M{"$in": []domain.JobState{domain.JobQueued, domain.JobRunning}},
+	}
+	update := bson.M{"$set": bson.M{"cancel_requested": true, "updated_at": time.Now()}}
+
+	result, err := r.coll.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, fmt.Errorf("failed to request job cancellation: %w", err)
+	}
+	return result.MatchedCount > 0, nil
+}
+
+func (r *jobRepository) AppendSummary(ctx context.Context, id primitive.ObjectID, summary domain.ConcallSummary) error {
+	update := bson.M{
+		"$push": bson.M{"summaries": summary},
+		"$set":  bson.M{"updated_at": time.Now()},
+	}
+	if _, err := r.coll.UpdateOne(ctx, bson.M{"_id": id}, update); err != nil {
+		return fmt.Errorf("failed to append summary to job: %w", err)
+	}
+	return nil
+}
+
+func (r *jobRepository) FindUnfinished(ctx context.Context) ([]domain.FetchJob, error) {
+	filter := bson.M{"state": bson.M{"$in": []domain.JobState{domain.JobQueued, domain.JobRunning}}}
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+
+	cursor, err := r.coll.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query unfinished jobs: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	jobs := []domain.FetchJob{}
+	if err := cursor.All(ctx, &jobs); err != nil {
+		return nil, fmt.Errorf("failed to decode jobs: %w", err)
+	}
+	return jobs, nil
+}
+
+func (r *jobRepository) MarkResumed(ctx context.Context, id primitive.ObjectID) error {
+	now := time.Now()
+	update := bson.M{
+		"$inc": bson.M{"resumes": 1},
+		"$set": bson.M{"resumed_at": now, "updated_at": now},
+	}
+	if _, err := r.coll.UpdateOne(ctx, bson.M{"_id": id}, update); err != nil {
+		return fmt.Errorf("failed to mark job resumed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"concall-analyser/internal/db"
+	"concall-analyser/internal/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ledgerRepository struct {
+	coll *mongo.Collection
+}
+
+// NewLedgerRepository creates a new MongoDB implementation of LedgerRepository
+func NewLedgerRepository(db *db.MongoDB) domain.LedgerRepository {
+	return &ledgerRepository{
+		coll: db.Collection("ingestion_ledger"),
+	}
+}
+
+func (r *ledgerRepository) EnsureIndexes(ctx context.Context) error {
+	models := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "state", Value: 1}, {Key: "next_retry_at", Value: 1}},
+			Options: options.Index().SetName("state_next_retry_at"),
+		},
+		{
+			Keys:    bson.D{{Key: "state", Value: 1}, {Key: "updated_at", Value: -1}},
+			Options: options.Index().SetName("state_updated_at"),
+		},
+	}
+
+	if _, err := r.coll.Indexes().CreateMany(ctx, models); err != nil {
+		return fmt.Errorf("failed to create indexes: %w", err)
+	}
+	return nil
+}
+
+func (r *ledgerRepository) Enqueue(ctx context.Context, jobID primitive.ObjectID, announcements []domain.Announcement) error {
+	now := time.Now()
+	models := make([]mongo.WriteModel, 0, len(announcements))
+	for _, a := range announcements {
+		if a.NewsID == "" {
+			continue
+		}
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": a.NewsID}).
+			SetUpdate(bson.M{
+				"$set": bson.M{
+					"scrip_code":   a.ScripCode,
+					"name":         strings.TrimSuffix(a.ShortLongName, "-$"),
+					"date":         strings.Split(a.NewsDate, "T")[0],
+					"announcement": a,
+					"state":        domain.LedgerQueued,
+					"job_id":       jobID,
+					"updated_at":   now,
+				},
+				"$unset":       bson.M{"next_retry_at": ""},
+				"$setOnInsert": bson.M{"attempts": 0, "created_at": now},
+			}).
+			SetUpsert(true))
+	}
+	if len(models) == 0 {
+		return nil
+	}
+
+	if _, err := r.coll.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false)); err != nil {
+		return fmt.Errorf("failed to enqueue ledger entries: %w", err)
+	}
+	return nil
+}
+
+func (r *ledgerRepository) Update(ctx context.Context, newsID string, fields bson.M) error {
+	set := bson.M{"updated_at": time.Now()}
+	for k, v := range fields {
+		set[k] = v
+	}
+
+	if _, err := r.coll.UpdateOne(ctx, bson.M{"_id": newsID}, bson.M{"$set": set}); err != nil {
+		return fmt.Errorf("failed to update ledger entry: %w", err)
+	}
+	return nil
+}
+
+func (r *ledgerRepository) RecordAttempt(ctx context.Context, newsID string, state domain.LedgerState, reason string) (*domain.LedgerEntry, error) {
+	update := bson.M{
+		"$set":   bson.M{"state": state, "reason": reason, "updated_at": time.Now()},
+		"$inc":   bson.M{"attempts": 1},
+		"$unset": bson.M{"next_retry_at": ""},
+	}
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var entry domain.LedgerEntry
+	if err := r.coll.FindOneAndUpdate(ctx, bson.M{"_id": newsID}, update, opts).Decode(&entry); err != nil {
+		return nil, fmt.Errorf("failed to record attempt: %w", err)
+	}
+	return &entry, nil
+}
+
+func (r *ledgerRepository) Find(ctx context.Context, filter bson.M, opts *options.FindOptions) ([]domain.LedgerEntry, error) {
+	cursor, err := r.coll.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query ledger: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	entries := []domain.LedgerEntry{}
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, fmt.Errorf("failed to decode ledger entries: %w", err)
+	}
+	return entries, nil
+}
+
+func (r *ledgerRepository) Count(ctx context.Context, filter bson.M) (int64, error) {
+	count, err := r.coll.CountDocuments(ctx, filter)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count ledger entries: %w", err)
+	}
+	return count, nil
+}
@@ -0,0 +1,100 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"concall-analyser/internal/db"
+	"concall-analyser/internal/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type lockRepository struct {
+	coll *mongo.Collection
+}
+
+// NewLockRepository creates a new MongoDB implementation of LockRepository
+func NewLockRepository(db *db.MongoDB) domain.LockRepository {
+	return &lockRepository{
+		coll: db.Collection("locks"),
+	}
+}
+
+func (r *lockRepository) EnsureIndexes(ctx context.Context) error {
+	// The TTL monitor only runs about once a minute, so it just tidies up; expiry
+	// itself is decided by comparing expires_at in TryAcquire
+	model := mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetName("expires_at_ttl").SetExpireAfterSeconds(0),
+	}
+
+	if _, err := r.coll.Indexes().CreateOne(ctx, model); err != nil {
+		return fmt.Errorf("failed to create indexes: %w", err)
+	}
+	return nil
+}
+
+func (r *lockRepository) TryAcquire(ctx context.Context, lease *domain.Lease) error {
+	// Matches only a missing or expired lease. When a live lease exists the upsert
+	// tries to insert a second document with the same _id and fails.
+	filter := bson.M{"_id": lease.Name, "expires_at": bson.M{"$lt": time.Now()}}
+	update := bson.M{"$set": bson.M{
+		"token":       lease.Token,
+		"holder":      lease.Holder,
+		"operation":   lease.Operation,
+		"job_id":      lease.JobID,
+		"acquired_at": lease.AcquiredAt,
+		"renewed_at":  lease.RenewedAt,
+		"expires_at":  lease.ExpiresAt,
+	}}
+
+	_, err := r.coll.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err == nil {
+		return nil
+	}
+	if !mongo.IsDuplicateKeyError(err) {
+		return fmt.Errorf("failed to acquire lock: %w", err)
+	}
+
+	var current domain.Lease
+	if err := r.coll.FindOne(ctx, bson.M{"_id": lease.Name}).Decode(&current); err != nil {
+		// Released between the two calls; report it as held and let the caller retry
+		return &domain.LockHeldError{}
+	}
+	return &domain.LockHeldError{Lease: &current}
+}
+
+func (r *lockRepository) Renew(ctx context.Context, name, token string, expiresAt time.Time) error {
+	update := bson.M{"$set": bson.M{"renewed_at": time.Now(), "expires_at": expiresAt}}
+	result, err := r.coll.UpdateOne(ctx, bson.M{"_id": name, "token": token}, update)
+	if err != nil {
+		return fmt.Errorf("failed to renew lock: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrLockLost
+	}
+	return nil
+}
+
+func (r *lockRepository) SetJob(ctx context.Context, name, token, jobID string) error {
+	update := bson.M{"$set": bson.M{"job_id": jobID}}
+	result, err := r.coll.UpdateOne(ctx, bson.M{"_id": name, "token": token}, update)
+	if err != nil {
+		return fmt.Errorf("failed to update lock: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrLockLost
+	}
+	return nil
+}
+
+func (r *lockRepository) Release(ctx context.Context, name, token string) error {
+	if _, err := r.coll.DeleteOne(ctx, bson.M{"_id": name, "token": token}); err != nil {
+		return fmt.Errorf("failed to release lock: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+
+	"concall-analyser/internal/db"
+	"concall-analyser/internal/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type summaryVersionRepository struct {
+	coll *mongo.Collection
+}
+
+// NewSummaryVersionRepository creates a new MongoDB implementation of SummaryVersionRepository
+func NewSummaryVersionRepository(db *db.MongoDB) domain.SummaryVersionRepository {
+	return &summaryVersionRepository{
+		coll: db.Collection("guidance_versions"),
+	}
+}
+
+func (r *summaryVersionRepository) EnsureIndexes(ctx context.Context) error {
+	model := mongo.IndexModel{
+		Keys:    bson.D{{Key: "news_id", Value: 1}, {Key: "superseded_at", Value: -1}},
+		Options: options.Index().SetName("news_id_superseded_at"),
+	}
+
+	if _, err := r.coll.Indexes().CreateOne(ctx, model); err != nil {
+		return fmt.Errorf("failed to create indexes: %w", err)
+	}
+	return nil
+}
+
+func (r *summaryVersionRepository) Insert(ctx context.Context, version *domain.SummaryVersion) error {
+	if version.ID.IsZero() {
+		version.ID = primitive.NewObjectID()
+	}
+
+	if _, err := r.coll.InsertOne(ctx, version); err != nil {
+		return fmt.Errorf("failed to insert summary version: %w", err)
+	}
+	return nil
+}
+
+func (r *summaryVersionRepository) FindByNewsID(ctx context.Context, newsID string) ([]domain.SummaryVersion, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "superseded_at", Value: -1}})
+	cursor, err := r.coll.Find(ctx, bson.M{"news_id": newsID}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query summary versions: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	versions := []domain.SummaryVersion{}
+	if err := cursor.All(ctx, &versions); err != nil {
+		return nil, fmt.Errorf("failed to decode summary versions: %w", err)
+	}
+	return versions, nil
+}
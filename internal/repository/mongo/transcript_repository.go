@@ -0,0 +1,46 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+
+	"concall-analyser/internal/db"
+	"concall-analyser/internal/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type transcriptRepository struct {
+	coll *mongo.Collection
+}
+
+// NewTranscriptRepository creates a new MongoDB implementation of TranscriptRepository
+func NewTranscriptRepository(db *db.MongoDB) domain.TranscriptRepository {
+	return &transcriptRepository{
+		coll: db.Collection("transcripts"),
+	}
+}
+
+func (r *transcriptRepository) Upsert(ctx context.Context, transcript *domain.Transcript) error {
+	filter := bson.M{"_id": transcript.NewsID}
+	opts := options.Replace().SetUpsert(true)
+
+	if _, err := r.coll.ReplaceOne(ctx, filter, transcript, opts); err != nil {
+		return fmt.Errorf("failed to upsert transcript: %w", err)
+	}
+	return nil
+}
+
+func (r *transcriptRepository) FindByNewsID(ctx context.Context, newsID string) (*domain.Transcript, error) {
+	var transcript domain.Transcript
+	err := r.coll.FindOne(ctx, bson.M{"_id": newsID}).Decode(&transcript)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrTranscriptNotFound
+		}
+		return nil, fmt.Errorf("failed to get transcript: %w", err)
+	}
+	return &transcript, nil
+}
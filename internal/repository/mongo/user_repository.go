@@ -0,0 +1,80 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"concall-analyser/internal/db"
+	"concall-analyser/internal/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type userRepository struct {
+	coll *mongo.Collection
+}
+
+// NewUserRepository creates a new MongoDB implementation of UserRepository
+func NewUserRepository(db *db.MongoDB) domain.UserRepository {
+	return &userRepository{
+		coll: db.Collection("users"),
+	}
+}
+
+func (r *userRepository) EnsureIndexes(ctx context.Context) error {
+	model := mongo.IndexModel{
+		Keys:    bson.D{{Key: "email", Value: 1}},
+		Options: options.Index().SetName("email_unique").SetUnique(true),
+	}
+
+	if _, err := r.coll.Indexes().CreateOne(ctx, model); err != nil {
+		return fmt.Errorf("failed to create indexes: %w", err)
+	}
+	return nil
+}
+
+func (r *userRepository) Create(ctx context.Context, user *domain.User) error {
+	if user.ID.IsZero() {
+		user.ID = primitive.NewObjectID()
+	}
+
+	if _, err := r.coll.InsertOne(ctx, user); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return domain.ErrUserExists
+		}
+		return fmt.Errorf("failed to insert user: %w", err)
+	}
+	return nil
+}
+
+func (r *userRepository) FindByEmail(ctx context.Context, email string) (*domain.User, error) {
+	var user domain.User
+	err := r.coll.FindOne(ctx, bson.M{"email": email}).Decode(&user)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, domain.ErrUserNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+	return &user, nil
+}
+
+func (r *userRepository) Count(ctx context.Context) (int64, error) {
+	count, err := r.coll.CountDocuments(ctx, bson.M{})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count users: %w", err)
+	}
+	return count, nil
+}
+
+func (r *userRepository) TouchLogin(ctx context.Context, id primitive.ObjectID, at time.Time) error {
+	if _, err := r.coll.UpdateByID(ctx, id, bson.M{"$set": bson.M{"last_login_at": at}}); err != nil {
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+	return nil
+}
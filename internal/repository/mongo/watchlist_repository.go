@@ -0,0 +1,185 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"concall-analyser/internal/db"
+	"concall-analyser/internal/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type watchlistRepository struct {
+	coll *mongo.Collection
+}
+
+// NewWatchlistRepository creates a new MongoDB implementation of WatchlistRepository
+func NewWatchlistRepository(db *db.MongoDB) domain.WatchlistRepository {
+	return &watchlistRepository{
+		coll: db.Collection("watchlists"),
+	}
+}
+
+func (r *watchlistRepository) EnsureIndexes(ctx context.Context) error {
+	models := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "owner", Value: 1}, {Key: "created_at", Value: 1}},
+			Options: options.Index().SetName("owner_created_at"),
+		},
+		{
+			Keys:    bson.D{{Key: "companies.scrip_code", Value: 1}},
+			Options: options.Index().SetName("companies_scrip_code"),
+		},
+	}
+
+	if _, err := r.coll.Indexes().CreateMany(ctx, models); err != nil {
+		return fmt.Errorf("failed to create indexes: %w", err)
+	}
+	return nil
+}
+
+func (r *watchlistRepository) Create(ctx context.Context, watchlist *domain.Watchlist) error {
+	if watchlist.ID.IsZero() {
+		watchlist.ID = primitive.NewObjectID()
+	}
+	if watchlist.Companies == nil {
+		watchlist.Companies = []domain.WatchedCompany{}
+	}
+
+	if _, err := r.coll.InsertOne(ctx, watchlist); err != nil {
+		return fmt.Errorf("failed to insert watchlist: %w", err)
+	}
+	return nil
+}
+
+func (r *watchlistRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*domain.Watchlist, error) {
+	var watchlist domain.Watchlist
+	err := r.coll.FindOne(ctx, bson.M{"_id": id}).Decode(&watchlist)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, domain.ErrWatchlistNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find watchlist: %w", err)
+	}
+	return &watchlist, nil
+}
+
+func (r *watchlistRepository) FindByOwner(ctx context.Context, owner string) ([]domain.Watchlist, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+	cursor, err := r.coll.Find(ctx, bson.M{"owner": owner}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query watchlists: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	watchlists := []domain.Watchlist{}
+	if err := cursor.All(ctx, &watchlists); err != nil {
+		return nil, fmt.Errorf("failed to decode watchlists: %w", err)
+	}
+	return watchlists, nil
+}
+
+func (r *watchlistRepository) Rename(ctx context.Context, id primitive.ObjectID, name string) error {
+	update := bson.M{"$set": bson.M{"name": name, "updated_at": time.Now()}}
+	result, err := r.coll.UpdateByID(ctx, id, update)
+	if err != nil {
+		return fmt.Errorf("failed to rename watchlist: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrWatchlistNotFound
+	}
+	return nil
+}
+
+func (r *watchlistRepository) AddCompany(ctx context.Context, id primitive.ObjectID, company domain.WatchedCompany) (*domain.Watchlist, error) {
+	// Only matches while the company is missing and the list has room, so
+	// concurrent adds cannot push it past the cap or add a company twice
+	filter := bson.M{
+		"_id":                  id,
+		"companies.scrip_code": bson.M{"$ne": company.ScripCode},
+		"companies." + strconv.Itoa(domain.MaxWatchlistCompanies-1): bson.M{"$exists": false},
+	}
+	update := bson.M{
+		"$push": bson.M{"companies": company},
+		"$set":  bson.M{"updated_at": time.Now()},
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var watchlist domain.Watchlist
+	err := r.coll.FindOneAndUpdate(ctx, filter, update, opts).Decode(&watchlist)
+	if err == nil {
+		return &watchlist, nil
+	}
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, fmt.Errorf("failed to add company to watchlist: %w", err)
+	}
+
+	current, err := r.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range current.Companies {
+		if c.ScripCode == company.ScripCode {
+			return current, nil
+		}
+	}
+	return nil, domain.ErrWatchlistFull
+}
+
+func (r *watchlistRepository) RemoveCompany(ctx context.Context, id primitive.ObjectID, scripCode int) (*domain.Watchlist, error) {
+	update := bson.M{
+		"$pull": bson.M{"companies": bson.M{"scrip_code": scripCode}},
+		"$set":  bson.M{"updated_at": time.Now()},
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var watchlist domain.Watchlist
+	err := r.coll.FindOneAndUpdate(ctx, bson.M{"_id": id}, update, opts).Decode(&watchlist)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, domain.ErrWatchlistNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to remove company from watchlist: %w", err)
+	}
+	return &watchlist, nil
+}
+
+func (r *watchlistRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	result, err := r.coll.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return fmt.Errorf("failed to delete watchlist: %w", err)
+	}
+	if result.DeletedCount == 0 {
+		return domain.ErrWatchlistNotFound
+	}
+	return nil
+}
+
+func (r *watchlistRepository) FindIDsByScripCode(ctx context.Context, scripCode int) ([]primitive.ObjectID, error) {
+	opts := options.Find().SetProjection(bson.M{"_id": 1})
+	cursor, err := r.coll.Find(ctx, bson.M{"companies.scrip_code": scripCode}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query watchlists: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var docs []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, fmt.Errorf("failed to decode watchlists: %w", err)
+	}
+
+	ids := make([]primitive.ObjectID, len(docs))
+	for i, doc := range docs {
+		ids[i] = doc.ID
+	}
+	return ids, nil
+}
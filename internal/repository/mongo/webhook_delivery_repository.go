@@ -0,0 +1,135 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"concall-analyser/internal/db"
+	"concall-analyser/internal/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// deliveryRetention is how long delivery logs are kept
+const deliveryRetention = 30 * 24 * time.Hour
+
+type webhookDeliveryRepository struct {
+	coll *mongo.Collection
+}
+
+// NewWebhookDeliveryRepository creates a new MongoDB implementation of WebhookDeliveryRepository
+func NewWebhookDeliveryRepository(db *db.MongoDB) domain.WebhookDeliveryRepository {
+	return &webhookDeliveryRepository{
+		coll: db.Collection("webhook_deliveries"),
+	}
+}
+
+func (r *webhookDeliveryRepository) EnsureIndexes(ctx context.Context) error {
+	models := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "webhook_id", Value: 1}, {Key: "created_at", Value: -1}},
+			Options: options.Index().SetName("webhook_id_created_at"),
+		},
+		{
+			Keys: bson.D{{Key: "next_attempt_at", Value: 1}},
+			Options: options.Index().
+				SetName("pending_next_attempt_at").
+				SetPartialFilterExpression(bson.M{"state": domain.DeliveryPending}),
+		},
+		{
+			Keys:    bson.D{{Key: "created_at", Value: 1}},
+			Options: options.Index().SetName("created_at_ttl").SetExpireAfterSeconds(int32(deliveryRetention.Seconds())),
+		},
+	}
+
+	if _, err := r.coll.Indexes().CreateMany(ctx, models); err != nil {
+		return fmt.Errorf("failed to create indexes: %w", err)
+	}
+	return nil
+}
+
+func (r *webhookDeliveryRepository) Create(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	if delivery.ID.IsZero() {
+		delivery.ID = primitive.NewObjectID()
+	}
+	if delivery.Attempts == nil {
+		delivery.Attempts = []domain.DeliveryAttempt{}
+	}
+
+	if _, err := r.coll.InsertOne(ctx, delivery); err != nil {
+		return fmt.Errorf("failed to insert webhook delivery: %w", err)
+	}
+	return nil
+}
+
+func (r *webhookDeliveryRepository) RecordAttempt(ctx context.Context, id primitive.ObjectID, attempt domain.DeliveryAttempt, state domain.DeliveryState, nextAttemptAt *time.Time) error {
+	set := bson.M{"state": state, "updated_at": time.Now()}
+	update := bson.M{
+		"$push": bson.M{"attempts": attempt},
+		"$set":  set,
+	}
+	if nextAttemptAt != nil {
+		set["next_attempt_at"] = *nextAttemptAt
+	} else {
+		update["$unset"] = bson.M{"next_attempt_at": ""}
+	}
+
+	result, err := r.coll.UpdateByID(ctx, id, update)
+	if err != nil {
+		return fmt.Errorf("failed to record webhook delivery attempt: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrDeliveryNotFound
+	}
+	return nil
+}
+
+func (r *webhookDeliveryRepository) ClaimDue(ctx context.Context, now, leaseUntil time.Time) (*domain.WebhookDelivery, error) {
+	filter := bson.M{
+		"state":           domain.DeliveryPending,
+		"next_attempt_at": bson.M{"$lte": now},
+	}
+	update := bson.M{"$set": bson.M{"next_attempt_at": leaseUntil}}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "next_attempt_at", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	var delivery domain.WebhookDelivery
+	err := r.coll.FindOneAndUpdate(ctx, filter, update, opts).Decode(&delivery)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, domain.ErrDeliveryNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim webhook delivery: %w", err)
+	}
+	return &delivery, nil
+}
+
+func (r *webhookDeliveryRepository) FindByWebhook(ctx context.Context, webhookID primitive.ObjectID, filter bson.M, opts *options.FindOptions) ([]domain.WebhookDelivery, error) {
+	filter["webhook_id"] = webhookID
+	cursor, err := r.coll.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhook deliveries: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	deliveries := []domain.WebhookDelivery{}
+	if err := cursor.All(ctx, &deliveries); err != nil {
+		return nil, fmt.Errorf("failed to decode webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+func (r *webhookDeliveryRepository) CountByWebhook(ctx context.Context, webhookID primitive.ObjectID, filter bson.M) (int64, error) {
+	filter["webhook_id"] = webhookID
+	count, err := r.coll.CountDocuments(ctx, filter)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count webhook deliveries: %w", err)
+	}
+	return count, nil
+}
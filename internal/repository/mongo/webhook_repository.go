@@ -0,0 +1,96 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"concall-analyser/internal/db"
+	"concall-analyser/internal/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type webhookRepository struct {
+	coll *mongo.Collection
+}
+
+// NewWebhookRepository creates a new MongoDB implementation of WebhookRepository
+func NewWebhookRepository(db *db.MongoDB) domain.WebhookRepository {
+	return &webhookRepository{
+		coll: db.Collection("webhooks"),
+	}
+}
+
+func (r *webhookRepository) Create(ctx context.Context, webhook *domain.Webhook) error {
+	if webhook.ID.IsZero() {
+		webhook.ID = primitive.NewObjectID()
+	}
+
+	if _, err := r.coll.InsertOne(ctx, webhook); err != nil {
+		return fmt.Errorf("failed to insert webhook: %w", err)
+	}
+	return nil
+}
+
+func (r *webhookRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*domain.Webhook, error) {
+	var webhook domain.Webhook
+	err := r.coll.FindOne(ctx, bson.M{"_id": id}).Decode(&webhook)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, domain.ErrWebhookNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find webhook: %w", err)
+	}
+	return &webhook, nil
+}
+
+func (r *webhookRepository) List(ctx context.Context) ([]domain.Webhook, error) {
+	return r.find(ctx, bson.M{})
+}
+
+func (r *webhookRepository) FindActive(ctx context.Context) ([]domain.Webhook, error) {
+	return r.find(ctx, bson.M{"active": true})
+}
+
+func (r *webhookRepository) find(ctx context.Context, filter bson.M) ([]domain.Webhook, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+	cursor, err := r.coll.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhooks: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	webhooks := []domain.Webhook{}
+	if err := cursor.All(ctx, &webhooks); err != nil {
+		return nil, fmt.Errorf("failed to decode webhooks: %w", err)
+	}
+	return webhooks, nil
+}
+
+func (r *webhookRepository) Update(ctx context.Context, id primitive.ObjectID, fields bson.M) error {
+	fields["updated_at"] = time.Now()
+	result, err := r.coll.UpdateByID(ctx, id, bson.M{"$set": fields})
+	if err != nil {
+		return fmt.Errorf("failed to update webhook: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrWebhookNotFound
+	}
+	return nil
+}
+
+func (r *webhookRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	result, err := r.coll.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+	if result.DeletedCount == 0 {
+		return domain.ErrWebhookNotFound
+	}
+	return nil
+}
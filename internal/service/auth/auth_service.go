@@ -0,0 +1,259 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"concall-analyser/internal/domain"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	issuer = "concall-analyser"
+	// apiKeyPrefix marks API keys so they are easy to spot in logs and secret scanners
+	apiKeyPrefix = "ca_"
+	// apiKeyShown is how much of a key is kept in clear to tell keys apart
+	apiKeyShown = 10
+	// minPasswordLength is the shortest password CreateUser accepts
+	minPasswordLength = 8
+	// touchInterval limits how often last_used_at is written for a busy key
+	touchInterval = time.Minute
+)
+
+var (
+	// ErrInvalidCredentials is returned for an unknown email, a wrong password or a bad token or key
+	ErrInvalidCredentials = errors.New("invalid credentials")
+
+	// dummyHash is compared against when the email is unknown, so both cases take as long
+	dummyHash, _ = bcrypt.GenerateFromPassword([]byte("not a password"), bcrypt.DefaultCost)
+)
+
+// Session is a signed JWT issued at login
+type Session struct {
+	Token     string       `json:"token"`
+	ExpiresAt time.Time    `json:"expires_at"`
+	User      *domain.User `json:"user"`
+}
+
+// AuthService logs users in and authenticates requests by session token or API key
+type AuthService interface {
+	// Login checks an email and password and issues a session, or returns ErrInvalidCredentials
+	Login(ctx context.Context, email, password string) (*Session, error)
+
+	// AuthenticateToken verifies a session token issued by Login
+	AuthenticateToken(ctx context.Context, token string) (*domain.Principal, error)
+
+	// AuthenticateAPIKey looks up an active API key
+	AuthenticateAPIKey(ctx context.Context, key string) (*domain.Principal, error)
+
+	// CreateUser stores a user with a bcrypt hash of the password
+	CreateUser(ctx context.Context, email, password string, role domain.Role) (*domain.User, error)
+
+	// EnsureAdmin creates an admin with the given credentials when there are no users yet
+	EnsureAdmin(ctx context.Context, email, password string) error
+
+	// CreateAPIKey generates a key and returns it in clear along with its stored record.
+	// The key cannot be recovered afterwards.
+	CreateAPIKey(ctx context.Context, name string, role domain.Role, createdBy string) (string, *domain.APIKey, error)
+
+	// ListAPIKeys returns every key, newest first
+	ListAPIKeys(ctx context.Context) ([]domain.APIKey, error)
+
+	// RevokeAPIKey stops a key from authenticating
+	RevokeAPIKey(ctx context.Context, id primitive.ObjectID) error
+}
+
+// claims are the contents of a session token
+type claims struct {
+	Role domain.Role `json:"role"`
+	jwt.RegisteredClaims
+}
+
+type authService struct {
+	users  domain.UserRepository
+	keys   domain.APIKeyRepository
+	secret []byte
+	ttl    time.Duration
+}
+
+// NewAuthService creates an AuthService signing sessions with secret (HS256) that
+// stay valid for ttl
+func NewAuthService(users domain.UserRepository, keys domain.APIKeyRepository, secret []byte, ttl time.Duration) AuthService {
+	return &authService{
+		users:  users,
+		keys:   keys,
+		secret: secret,
+		ttl:    ttl,
+	}
+}
+
+func (s *authService) Login(ctx context.Context, email, password string) (*Session, error) {
+	user, err := s.users.FindByEmail(ctx, normalizeEmail(email))
+	if errors.Is(err, domain.ErrUserNotFound) {
+		bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+		return nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(s.ttl)
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims{
+		Role: user.Role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    issuer,
+			Subject:   user.Email,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	})
+	signed, err := token.SignedString(s.secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign session: %w", err)
+	}
+
+	if err := s.users.TouchLogin(ctx, user.ID, now); err != nil {
+		log.Printf("⚠️ Failed to record login of %s: %v", user.Email, err)
+	}
+	user.LastLoginAt = &now
+
+	return &Session{Token: signed, ExpiresAt: expiresAt, User: user}, nil
+}
+
+func (s *authService) AuthenticateToken(ctx context.Context, token string) (*domain.Principal, error) {
+	var c claims
+	_, err := jwt.ParseWithClaims(token, &c, func(*jwt.Token) (interface{}, error) {
+		return s.secret, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(issuer),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, ErrInvalidCredentials
+	}
+	if _, err := domain.ParseRole(string(c.Role)); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	return &domain.Principal{Subject: c.Subject, Role: c.Role, Method: "session"}, nil
+}
+
+func (s *authService) AuthenticateAPIKey(ctx context.Context, key string) (*domain.Principal, error) {
+	if !strings.HasPrefix(key, apiKeyPrefix) {
+		return nil, ErrInvalidCredentials
+	}
+
+	record, err := s.keys.FindActiveByHash(ctx, hashKey(key))
+	if errors.Is(err, domain.ErrAPIKeyNotFound) {
+		return nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if record.LastUsedAt == nil || now.Sub(*record.LastUsedAt) > touchInterval {
+		if err := s.keys.TouchUsed(ctx, record.ID, now); err != nil {
+			log.Printf("⚠️ Failed to record use of API key %s: %v", record.Prefix, err)
+		}
+	}
+
+	return &domain.Principal{Subject: record.Name, Role: record.Role, Method: "api_key"}, nil
+}
+
+func (s *authService) CreateUser(ctx context.Context, email, password string, role domain.Role) (*domain.User, error) {
+	email = normalizeEmail(email)
+	if email == "" || !strings.Contains(email, "@") {
+		return nil, fmt.Errorf("invalid email %q", email)
+	}
+	if len(password) < minPasswordLength {
+		return nil, fmt.Errorf("password must be at least %d characters", minPasswordLength)
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	user := &domain.User{
+		Email:        email,
+		PasswordHash: string(hash),
+		Role:         role,
+		CreatedAt:    time.Now(),
+	}
+	if err := s.users.Create(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (s *authService) EnsureAdmin(ctx context.Context, email, password string) error {
+	count, err := s.users.Count(ctx)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	if _, err := s.CreateUser(ctx, email, password, domain.RoleAdmin); err != nil {
+		return fmt.Errorf("failed to create admin: %w", err)
+	}
+	log.Printf("👤 Created admin user %s", normalizeEmail(email))
+	return nil
+}
+
+func (s *authService) CreateAPIKey(ctx context.Context, name string, role domain.Role, createdBy string) (string, *domain.APIKey, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", nil, fmt.Errorf("failed to generate api key: %w", err)
+	}
+	key := apiKeyPrefix + hex.EncodeToString(secret)
+
+	record := &domain.APIKey{
+		Name:      name,
+		Prefix:    key[:apiKeyShown],
+		Hash:      hashKey(key),
+		Role:      role,
+		CreatedBy: createdBy,
+		CreatedAt: time.Now(),
+	}
+	if err := s.keys.Create(ctx, record); err != nil {
+		return "", nil, err
+	}
+	return key, record, nil
+}
+
+func (s *authService) ListAPIKeys(ctx context.Context) ([]domain.APIKey, error) {
+	return s.keys.List(ctx)
+}
+
+func (s *authService) RevokeAPIKey(ctx context.Context, id primitive.ObjectID) error {
+	return s.keys.Revoke(ctx, id, time.Now())
+}
+
+// hashKey is how API keys are stored and looked up. Keys are long and random,
+// so a fast unsalted hash is enough.
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
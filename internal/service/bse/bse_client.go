@@ -5,17 +5,45 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log"
+	"math/rand"
 	nethttp "net/http"
 	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"concall-analyser/internal/domain"
 	"concall-analyser/internal/infrastructure/http"
 )
 
+const (
+	announcementsURL = "https://api.bseindia.com/BseIndiaAPI/api/AnnSubCategoryGetData/w" +
+		"?strCat=Company+Update&strScrip=&strSearch=P&strType=C&subcategory=Earnings+Call+Transcript"
+	// companyHeaderURL returns a listed company's name, symbol and classification
+	companyHeaderURL = "https://api.bseindia.com/BseIndiaAPI/api/ComHeadernew/w?quotetype=EQ&seriesid="
+
+	// maxConcurrentPages bounds how many result pages are requested from BSE at once
+	maxConcurrentPages = 4
+	// maxPageRetries is the number of attempts made for a single page before giving up on it
+	maxPageRetries     = 3
+	pageRetryBaseDelay = 500 * time.Millisecond
+)
+
 // BSEClient defines the interface for BSE API operations
 type BSEClient interface {
-	FetchAnnouncements(ctx context.Context, fromDate, toDate time.Time) ([]domain.Announcement, error)
+	FetchAnnouncements(ctx context.Context, fromDate, toDate time.Time) (*FetchResult, error)
+	// FetchCompany returns the name, symbol, sector and industry BSE lists for a scrip code
+	FetchCompany(ctx context.Context, scripCode int) (*domain.Company, error)
+}
+
+// FetchResult holds every announcement found for a date range along with
+// how much of the result set was actually retrieved
+type FetchResult struct {
+	domain.FetchStats
+	Announcements []domain.Announcement `json:"-"`
 }
 
 type bseClient struct {
@@ -54,24 +82,140 @@ func parseHumanReadableDate(dateStr string) (time.Time, error) {
 	return time.Time{}, fmt.Errorf("unable to parse date '%s'. Supported formats: YYYY-MM-DD, DD-MM-YYYY, MM/DD/YYYY, DD/MM/YYYY, YYYYMMDD", dateStr)
 }
 
-func (c *bseClient) FetchAnnouncements(ctx context.Context, fromDate, toDate time.Time) ([]domain.Announcement, error) {
-	// Format dates as YYYYMMDD for the API
-	fromDateFormatted := fromDate.Format("20060102")
-	toDateFormatted := toDate.Format("20060102")
+// FetchAnnouncements walks every result page for the date range. The first page
+// tells us the total row count (Table1[].ROWCNT) and the page size; the remaining
+// pages are then fetched concurrently. A page that still fails after retries is
+// recorded in FailedPages rather than failing the whole fetch.
+func (c *bseClient) FetchAnnouncements(ctx context.Context, fromDate, toDate time.Time) (*FetchResult, error) {
+	first, err := c.fetchPageWithRetry(ctx, fromDate, toDate, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &FetchResult{
+		FetchStats: domain.FetchStats{
+			PagesFetched:  1,
+			PagesExpected: 1,
+			RowsExpected:  totalRows(first),
+		},
+	}
 
-	baseURL := "https://api.bseindia.com/BseIndiaAPI/api/AnnSubCategoryGetData/w" +
-		"?pageno=1&strCat=Company+Update&strPrevDate=20251018&strScrip=&strSearch=P" +
-		"&strToDate=20251018&strType=C&subcategory=Earnings+Call+Transcript"
+	pageSize := len(first.Table)
+	if pageSize > 0 && result.RowsExpected > pageSize {
+		result.PagesExpected = (result.RowsExpected + pageSize - 1) / pageSize
+	}
+	if result.RowsExpected == 0 {
+		result.RowsExpected = pageSize
+	}
+
+	pages := make([][]domain.Announcement, result.PagesExpected)
+	pages[0] = first.Table
+
+	if result.PagesExpected > 1 {
+		log.Printf("📑 BSE reports %d rows across %d pages (page size %d)", result.RowsExpected, result.PagesExpected, pageSize)
+
+		var (
+			wg  sync.WaitGroup
+			mu  sync.Mutex
+			sem = make(chan struct{}, maxConcurrentPages)
+		)
+
+		for page := 2; page <= result.PagesExpected; page++ {
+			wg.Add(1)
+			go func(page int) {
+				defer wg.Done()
+
+				select {
+				case sem <- struct{}{}:
+				case <-ctx.Done():
+					mu.Lock()
+					result.FailedPages = append(result.FailedPages, page)
+					mu.Unlock()
+					return
+				}
+				defer func() { <-sem }()
+
+				resp, err := c.fetchPageWithRetry(ctx, fromDate, toDate, page)
+
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil {
+					log.Printf("❌ Giving up on BSE page %d: %v", page, err)
+					result.FailedPages = append(result.FailedPages, page)
+					return
+				}
+				pages[page-1] = resp.Table
+				result.PagesFetched++
+			}(page)
+		}
+		wg.Wait()
+		sort.Ints(result.FailedPages)
+	}
+
+	// Rows can shift between pages while we walk them, so drop repeats by NewsID
+	seen := make(map[string]bool, result.RowsExpected)
+	for _, table := range pages {
+		for _, a := range table {
+			if a.NewsID != "" {
+				if seen[a.NewsID] {
+					continue
+				}
+				seen[a.NewsID] = true
+			}
+			result.Announcements = append(result.Announcements, a)
+		}
+	}
+	result.RowsSeen = len(result.Announcements)
+
+	if !result.Complete() {
+		log.Printf("⚠️ Incomplete BSE fetch: pages %d/%d, rows %d/%d, failed pages %v",
+			result.PagesFetched, result.PagesExpected, result.RowsSeen, result.RowsExpected, result.FailedPages)
+	}
+
+	return result, nil
+}
+
+func (c *bseClient) fetchPageWithRetry(ctx context.Context, fromDate, toDate time.Time, page int) (*domain.AnnouncementResponse, error) {
+	var lastErr error
+
+	for attempt := 0; attempt < maxPageRetries; attempt++ {
+		if attempt > 0 {
+			delay := pageRetryBaseDelay * time.Duration(1<<(attempt-1))
+			delay += time.Duration(rand.Int63n(int64(delay) / 5))
+			log.Printf("⚠️ Retrying BSE page %d in %v (Attempt %d/%d). Error: %v", page, delay, attempt+1, maxPageRetries, lastErr)
+
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(delay):
+			}
+		}
+
+		resp, err := c.fetchPage(ctx, fromDate, toDate, page)
+		if err == nil {
+			return resp, nil
+		}
+		lastErr = err
+
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+	}
+
+	return nil, fmt.Errorf("page %d failed after %d attempts: %w", page, maxPageRetries, lastErr)
+}
 
-	u, err := url.Parse(baseURL)
+func (c *bseClient) fetchPage(ctx context.Context, fromDate, toDate time.Time, page int) (*domain.AnnouncementResponse, error) {
+	u, err := url.Parse(announcementsURL)
 	if err != nil {
 		return nil, fmt.Errorf("invalid base URL: %w", err)
 	}
 
+	// Format dates as YYYYMMDD for the API
 	q := u.Query()
-	q.Set("strPrevDate", fromDateFormatted)
-	q.Set("strToDate", toDateFormatted)
-	q.Set("pageno", "1")
+	q.Set("strPrevDate", fromDate.Format("20060102"))
+	q.Set("strToDate", toDate.Format("20060102"))
+	q.Set("pageno", strconv.Itoa(page))
 	u.RawQuery = q.Encode()
 
 	req, err := nethttp.NewRequestWithContext(ctx, nethttp.MethodGet, u.String(), nil)
@@ -79,12 +223,7 @@ func (c *bseClient) FetchAnnouncements(ctx context.Context, fromDate, toDate tim
 		return nil, fmt.Errorf("create request: %w", err)
 	}
 
-	req.Header.Set("Accept", "application/json, text/plain, */*")
-	req.Header.Set("Referer", "https://www.bseindia.com/")
-	req.Header.Set("User-Agent", "Mozilla/5.0")
-	req.Header.Set("Sec-CH-UA", `"Google Chrome";v="141", "Not?A_Brand";v="8", "Chromium";v="141"`)
-	req.Header.Set("Sec-CH-UA-Mobile", "?0")
-	req.Header.Set("Sec-CH-UA-Platform", `"macOS"`)
+	setBrowserHeaders(req)
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
@@ -106,6 +245,75 @@ func (c *bseClient) FetchAnnouncements(ctx context.Context, fromDate, toDate tim
 		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
 	}
 
-	return ar.Table, nil
+	return &ar, nil
 }
 
+// companyHeader is the part of BSE's company header response that is kept
+type companyHeader struct {
+	CompanyName string `json:"CompanyName"`
+	SecurityID  string `json:"SecurityId"`
+	Sector      string `json:"Sector"`
+	Industry    string `json:"Industry"`
+	IndustryNew string `json:"IndustryNew"`
+}
+
+func (c *bseClient) FetchCompany(ctx context.Context, scripCode int) (*domain.Company, error) {
+	u := companyHeaderURL + "&scripcode=" + strconv.Itoa(scripCode)
+	req, err := nethttp.NewRequestWithContext(ctx, nethttp.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	setBrowserHeaders(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch company %d: %w", scripCode, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != nethttp.StatusOK {
+		return nil, fmt.Errorf("BSE API returned status %d", resp.StatusCode)
+	}
+
+	var header companyHeader
+	if err := json.NewDecoder(resp.Body).Decode(&header); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal company %d: %w", scripCode, err)
+	}
+	if header.CompanyName == "" && header.Sector == "" {
+		return nil, fmt.Errorf("BSE has no company with scrip code %d", scripCode)
+	}
+
+	industry := header.IndustryNew
+	if industry == "" {
+		industry = header.Industry
+	}
+	fetchedAt := time.Now()
+	return &domain.Company{
+		ScripCode:        scripCode,
+		Name:             strings.TrimSpace(header.CompanyName),
+		Symbol:           strings.TrimSpace(header.SecurityID),
+		Sector:           strings.TrimSpace(header.Sector),
+		Industry:         strings.TrimSpace(industry),
+		ProfileFetchedAt: &fetchedAt,
+	}, nil
+}
+
+// setBrowserHeaders makes a request look like it comes from the BSE website,
+// which the API requires
+func setBrowserHeaders(req *nethttp.Request) {
+	req.Header.Set("Accept", "application/json, text/plain, */*")
+	req.Header.Set("Referer", "https://www.bseindia.com/")
+	req.Header.Set("User-Agent", "Mozilla/5.0")
+	req.Header.Set("Sec-CH-UA", `"Google Chrome";v="141", "Not?A_Brand";v="8", "Chromium";v="141"`)
+	req.Header.Set("Sec-CH-UA-Mobile", "?0")
+	req.Header.Set("Sec-CH-UA-Platform", `"macOS"`)
+}
+
+func totalRows(ar *domain.AnnouncementResponse) int {
+	for _, t := range ar.Table1 {
+		if t.ROWCNT > 0 {
+			return t.ROWCNT
+		}
+	}
+	return 0
+}
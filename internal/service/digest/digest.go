@@ -0,0 +1,257 @@
+package digest
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"log"
+	"net/url"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"concall-analyser/internal/domain"
+	"concall-analyser/internal/service/scheduler"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+//go:embed templates/*
+var templateFS embed.FS
+
+var (
+	htmlTemplate = htmltemplate.Must(htmltemplate.ParseFS(templateFS, "templates/digest.html.tmpl"))
+	textTemplate = template.Must(template.ParseFS(templateFS, "templates/digest.txt.tmpl"))
+)
+
+// sendTimeout bounds the delivery of one digest email
+const sendTimeout = 30 * time.Second
+
+// Email is a rendered digest
+type Email struct {
+	Subject string `json:"subject"`
+	HTML    string `json:"html"`
+	Text    string `json:"text"`
+}
+
+// Service compiles the summaries stored by an ingestion run into an email
+// digest and sends it to every subscriber
+type Service interface {
+	// Enabled reports whether an SMTP server is configured to send digests through
+	Enabled() bool
+
+	// SendRun mails the summaries with guidance to every subscriber, limited to
+	// their watchlist when they chose one. Subscribers with nothing new get no mail.
+	SendRun(ctx context.Context, summaries []domain.ConcallSummary) error
+
+	// Compose renders the digest of the summaries with guidance, limited to the
+	// companies on watchlist when it is not nil
+	Compose(summaries []domain.ConcallSummary, watchlist *domain.Watchlist) (*Email, error)
+}
+
+// Options configure the content of digests
+type Options struct {
+	// SiteURL is where the links in a digest point, e.g. "https://concalls.example.com"
+	SiteURL string
+}
+
+type service struct {
+	subscriptions domain.DigestSubscriptionRepository
+	watchlists    domain.WatchlistRepository
+	mailer        Mailer
+	opts          Options
+}
+
+// NewService creates a digest service. A nil mailer disables sending, while
+// digests can still be composed for previews.
+func NewService(subscriptions domain.DigestSubscriptionRepository, watchlists domain.WatchlistRepository, mailer Mailer, opts Options) Service {
+	opts.SiteURL = strings.TrimSuffix(opts.SiteURL, "/")
+	return &service{
+		subscriptions: subscriptions,
+		watchlists:    watchlists,
+		mailer:        mailer,
+		opts:          opts,
+	}
+}
+
+func (s *service) Enabled() bool {
+	return s.mailer != nil
+}
+
+func (s *service) SendRun(ctx context.Context, summaries []domain.ConcallSummary) error {
+	if s.mailer == nil {
+		return nil
+	}
+	if len(withGuidance(summaries, nil)) == 0 {
+		return nil
+	}
+
+	subscriptions, err := s.subscriptions.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	sent := 0
+	watchlists := make(map[string]*domain.Watchlist)
+	for _, subscription := range subscriptions {
+		var watchlist *domain.Watchlist
+		if subscription.WatchlistID != "" {
+			watchlist, err = s.watchlist(ctx, watchlists, subscription.WatchlistID)
+			if err != nil {
+				log.Printf("⚠️ Skipping digest to %s: %v", subscription.Email, err)
+				continue
+			}
+		}
+		if len(withGuidance(summaries, watchlist)) == 0 {
+			continue
+		}
+
+		email, err := s.Compose(summaries, watchlist)
+		if err != nil {
+			return err
+		}
+		sendCtx, cancel := context.WithTimeout(ctx, sendTimeout)
+		err = s.mailer.Send(sendCtx, subscription.Email, email)
+		cancel()
+		if err != nil {
+			log.Printf("❌ Failed to send digest to %s: %v", subscription.Email, err)
+			continue
+		}
+		if err := s.subscriptions.TouchSent(ctx, subscription.ID, time.Now()); err != nil {
+			log.Printf("⚠️ Failed to record digest sent to %s: %v", subscription.Email, err)
+		}
+		sent++
+	}
+
+	log.Printf("📧 Sent %d digests of %d summaries", sent, len(summaries))
+	return nil
+}
+
+// watchlist loads a subscription's watchlist once per run
+func (s *service) watchlist(ctx context.Context, cache map[string]*domain.Watchlist, hexID string) (*domain.Watchlist, error) {
+	if watchlist, ok := cache[hexID]; ok {
+		return watchlist, nil
+	}
+	id, err := primitive.ObjectIDFromHex(hexID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid watchlist id %q", hexID)
+	}
+	watchlist, err := s.watchlists.FindByID(ctx, id)
+	if errors.Is(err, domain.ErrWatchlistNotFound) {
+		return nil, fmt.Errorf("watchlist %s no longer exists", hexID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	cache[hexID] = watchlist
+	return watchlist, nil
+}
+
+// digestData is what the templates render
+type digestData struct {
+	Title       string
+	Watchlist   string
+	GeneratedAt string
+	SiteURL     string
+	Concalls    []digestEntry
+}
+
+type digestEntry struct {
+	Name         string
+	ScripCode    int
+	Date         string
+	Guidance     string
+	ByFiscalYear []domain.FiscalYearGuidance
+	DocumentURL  string
+}
+
+func (s *service) Compose(summaries []domain.ConcallSummary, watchlist *domain.Watchlist) (*Email, error) {
+	concalls := withGuidance(summaries, watchlist)
+
+	data := digestData{
+		Title:       "New concall guidance",
+		GeneratedAt: time.Now().In(scheduler.IST).Format("2 Jan 2006, 15:04 IST"),
+		SiteURL:     s.opts.SiteURL,
+		Concalls:    make([]digestEntry, 0, len(concalls)),
+	}
+	if watchlist != nil {
+		data.Watchlist = watchlist.Name
+		data.Title = "New concall guidance for " + watchlist.Name
+	}
+	for _, concall := range concalls {
+		entry := digestEntry{
+			Name:      strings.TrimSuffix(concall.Name, "-$"),
+			ScripCode: concall.ScripCode,
+			Date:      concall.Date,
+			Guidance:  concall.Guidance,
+		}
+		for _, fy := range concall.GuidanceByFY {
+			if fy.Summary != "" && fy.Summary != "NA" {
+				entry.ByFiscalYear = append(entry.ByFiscalYear, fy)
+			}
+		}
+		if s.opts.SiteURL != "" && concall.DocumentID != "" {
+			entry.DocumentURL = s.opts.SiteURL + "/api/concalls/" + url.PathEscape(concall.NewsID) + "/document"
+		}
+		data.Concalls = append(data.Concalls, entry)
+	}
+
+	var html, text bytes.Buffer
+	if err := htmlTemplate.Execute(&html, data); err != nil {
+		return nil, fmt.Errorf("failed to render HTML digest: %w", err)
+	}
+	if err := textTemplate.Execute(&text, data); err != nil {
+		return nil, fmt.Errorf("failed to render text digest: %w", err)
+	}
+
+	return &Email{
+		Subject: subject(data),
+		HTML:    html.String(),
+		Text:    text.String(),
+	}, nil
+}
+
+func subject(data digestData) string {
+	updates := fmt.Sprintf("%d new guidance updates", len(data.Concalls))
+	if len(data.Concalls) == 1 {
+		updates = "1 new guidance update"
+	}
+	if data.Watchlist != "" {
+		return fmt.Sprintf("Concall digest for %s: %s", data.Watchlist, updates)
+	}
+	return "Concall digest: " + updates
+}
+
+// withGuidance keeps the summaries that have guidance and, when watchlist is
+// not nil, belong to a company on it, newest first and then by name
+func withGuidance(summaries []domain.ConcallSummary, watchlist *domain.Watchlist) []domain.ConcallSummary {
+	var watched map[int]bool
+	if watchlist != nil {
+		watched = make(map[int]bool, len(watchlist.Companies))
+		for _, code := range watchlist.ScripCodes() {
+			watched[code] = true
+		}
+	}
+
+	var result []domain.ConcallSummary
+	for _, summary := range summaries {
+		if summary.Guidance == "" || summary.Guidance == "NA" {
+			continue
+		}
+		if watched != nil && !watched[summary.ScripCode] {
+			continue
+		}
+		result = append(result, summary)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Date != result[j].Date {
+			return result[i].Date > result[j].Date
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
@@ -0,0 +1,32 @@
+package eventbus
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"concall-analyser/config"
+	"concall-analyser/internal/db"
+	ws "concall-analyser/internal/websocket"
+)
+
+// New creates the bus selected by EVENT_BUS. "auto" uses Mongo change streams
+// when the deployment supports them (a replica set) and otherwise falls back
+// to the in-memory bus, which only reaches clients of this replica.
+func New(ctx context.Context, cfg *config.Config, db *db.MongoDB) (ws.Bus, error) {
+	switch cfg.EventBus {
+	case "memory":
+		return ws.NewMemoryBus(), nil
+	case "mongo":
+		return NewMongoBus(ctx, db)
+	case "auto":
+		bus, err := NewMongoBus(ctx, db)
+		if err != nil {
+			log.Printf("⚠️ Mongo change streams unavailable, real-time updates only reach clients of this replica: %v", err)
+			return ws.NewMemoryBus(), nil
+		}
+		return bus, nil
+	default:
+		return nil, fmt.Errorf("unknown event bus %q (expected auto, mongo or memory)", cfg.EventBus)
+	}
+}
@@ -0,0 +1,251 @@
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"concall-analyser/internal/db"
+	"concall-analyser/internal/domain"
+	repository "concall-analyser/internal/repository/mongo"
+	ws "concall-analyser/internal/websocket"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	analyticsCollection = "analytics"
+	guidancesCollection = "guidances"
+	// eventsCollection carries the messages that no watched write produces, e.g. job progress
+	eventsCollection = "hub_events"
+	// eventsTTL is how long published messages are kept; they are only read as they are inserted
+	eventsTTL = time.Hour
+	// reopenDelay is how long the watcher waits before reopening a failed change stream
+	reopenDelay = 3 * time.Second
+)
+
+// sourced are the message types the bus derives from writes it watches, so
+// publishing them would deliver them twice
+var sourced = map[string]bool{
+	ws.MessageAnalyticsUpdate: true,
+	ws.EventConcallAdded:      true,
+}
+
+// busEvent is a published message as stored in hub_events
+type busEvent struct {
+	Type      string    `bson:"type"`
+	Topics    []string  `bson:"topics"`
+	Data      string    `bson:"data"`
+	Origin    string    `bson:"origin"`
+	CreatedAt time.Time `bson:"created_at"`
+}
+
+// changeEvent is the part of a change stream event the bus reads
+type changeEvent struct {
+	OperationType string `bson:"operationType"`
+	NS            struct {
+		Coll string `bson:"coll"`
+	} `bson:"ns"`
+	FullDocument bson.Raw `bson:"fullDocument"`
+}
+
+type mongoBus struct {
+	db         *db.MongoDB
+	events     *mongo.Collection
+	watchlists domain.WatchlistRepository
+	origin     string
+}
+
+// NewMongoBus creates a bus on a change stream over the analytics, guidances
+// and hub_events collections, so that every replica sees the visits counted
+// and concalls stored by any other. It fails when the deployment does not
+// support change streams, i.e. is not a replica set.
+func NewMongoBus(ctx context.Context, db *db.MongoDB) (ws.Bus, error) {
+	events := db.Collection(eventsCollection)
+	_, err := events.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "created_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(int32(eventsTTL.Seconds())),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to ensure %s indexes: %w", eventsCollection, err)
+	}
+
+	probe, err := db.Watch(ctx, watchPipeline())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open change stream: %w", err)
+	}
+	probe.Close(ctx)
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+
+	return &mongoBus{
+		db:         db,
+		events:     events,
+		watchlists: repository.NewWatchlistRepository(db),
+		origin:     fmt.Sprintf("%s/%d", hostname, os.Getpid()),
+	}, nil
+}
+
+func watchPipeline() mongo.Pipeline {
+	return mongo.Pipeline{{{Key: "$match", Value: bson.M{
+		"ns.coll":       bson.M{"$in": []string{analyticsCollection, guidancesCollection, eventsCollection}},
+		"operationType": bson.M{"$in": []string{"insert", "update", "replace"}},
+	}}}}
+}
+
+func (b *mongoBus) Publish(ctx context.Context, msg ws.Message) error {
+	if sourced[msg.Type] {
+		return nil
+	}
+
+	_, err := b.events.InsertOne(ctx, busEvent{
+		Type:      msg.Type,
+		Topics:    msg.Topics,
+		Data:      string(msg.Data),
+		Origin:    b.origin,
+		CreatedAt: msg.Timestamp,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to insert %s event: %w", msg.Type, err)
+	}
+	return nil
+}
+
+// Subscribe follows the change stream until ctx ends, reopening it after
+// errors and resuming where it left off when possible
+func (b *mongoBus) Subscribe(ctx context.Context, deliver func(ws.Message)) error {
+	var resumeToken bson.Raw
+	for {
+		err := b.watch(ctx, &resumeToken, deliver)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		log.Printf("⚠️ Change stream ended, reopening in %s: %v", reopenDelay, err)
+		select {
+		case <-time.After(reopenDelay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (b *mongoBus) watch(ctx context.Context, resumeToken *bson.Raw, deliver func(ws.Message)) error {
+	opts := options.ChangeStream().SetFullDocument(options.UpdateLookup)
+	if *resumeToken != nil {
+		opts.SetResumeAfter(*resumeToken)
+	}
+
+	stream, err := b.db.Watch(ctx, watchPipeline(), opts)
+	if err != nil {
+		// The token may have fallen off the oplog; start from now next time
+		*resumeToken = nil
+		return err
+	}
+	defer stream.Close(context.Background())
+
+	for stream.Next(ctx) {
+		*resumeToken = stream.ResumeToken()
+
+		var change changeEvent
+		if err := stream.Decode(&change); err != nil {
+			log.Printf("⚠️ Failed to decode change event: %v", err)
+			continue
+		}
+
+		msg, ok, err := b.translate(ctx, change)
+		if err != nil {
+			log.Printf("⚠️ Failed to translate %s change on %s: %v", change.OperationType, change.NS.Coll, err)
+			continue
+		}
+		if ok {
+			deliver(msg)
+		}
+	}
+	return stream.Err()
+}
+
+// translate turns a change into the hub message it stands for
+func (b *mongoBus) translate(ctx context.Context, change changeEvent) (ws.Message, bool, error) {
+	if change.FullDocument == nil {
+		// The document was deleted before the update could be looked up
+		return ws.Message{}, false, nil
+	}
+
+	switch change.NS.Coll {
+	case eventsCollection:
+		if change.OperationType != "insert" {
+			return ws.Message{}, false, nil
+		}
+		var event busEvent
+		if err := bson.Unmarshal(change.FullDocument, &event); err != nil {
+			return ws.Message{}, false, err
+		}
+		return ws.Message{
+			Type:      event.Type,
+			Topics:    event.Topics,
+			Data:      json.RawMessage(event.Data),
+			Timestamp: event.CreatedAt,
+		}, true, nil
+
+	case analyticsCollection:
+		var counter struct {
+			TotalVisits int64 `bson:"total_visits"`
+		}
+		if err := bson.Unmarshal(change.FullDocument, &counter); err != nil {
+			return ws.Message{}, false, err
+		}
+		return message([]string{ws.TopicAnalytics}, ws.MessageAnalyticsUpdate, ws.AnalyticsUpdate{TotalVisits: counter.TotalVisits})
+
+	case guidancesCollection:
+		// Updates are resumed runs and reprocessing, not new concalls
+		if change.OperationType != "insert" {
+			return ws.Message{}, false, nil
+		}
+		var summary domain.ConcallSummary
+		if err := bson.Unmarshal(change.FullDocument, &summary); err != nil {
+			return ws.Message{}, false, err
+		}
+		return message(b.ingestionTopics(ctx, summary.ScripCode), ws.EventConcallAdded, ws.ConcallAdded{Concall: summary.Lite()})
+	}
+
+	return ws.Message{}, false, nil
+}
+
+// ingestionTopics adds the topics of the watchlists following a company to its ingestion topics
+func (b *mongoBus) ingestionTopics(ctx context.Context, scripCode int) []string {
+	topics := ws.IngestionTopics(scripCode)
+	if scripCode <= 0 {
+		return topics
+	}
+
+	ids, err := b.watchlists.FindIDsByScripCode(ctx, scripCode)
+	if err != nil {
+		log.Printf("⚠️ Failed to find watchlists following %d: %v", scripCode, err)
+		return topics
+	}
+	for _, id := range ids {
+		topics = append(topics, ws.WatchlistTopic(id.Hex()))
+	}
+	return topics
+}
+
+func message(topics []string, messageType string, data interface{}) (ws.Message, bool, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return ws.Message{}, false, err
+	}
+	return ws.Message{Type: messageType, Topics: topics, Data: raw, Timestamp: time.Now()}, true, nil
+}
+
+func (b *mongoBus) Name() string {
+	return "mongo"
+}
@@ -8,86 +8,168 @@ import (
 	"strings"
 	"time"
 
+	"concall-analyser/internal/domain"
+	"concall-analyser/internal/service/llm"
+
 	"github.com/google/generative-ai-go/genai"
 	"google.golang.org/api/googleapi"
 	"google.golang.org/api/option"
 )
 
-// GeminiClient defines the interface for Gemini AI operations
-type GeminiClient interface {
-	SummarizePDF(ctx context.Context, pdfPath string) (string, error)
-	Close() error
-}
+// DefaultModel is used when no model is configured
+const DefaultModel = "gemini-2.5-flash"
 
 type geminiClient struct {
-	client *genai.Client
-	model  *genai.GenerativeModel
+	client    *genai.Client
+	model     *genai.GenerativeModel
+	modelName string
 }
 
-// NewGeminiClient creates a new Gemini AI client
-func NewGeminiClient(ctx context.Context, apiKey string) (GeminiClient, error) {
+// NewGeminiClient creates a Gemini backed summarizer
+func NewGeminiClient(ctx context.Context, apiKey, modelName string) (llm.Summarizer, error) {
 	genaiClient, err := genai.NewClient(ctx, option.WithAPIKey(apiKey))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Gemini client: %w", err)
 	}
 
-	model := genaiClient.GenerativeModel("gemini-2.5-flash")
+	if modelName == "" {
+		modelName = DefaultModel
+	}
+
+	model := genaiClient.GenerativeModel(modelName)
+	model.ResponseMIMEType = "application/json"
+	model.ResponseSchema = guidanceSchema()
+
 	return &geminiClient{
-		client: genaiClient,
-		model:  model,
+		client:    genaiClient,
+		model:     model,
+		modelName: modelName,
 	}, nil
 }
 
+// guidanceSchema describes the JSON object the model must answer with
+func guidanceSchema() *genai.Schema {
+	metrics := make([]string, 0, len(domain.GuidanceMetrics))
+	for _, m := range domain.GuidanceMetrics {
+		metrics = append(metrics, string(m))
+	}
+
+	return &genai.Schema{
+		Type: genai.TypeObject,
+		Properties: map[string]*genai.Schema{
+			"summary": {Type: genai.TypeString, Description: "One line guidance summary, or NA"},
+			"by_fiscal_year": {
+				Type: genai.TypeArray,
+				Items: &genai.Schema{
+					Type: genai.TypeObject,
+					Properties: map[string]*genai.Schema{
+						"fiscal_year": {Type: genai.TypeString},
+						"summary":     {Type: genai.TypeString},
+					},
+					Required: []string{"fiscal_year", "summary"},
+				},
+			},
+			"guidance": {
+				Type: genai.TypeArray,
+				Items: &genai.Schema{
+					Type: genai.TypeObject,
+					Properties: map[string]*genai.Schema{
+						"metric":      {Type: genai.TypeString, Format: "enum", Enum: metrics},
+						"fiscal_year": {Type: genai.TypeString},
+						"low":         {Type: genai.TypeNumber, Nullable: true},
+						"high":        {Type: genai.TypeNumber, Nullable: true},
+						"unit":        {Type: genai.TypeString},
+						"growth_pct":  {Type: genai.TypeNumber, Nullable: true},
+						"quote":       {Type: genai.TypeString},
+					},
+					Required: []string{"metric", "fiscal_year", "quote"},
+				},
+			},
+		},
+		Required: []string{"summary", "by_fiscal_year", "guidance"},
+	}
+}
+
+func (g *geminiClient) Provider() string {
+	return "gemini"
+}
+
+func (g *geminiClient) Model() string {
+	return g.modelName
+}
+
 func (g *geminiClient) Close() error {
 	return g.client.Close()
 }
 
-func (g *geminiClient) SummarizePDF(ctx context.Context, pdfPath string) (string, error) {
-	// Upload file
-	file, err := g.client.UploadFileFromPath(ctx, pdfPath, &genai.UploadFileOptions{
-		MIMEType: "application/pdf",
-	})
-	if err != nil {
-		return "", fmt.Errorf("failed to upload PDF: %w", err)
+// Summarize sends the transcript text when it is available and otherwise
+// uploads the PDF itself
+func (g *geminiClient) Summarize(ctx context.Context, req llm.Request) (*domain.GuidanceResult, error) {
+	var document genai.Part
+	if req.Text != "" {
+		document = genai.Text(req.Text)
+	} else {
+		// Upload file
+		file, err := g.client.UploadFileFromPath(ctx, req.PDFPath, &genai.UploadFileOptions{
+			MIMEType: "application/pdf",
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to upload PDF: %w", err)
+		}
+
+		fmt.Printf("✅ Uploaded file: %s (MIME: %s)\n", file.Name, file.MIMEType)
+
+		// Clean up uploaded file
+		defer func() {
+			if err := g.client.DeleteFile(context.Background(), file.Name); err != nil {
+				log.Printf("Warning: failed to delete uploaded file %s: %v", file.Name, err)
+			}
+		}()
+		document = genai.FileData{MIMEType: file.MIMEType, URI: file.URI}
 	}
 
-	fmt.Printf("✅ Uploaded file: %s (MIME: %s)\n", file.Name, file.MIMEType)
+	prompt := llm.BuildPrompt(req.FiscalYears)
 
-	prompt := `Go through the concall and identify if management has given any guidance for fy26 on the future growth of the company in terms of revenue, profit or eps. If yes, then quantify the guidance andjust return the fy26' guidance an nothing else. If no guidance is provided, then return "NA". Your responsse should be just 1 line providing the guidance for fy26' in numbers otherwise NA.`
+	var lastErr error
+	for attempt := 1; attempt <= llm.MaxParseAttempts; attempt++ {
+		resp, err := g.makeCallWithRetry(ctx, document, prompt)
+		if err != nil {
+			return nil, fmt.Errorf("Gemini generation failed: %w", err)
+		}
 
-	resp, err := g.makeCallWithRetry(ctx, file, prompt)
-	if err != nil {
-		return "", fmt.Errorf("Gemini generation failed: %w", err)
+		result, err := llm.ParseResponse(responseText(resp), req.FiscalYears)
+		if err == nil {
+			return result, nil
+		}
+
+		lastErr = err
+		log.Printf("⚠️ Invalid guidance response for %s (Attempt %d/%d): %v", req.PDFPath, attempt, llm.MaxParseAttempts, err)
 	}
 
-	if len(resp.Candidates) == 0 {
-		return "(no response)", nil
+	return nil, lastErr
+}
+
+// responseText concatenates the text parts of the first candidate
+func responseText(resp *genai.GenerateContentResponse) string {
+	if len(resp.Candidates) == 0 || resp.Candidates[0].Content == nil {
+		return ""
 	}
 
 	var output strings.Builder
-	for _, cand := range resp.Candidates {
-		for _, part := range cand.Content.Parts {
-			output.WriteString(fmt.Sprintln(part))
+	for _, part := range resp.Candidates[0].Content.Parts {
+		if text, ok := part.(genai.Text); ok {
+			output.WriteString(string(text))
 		}
 	}
-
-	// Clean up uploaded file
-	if err := g.client.DeleteFile(ctx, file.Name); err != nil {
-		log.Printf("Warning: failed to delete uploaded file %s: %v", file.Name, err)
-	}
-
-	return strings.TrimSpace(output.String()), nil
+	return output.String()
 }
 
-func (g *geminiClient) makeCallWithRetry(ctx context.Context, file *genai.File, prompt string) (*genai.GenerateContentResponse, error) {
+func (g *geminiClient) makeCallWithRetry(ctx context.Context, document genai.Part, prompt string) (*genai.GenerateContentResponse, error) {
 	const maxRetries = 5
 	baseDelay := 100 * time.Millisecond
 
 	for i := 0; i < maxRetries; i++ {
-		resp, err := g.model.GenerateContent(ctx,
-			genai.FileData{MIMEType: file.MIMEType, URI: file.URI},
-			genai.Text(prompt),
-		)
+		resp, err := g.model.GenerateContent(ctx, document, genai.Text(prompt))
 
 		if err == nil {
 			return resp, nil
@@ -0,0 +1,162 @@
+package llm
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"concall-analyser/internal/domain"
+)
+
+// ChunkPages groups whole pages into page-numbered chunks of at most maxChars,
+// splitting a single page only when it is larger than maxChars on its own
+func ChunkPages(pages []domain.TranscriptPage, maxChars int) []string {
+	if maxChars <= 0 {
+		t := domain.Transcript{Pages: pages}
+		return []string{t.Text()}
+	}
+
+	var chunks []string
+	var current strings.Builder
+
+	flush := func() {
+		if current.Len() > 0 {
+			chunks = append(chunks, current.String())
+			current.Reset()
+		}
+	}
+
+	for _, p := range pages {
+		header := fmt.Sprintf("--- Page %d ---\n", p.Number)
+		text := p.Text + "\n"
+
+		if current.Len()+len(header)+len(text) > maxChars {
+			flush()
+		}
+
+		for len(header)+len(text) > maxChars && text != "" {
+			// A limit smaller than the header still moves at least one rune forward
+			cut := maxChars - len(header)
+			if cut <= 0 {
+				cut = 1
+			}
+			if cut >= len(text) {
+				cut = len(text)
+			} else {
+				// Never split a multibyte rune, but never cut nothing either
+				for cut > 0 && !utf8.RuneStart(text[cut]) {
+					cut--
+				}
+				if cut == 0 {
+					_, cut = utf8.DecodeRuneInString(text)
+				}
+			}
+			chunks = append(chunks, header+text[:cut])
+			text = text[cut:]
+		}
+		if text == "" {
+			continue
+		}
+
+		current.WriteString(header)
+		current.WriteString(text)
+	}
+	flush()
+
+	return chunks
+}
+
+// MergeResults combines the results of summarizing the chunks of one transcript
+func MergeResults(results []*domain.GuidanceResult, fiscalYears []string) *domain.GuidanceResult {
+	if len(results) == 1 {
+		return results[0]
+	}
+
+	merged := &domain.GuidanceResult{}
+	seen := make(map[string]bool)
+	var summaries []string
+	byFY := make(map[string][]string)
+
+	for _, r := range results {
+		for _, item := range r.Items {
+			key := fmt.Sprintf("%s|%s|%v|%v|%v", item.Metric, item.FiscalYear, deref(item.Low), deref(item.High), deref(item.GrowthPct))
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			merged.Items = append(merged.Items, item)
+		}
+		if r.Summary != domain.NoGuidance {
+			summaries = append(summaries, r.Summary)
+		}
+		for _, fy := range r.ByFiscalYear {
+			if fy.Summary != domain.NoGuidance {
+				byFY[fy.FiscalYear] = append(byFY[fy.FiscalYear], fy.Summary)
+			}
+		}
+	}
+
+	merged.Summary = strings.Join(summaries, "; ")
+	for _, fy := range fiscalYears {
+		merged.ByFiscalYear = append(merged.ByFiscalYear, domain.FiscalYearGuidance{
+			FiscalYear: fy,
+			Summary:    strings.Join(byFY[fy], "; "),
+		})
+	}
+
+	merged.Normalize()
+	merged.RestrictTo(fiscalYears)
+
+	// Chunks can each hold numbers without any of them summarizing them
+	if merged.Summary == "" {
+		merged.Summary = describeItems(merged.Items)
+	}
+	for i, fy := range merged.ByFiscalYear {
+		if fy.Summary != domain.NoGuidance {
+			continue
+		}
+		var items []domain.GuidanceItem
+		for _, item := range merged.Items {
+			if item.FiscalYear == fy.FiscalYear {
+				items = append(items, item)
+			}
+		}
+		if len(items) > 0 {
+			merged.ByFiscalYear[i].Summary = describeItems(items)
+		}
+	}
+	return merged
+}
+
+// describeItems writes guidance items out as a summary, e.g. "FY26 revenue
+// 1200-1300 crore, 15% growth", or NA when there are none
+func describeItems(items []domain.GuidanceItem) string {
+	if len(items) == 0 {
+		return domain.NoGuidance
+	}
+
+	parts := make([]string, 0, len(items))
+	for _, item := range items {
+		var values []string
+		switch {
+		case item.Low != nil && item.High != nil && *item.Low != *item.High:
+			values = append(values, strings.TrimSpace(fmt.Sprintf("%g-%g %s", *item.Low, *item.High, item.Unit)))
+		case item.Low != nil:
+			values = append(values, strings.TrimSpace(fmt.Sprintf("%g %s", *item.Low, item.Unit)))
+		case item.High != nil:
+			values = append(values, strings.TrimSpace(fmt.Sprintf("up to %g %s", *item.High, item.Unit)))
+		}
+		if item.GrowthPct != nil {
+			values = append(values, fmt.Sprintf("%g%% growth", *item.GrowthPct))
+		}
+		parts = append(parts, fmt.Sprintf("%s %s %s", item.FiscalYear, item.Metric, strings.Join(values, ", ")))
+	}
+	return strings.Join(parts, "; ")
+}
+
+func deref(v *float64) interface{} {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
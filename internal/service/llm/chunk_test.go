@@ -0,0 +1,169 @@
+package llm
+
+import (
+	"testing"
+	"unicode/utf8"
+
+	"concall-analyser/internal/domain"
+)
+
+func float(v float64) *float64 {
+	return &v
+}
+
+func TestMergeResults(t *testing.T) {
+	fiscalYears := []string{"FY26", "FY27"}
+	revenue := domain.GuidanceItem{Metric: domain.MetricRevenue, FiscalYear: "FY26", GrowthPct: float(15), Quote: "15% growth"}
+	profit := domain.GuidanceItem{Metric: domain.MetricProfit, FiscalYear: "FY27", Low: float(100), High: float(120), Unit: "crore", Quote: "100 to 120 crore"}
+	na := func() *domain.GuidanceResult {
+		return &domain.GuidanceResult{Summary: domain.NoGuidance}
+	}
+
+	tests := []struct {
+		name    string
+		results []*domain.GuidanceResult
+		summary string
+		items   int
+		byFY    map[string]string
+	}{
+		{
+			name: "summaries are joined and items deduplicated",
+			results: []*domain.GuidanceResult{
+				{Summary: "Revenue up 15%", Items: []domain.GuidanceItem{revenue}, ByFiscalYear: []domain.FiscalYearGuidance{{FiscalYear: "FY26", Summary: "Revenue up 15%"}}},
+				{Summary: "Profit of 100-120 crore", Items: []domain.GuidanceItem{revenue, profit}},
+			},
+			summary: "Revenue up 15%; Profit of 100-120 crore",
+			items:   2,
+			byFY:    map[string]string{"FY26": "Revenue up 15%", "FY27": "FY27 profit 100-120 crore"},
+		},
+		{
+			name:    "every chunk without guidance",
+			results: []*domain.GuidanceResult{na(), na()},
+			summary: domain.NoGuidance,
+			byFY:    map[string]string{"FY26": domain.NoGuidance, "FY27": domain.NoGuidance},
+		},
+		{
+			name: "items under NA summaries",
+			results: []*domain.GuidanceResult{
+				{Summary: domain.NoGuidance, Items: []domain.GuidanceItem{revenue}},
+				{Summary: domain.NoGuidance, Items: []domain.GuidanceItem{profit}},
+			},
+			summary: "FY26 revenue 15% growth; FY27 profit 100-120 crore",
+			items:   2,
+			byFY:    map[string]string{"FY26": "FY26 revenue 15% growth", "FY27": "FY27 profit 100-120 crore"},
+		},
+		{
+			name: "items only for other years",
+			results: []*domain.GuidanceResult{
+				{Summary: "FY28 revenue", Items: []domain.GuidanceItem{{Metric: domain.MetricRevenue, FiscalYear: "FY28", GrowthPct: float(10), Quote: "10%"}}},
+				na(),
+			},
+			summary: domain.NoGuidance,
+			byFY:    map[string]string{"FY26": domain.NoGuidance, "FY27": domain.NoGuidance},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged := MergeResults(tt.results, fiscalYears)
+			if merged.Summary != tt.summary {
+				t.Errorf("Summary = %q, want %q", merged.Summary, tt.summary)
+			}
+			if len(merged.Items) != tt.items {
+				t.Errorf("len(Items) = %d, want %d", len(merged.Items), tt.items)
+			}
+			for _, fy := range merged.ByFiscalYear {
+				if want := tt.byFY[fy.FiscalYear]; fy.Summary != want {
+					t.Errorf("ByFiscalYear[%s] = %q, want %q", fy.FiscalYear, fy.Summary, want)
+				}
+			}
+			if len(merged.ByFiscalYear) != len(tt.byFY) {
+				t.Errorf("len(ByFiscalYear) = %d, want %d", len(merged.ByFiscalYear), len(tt.byFY))
+			}
+			if err := merged.Validate(); err != nil {
+				t.Errorf("merged result does not validate: %v", err)
+			}
+		})
+	}
+}
+
+func TestMergeResultsSingle(t *testing.T) {
+	result := &domain.GuidanceResult{Summary: "Revenue up 15%"}
+	if merged := MergeResults([]*domain.GuidanceResult{result}, []string{"FY26"}); merged != result {
+		t.Errorf("MergeResults() of one result = %+v, want it unchanged", merged)
+	}
+}
+
+func TestChunkPages(t *testing.T) {
+	page := func(number int, text string) domain.TranscriptPage {
+		return domain.TranscriptPage{Number: number, Text: text}
+	}
+	header := "--- Page 1 ---\n"
+
+	tests := []struct {
+		name     string
+		pages    []domain.TranscriptPage
+		maxChars int
+		want     []string
+	}{
+		{
+			name:     "no limit",
+			pages:    []domain.TranscriptPage{page(1, "a"), page(2, "b")},
+			maxChars: 0,
+			want:     []string{"--- Page 1 ---\na\n--- Page 2 ---\nb\n"},
+		},
+		{
+			name:     "pages exactly at the limit",
+			pages:    []domain.TranscriptPage{page(1, "aaaa"), page(2, "bbbb")},
+			maxChars: len(header) + len("aaaa\n"),
+			want:     []string{header + "aaaa\n", "--- Page 2 ---\nbbbb\n"},
+		},
+		{
+			name:     "pages sharing a chunk",
+			pages:    []domain.TranscriptPage{page(1, "aa"), page(2, "bb")},
+			maxChars: 2 * (len(header) + len("aa\n")),
+			want:     []string{header + "aa\n--- Page 2 ---\nbb\n"},
+		},
+		{
+			name:     "one oversized page",
+			pages:    []domain.TranscriptPage{page(1, "abcdefghij")},
+			maxChars: len(header) + 4,
+			want:     []string{header + "abcd", header + "efgh", header + "ij\n"},
+		},
+		{
+			name:     "multibyte runes at the cut",
+			pages:    []domain.TranscriptPage{page(1, "₹₹₹")},
+			maxChars: len(header) + 4,
+			want:     []string{header + "₹", header + "₹", header + "₹\n"},
+		},
+		{
+			name:     "limit below the header",
+			pages:    []domain.TranscriptPage{page(1, "ab")},
+			maxChars: 5,
+			want:     []string{header + "a", header + "b", header + "\n"},
+		},
+		{
+			name:     "multibyte runes with a limit below the header",
+			pages:    []domain.TranscriptPage{page(1, "₹")},
+			maxChars: 1,
+			want:     []string{header + "₹", header + "\n"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ChunkPages(tt.pages, tt.maxChars)
+			if len(got) != len(tt.want) {
+				t.Fatalf("ChunkPages() = %q, want %q", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("chunk %d = %q, want %q", i, got[i], tt.want[i])
+				}
+				if !utf8.ValidString(got[i]) {
+					t.Errorf("chunk %d splits a rune: %q", i, got[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"concall-analyser/internal/domain"
+)
+
+// PromptVersion identifies the prompt built by BuildPrompt and is recorded on every
+// summary. Bump it whenever the prompt or the response shape changes.
+const PromptVersion = "v2"
+
+// LegacyPromptVersion stands for summaries written before prompt versions were recorded
+const LegacyPromptVersion = "v1"
+
+// MaxParseAttempts is how many times a response that fails validation is regenerated
+const MaxParseAttempts = 3
+
+// ErrTextRequired is returned by providers that cannot read PDFs when a request carries no extracted text
+var ErrTextRequired = errors.New("provider requires extracted transcript text")
+
+// Request is everything a summarizer needs to extract guidance from one transcript
+type Request struct {
+	// PDFPath points at the downloaded transcript, for providers that accept documents
+	PDFPath string
+	// Text is the transcript text; providers prefer it over the PDF when set
+	Text        string
+	FiscalYears []string
+}
+
+// Summarizer turns a transcript into structured guidance, independent of the model behind it
+type Summarizer interface {
+	Summarize(ctx context.Context, req Request) (*domain.GuidanceResult, error)
+	// Provider names the backend, e.g. "gemini" or "openai"
+	Provider() string
+	// Model names the model used by the backend
+	Model() string
+	Close() error
+}
+
+// BuildPrompt asks for guidance on each of the target fiscal years
+func BuildPrompt(fiscalYears []string) string {
+	years := strings.Join(fiscalYears, ", ")
+
+	return fmt.Sprintf(`Go through the concall and identify if management has given any guidance for %[1]s on the future growth of the company in terms of revenue, profit or eps.
+For every quantified guidance return one entry in "guidance" with:
+- metric: one of "revenue", "profit" or "eps"
+- fiscal_year: the fiscal year the guidance is for, one of %[1]s (Indian fiscal years run April to March, FY26 ends in March 2026)
+- low and high: the guided range in absolute numbers (use the same number for both if a single figure was given, null if only growth was guided)
+- unit: the unit of low/high, e.g. "INR crore", "INR million", "INR per share"
+- growth_pct: the guided growth in percent, null if not stated
+- quote: the sentence from the transcript that states the guidance, verbatim
+Return "by_fiscal_year" with one entry per fiscal year in %[1]s, whose "summary" is one line stating that year's guidance in numbers, or "NA" if none was given.
+Also return "summary": one line stating all of the guidance above in numbers. If no guidance is provided for any of these years, return "NA" as the summary and an empty "guidance" list.
+Respond with a single JSON object of the form:
+{"summary": string, "by_fiscal_year": [{"fiscal_year": string, "summary": string}], "guidance": [{"metric": string, "fiscal_year": string, "low": number|null, "high": number|null, "unit": string, "growth_pct": number|null, "quote": string}]}`, years)
+}
+
+// ParseResponse decodes and validates the JSON answer of a model, keeping only
+// guidance for the requested fiscal years
+func ParseResponse(raw string, fiscalYears []string) (*domain.GuidanceResult, error) {
+	raw = strings.TrimSpace(raw)
+	// Models without a native JSON mode sometimes wrap the object in a code fence
+	raw = strings.TrimPrefix(raw, "```json")
+	raw = strings.TrimPrefix(raw, "```")
+	raw = strings.TrimSuffix(raw, "```")
+	raw = strings.TrimSpace(raw)
+
+	var result domain.GuidanceResult
+	if err := json.Unmarshal([]byte(raw), &result); err != nil {
+		return nil, fmt.Errorf("%w: %v (response: %.200q)", domain.ErrMalformedGuidance, err, raw)
+	}
+
+	result.Normalize()
+	if err := result.Validate(); err != nil {
+		return nil, err
+	}
+	result.RestrictTo(fiscalYears)
+
+	return &result, nil
+}
@@ -0,0 +1,88 @@
+package llm
+
+import (
+	"errors"
+	"testing"
+
+	"concall-analyser/internal/domain"
+)
+
+func TestParseResponse(t *testing.T) {
+	fiscalYears := []string{"FY26", "FY27"}
+
+	tests := []struct {
+		name      string
+		raw       string
+		wantErr   bool
+		summary   string
+		items     int
+		byFYCount int
+	}{
+		{
+			name:      "guidance",
+			raw:       `{"summary": "Revenue growth of 15% in FY26", "guidance": [{"metric": "Revenue", "fiscal_year": "fy26", "growth_pct": 15, "quote": "we expect 15% growth"}]}`,
+			summary:   "Revenue growth of 15% in FY26",
+			items:     1,
+			byFYCount: 2,
+		},
+		{
+			name:      "fiscal years spelled out",
+			raw:       `{"summary": "Revenue growth of 15% in FY26 and profit of 120 crore in FY27", "by_fiscal_year": [{"fiscal_year": "2025-26", "summary": "Revenue growth of 15%"}, {"fiscal_year": "FY 27", "summary": "Profit of 120 crore"}], "guidance": [{"metric": "revenue", "fiscal_year": "FY2026", "growth_pct": 15, "quote": "15% growth"}, {"metric": "profit", "fiscal_year": "2026-27", "low": 120, "unit": "crore", "quote": "120 crore"}]}`,
+			summary:   "Revenue growth of 15% in FY26 and profit of 120 crore in FY27",
+			items:     2,
+			byFYCount: 2,
+		},
+		{
+			name:      "code fence",
+			raw:       "```json\n{\"summary\": \"NA\", \"guidance\": []}\n```",
+			summary:   domain.NoGuidance,
+			byFYCount: 2,
+		},
+		{
+			name:      "empty summary without items",
+			raw:       `{"summary": "", "guidance": []}`,
+			summary:   domain.NoGuidance,
+			byFYCount: 2,
+		},
+		{
+			name:      "items outside the requested years",
+			raw:       `{"summary": "FY28 revenue of 100 crore", "guidance": [{"metric": "revenue", "fiscal_year": "FY28", "low": 100, "unit": "crore", "quote": "100 crore in FY28"}]}`,
+			summary:   domain.NoGuidance,
+			byFYCount: 2,
+		},
+		{
+			name:    "not json",
+			raw:     "There is no guidance in this transcript.",
+			wantErr: true,
+		},
+		{
+			name:    "invalid item",
+			raw:     `{"summary": "Margins to improve", "guidance": [{"metric": "margin", "fiscal_year": "FY26", "growth_pct": 2, "quote": "margins up"}]}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParseResponse(tt.raw, fiscalYears)
+			if tt.wantErr {
+				if !errors.Is(err, domain.ErrMalformedGuidance) {
+					t.Fatalf("ParseResponse() error = %v, want ErrMalformedGuidance", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseResponse() error = %v", err)
+			}
+			if result.Summary != tt.summary {
+				t.Errorf("Summary = %q, want %q", result.Summary, tt.summary)
+			}
+			if len(result.Items) != tt.items {
+				t.Errorf("len(Items) = %d, want %d", len(result.Items), tt.items)
+			}
+			if len(result.ByFiscalYear) != tt.byFYCount {
+				t.Errorf("len(ByFiscalYear) = %d, want %d", len(result.ByFiscalYear), tt.byFYCount)
+			}
+		})
+	}
+}
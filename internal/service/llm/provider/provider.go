@@ -0,0 +1,24 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"concall-analyser/config"
+	"concall-analyser/internal/infrastructure/http"
+	"concall-analyser/internal/service/gemini"
+	"concall-analyser/internal/service/llm"
+	"concall-analyser/internal/service/openai"
+)
+
+// New creates the summarizer selected by LLM_PROVIDER
+func New(ctx context.Context, cfg *config.Config, httpClient http.Client) (llm.Summarizer, error) {
+	switch cfg.LLMProvider {
+	case "gemini":
+		return gemini.NewGeminiClient(ctx, cfg.LLMAPIKey, cfg.LLMModel)
+	case "openai":
+		return openai.NewOpenAIClient(httpClient, cfg.LLMBaseURL, cfg.LLMAPIKey, cfg.LLMModel)
+	default:
+		return nil, fmt.Errorf("unknown LLM provider %q", cfg.LLMProvider)
+	}
+}
@@ -0,0 +1,181 @@
+package lock
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"concall-analyser/internal/domain"
+)
+
+// Locker hands out lease locks shared by every replica
+type Locker interface {
+	// Acquire takes the named lock for an operation, returning a *domain.LockHeldError
+	// if another run holds it. The lease is renewed in the background until released.
+	Acquire(ctx context.Context, name, operation string) (Handle, error)
+}
+
+// Handle is a held lock
+type Handle interface {
+	Lease() domain.Lease
+	// SetJob records the job the lock is guarding, so a 409 can point at it
+	SetJob(ctx context.Context, jobID string) error
+	// Lost is closed when the lease could not be renewed and may now be held by someone else
+	Lost() <-chan struct{}
+	// Release stops the heartbeat and frees the lock; calling it more than once is harmless
+	Release()
+}
+
+type locker struct {
+	repo   domain.LockRepository
+	ttl    time.Duration
+	holder string
+}
+
+// NewLocker creates a Locker whose leases expire after ttl unless renewed.
+// Heartbeats renew them every ttl/3.
+func NewLocker(repo domain.LockRepository, ttl time.Duration) Locker {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+
+	return &locker{
+		repo:   repo,
+		ttl:    ttl,
+		holder: fmt.Sprintf("%s/%d", hostname, os.Getpid()),
+	}
+}
+
+func (l *locker) Acquire(ctx context.Context, name, operation string) (Handle, error) {
+	token, err := newToken()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	lease := domain.Lease{
+		Name:       name,
+		Token:      token,
+		Holder:     l.holder,
+		Operation:  operation,
+		AcquiredAt: now,
+		RenewedAt:  now,
+		ExpiresAt:  now.Add(l.ttl),
+	}
+	if err := l.repo.TryAcquire(ctx, &lease); err != nil {
+		return nil, err
+	}
+	log.Printf("🔒 Acquired %s lock for %s", name, operation)
+
+	h := &handle{
+		locker: l,
+		lease:  lease,
+		lost:   make(chan struct{}),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go h.heartbeat()
+
+	return h, nil
+}
+
+type handle struct {
+	locker *locker
+
+	// mu guards lease, which SetJob and the heartbeat update while callers read it
+	mu    sync.Mutex
+	lease domain.Lease
+
+	lost     chan struct{}
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+func (h *handle) Lease() domain.Lease {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.lease
+}
+
+func (h *handle) SetJob(ctx context.Context, jobID string) error {
+	h.mu.Lock()
+	h.lease.JobID = jobID
+	name, token := h.lease.Name, h.lease.Token
+	h.mu.Unlock()
+	return h.locker.repo.SetJob(ctx, name, token, jobID)
+}
+
+func (h *handle) Lost() <-chan struct{} {
+	return h.lost
+}
+
+func (h *handle) heartbeat() {
+	defer close(h.done)
+
+	ticker := time.NewTicker(h.locker.ttl / 3)
+	defer ticker.Stop()
+
+	// Name, token and operation never change once the lock is held
+	lease := h.Lease()
+	expiresAt := lease.ExpiresAt
+
+	for {
+		select {
+		case <-h.stop:
+			return
+		case <-ticker.C:
+			next := time.Now().Add(h.locker.ttl)
+			ctx, cancel := context.WithTimeout(context.Background(), h.locker.ttl/3)
+			err := h.locker.repo.Renew(ctx, lease.Name, lease.Token, next)
+			cancel()
+
+			switch {
+			case err == nil:
+				expiresAt = next
+				h.mu.Lock()
+				h.lease.RenewedAt = time.Now()
+				h.lease.ExpiresAt = next
+				h.mu.Unlock()
+			case errors.Is(err, domain.ErrLockLost) || time.Now().After(expiresAt):
+				log.Printf("❌ Lost %s lock held for %s: %v", lease.Name, lease.Operation, err)
+				close(h.lost)
+				return
+			default:
+				// Keep trying; the lease is only lost once it has actually expired
+				log.Printf("⚠️ Failed to renew %s lock: %v", lease.Name, err)
+			}
+		}
+	}
+}
+
+func (h *handle) Release() {
+	h.stopOnce.Do(func() {
+		close(h.stop)
+		<-h.done
+
+		lease := h.Lease()
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := h.locker.repo.Release(ctx, lease.Name, lease.Token); err != nil {
+			log.Printf("⚠️ Failed to release %s lock: %v", lease.Name, err)
+			return
+		}
+		log.Printf("🔓 Released %s lock held for %s", lease.Name, lease.Operation)
+	})
+}
+
+func newToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate lock token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,188 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"concall-analyser/internal/domain"
+)
+
+// memoryRepo is a LockRepository kept in memory, with the expiry semantics of
+// the Mongo one. Renewals can be made to fail to simulate a stalled holder.
+type memoryRepo struct {
+	mu        sync.Mutex
+	leases    map[string]domain.Lease
+	renewals  int
+	failRenew bool
+}
+
+func newMemoryRepo() *memoryRepo {
+	return &memoryRepo{leases: make(map[string]domain.Lease)}
+}
+
+func (r *memoryRepo) EnsureIndexes(ctx context.Context) error { return nil }
+
+func (r *memoryRepo) TryAcquire(ctx context.Context, lease *domain.Lease) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if current, ok := r.leases[lease.Name]; ok && !current.ExpiresAt.Before(time.Now()) {
+		return &domain.LockHeldError{Lease: &current}
+	}
+	r.leases[lease.Name] = *lease
+	return nil
+}
+
+func (r *memoryRepo) Renew(ctx context.Context, name, token string, expiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.failRenew {
+		return errors.New("database unavailable")
+	}
+	current, ok := r.leases[name]
+	if !ok || current.Token != token {
+		return domain.ErrLockLost
+	}
+	current.RenewedAt = time.Now()
+	current.ExpiresAt = expiresAt
+	r.leases[name] = current
+	r.renewals++
+	return nil
+}
+
+func (r *memoryRepo) SetJob(ctx context.Context, name, token, jobID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if current, ok := r.leases[name]; ok && current.Token == token {
+		current.JobID = jobID
+		r.leases[name] = current
+	}
+	return nil
+}
+
+func (r *memoryRepo) Release(ctx context.Context, name, token string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if current, ok := r.leases[name]; ok && current.Token == token {
+		delete(r.leases, name)
+	}
+	return nil
+}
+
+func (r *memoryRepo) lease(name string) domain.Lease {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.leases[name]
+}
+
+func (r *memoryRepo) setFailRenew(fail bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.failRenew = fail
+}
+
+const testTTL = 60 * time.Millisecond
+
+func TestHeartbeatExtendsLease(t *testing.T) {
+	repo := newMemoryRepo()
+	locker := NewLocker(repo, testTTL)
+
+	held, err := locker.Acquire(context.Background(), "ingestion", "fetch")
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	defer held.Release()
+	firstExpiry := held.Lease().ExpiresAt
+
+	// Well past the original expiry, heartbeats keep the lock held
+	time.Sleep(3 * testTTL)
+
+	select {
+	case <-held.Lost():
+		t.Fatal("lease was lost while heartbeats were succeeding")
+	default:
+	}
+	if got := held.Lease().ExpiresAt; !got.After(firstExpiry) {
+		t.Errorf("Lease().ExpiresAt = %v, want after %v", got, firstExpiry)
+	}
+	if stored := repo.lease("ingestion"); !stored.ExpiresAt.After(time.Now()) {
+		t.Errorf("stored lease expires at %v, want in the future", stored.ExpiresAt)
+	}
+
+	_, err = NewLocker(repo, testTTL).Acquire(context.Background(), "ingestion", "cleanup")
+	var heldErr *domain.LockHeldError
+	if !errors.As(err, &heldErr) {
+		t.Fatalf("second Acquire() error = %v, want *LockHeldError", err)
+	}
+	if heldErr.Lease.Operation != "fetch" {
+		t.Errorf("LockHeldError operation = %q, want fetch", heldErr.Lease.Operation)
+	}
+}
+
+func TestExpiredLeaseIsTakenOver(t *testing.T) {
+	repo := newMemoryRepo()
+
+	stalled, err := NewLocker(repo, testTTL).Acquire(context.Background(), "ingestion", "fetch")
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	defer stalled.Release()
+	repo.setFailRenew(true)
+
+	select {
+	case <-stalled.Lost():
+	case <-time.After(5 * testTTL):
+		t.Fatal("Lost() was not closed after the lease expired")
+	}
+	repo.setFailRenew(false)
+
+	taker, err := NewLocker(repo, testTTL).Acquire(context.Background(), "ingestion", "reprocess")
+	if err != nil {
+		t.Fatalf("Acquire() after expiry error = %v", err)
+	}
+	defer taker.Release()
+
+	if stored := repo.lease("ingestion"); stored.Token != taker.Lease().Token || stored.Operation != "reprocess" {
+		t.Errorf("stored lease = %+v, want the new holder's", stored)
+	}
+
+	// The stalled holder releasing late must not free the new holder's lock
+	stalled.Release()
+	if stored := repo.lease("ingestion"); stored.Token != taker.Lease().Token {
+		t.Error("releasing an expired handle removed the new holder's lease")
+	}
+}
+
+func TestSetJobWhileRenewing(t *testing.T) {
+	repo := newMemoryRepo()
+	held, err := NewLocker(repo, 3*time.Millisecond).Acquire(context.Background(), "ingestion", "fetch")
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	defer held.Release()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				if err := held.SetJob(context.Background(), "job"); err != nil {
+					t.Errorf("SetJob() error = %v", err)
+					return
+				}
+				_ = held.Lease()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := held.Lease().JobID; got != "job" {
+		t.Errorf("Lease().JobID = %q, want job", got)
+	}
+	if got := repo.lease("ingestion").JobID; got != "job" {
+		t.Errorf("stored JobID = %q, want job", got)
+	}
+}
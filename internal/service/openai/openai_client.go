@@ -0,0 +1,200 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	nethttp "net/http"
+	"strings"
+	"time"
+
+	"concall-analyser/internal/domain"
+	"concall-analyser/internal/infrastructure/http"
+	"concall-analyser/internal/service/llm"
+)
+
+// DefaultBaseURL points at a local Ollama server's OpenAI-compatible API
+const DefaultBaseURL = "http://localhost:11434/v1"
+
+const systemPrompt = "You are a financial analyst extracting management guidance from Indian earnings call transcripts. Answer only with JSON."
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatRequest struct {
+	Model          string            `json:"model"`
+	Messages       []chatMessage     `json:"messages"`
+	Temperature    float64           `json:"temperature"`
+	ResponseFormat map[string]string `json:"response_format,omitempty"`
+}
+
+type chatResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+}
+
+// apiError is a non-2xx answer from the endpoint
+type apiError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("chat completion returned status %d: %s", e.StatusCode, e.Body)
+}
+
+type openAIClient struct {
+	httpClient http.Client
+	baseURL    string
+	apiKey     string
+	model      string
+}
+
+// NewOpenAIClient creates a summarizer for any OpenAI-compatible chat completions
+// endpoint, such as OpenAI itself, llama.cpp's server or Ollama. These endpoints
+// cannot read PDFs, so requests must carry extracted transcript text.
+func NewOpenAIClient(httpClient http.Client, baseURL, apiKey, model string) (llm.Summarizer, error) {
+	if model == "" {
+		return nil, fmt.Errorf("a model name is required for the OpenAI-compatible provider")
+	}
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+
+	return &openAIClient{
+		httpClient: httpClient,
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		apiKey:     apiKey,
+		model:      model,
+	}, nil
+}
+
+func (o *openAIClient) Provider() string {
+	return "openai"
+}
+
+func (o *openAIClient) Model() string {
+	return o.model
+}
+
+func (o *openAIClient) Close() error {
+	return nil
+}
+
+func (o *openAIClient) Summarize(ctx context.Context, req llm.Request) (*domain.GuidanceResult, error) {
+	if req.Text == "" {
+		return nil, llm.ErrTextRequired
+	}
+
+	body := chatRequest{
+		Model: o.model,
+		Messages: []chatMessage{
+			{Role: "system", Content: systemPrompt},
+			{Role: "user", Content: llm.BuildPrompt(req.FiscalYears) + "\n\nTranscript:\n" + req.Text},
+		},
+		ResponseFormat: map[string]string{"type": "json_object"},
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= llm.MaxParseAttempts; attempt++ {
+		content, err := o.makeCallWithRetry(ctx, body)
+		if err != nil {
+			return nil, fmt.Errorf("chat completion failed: %w", err)
+		}
+
+		result, err := llm.ParseResponse(content, req.FiscalYears)
+		if err == nil {
+			return result, nil
+		}
+
+		lastErr = err
+		log.Printf("⚠️ Invalid guidance response from %s (Attempt %d/%d): %v", o.model, attempt, llm.MaxParseAttempts, err)
+	}
+
+	return nil, lastErr
+}
+
+func (o *openAIClient) makeCallWithRetry(ctx context.Context, body chatRequest) (string, error) {
+	const maxRetries = 5
+	baseDelay := 500 * time.Millisecond
+
+	for i := 0; i < maxRetries; i++ {
+		content, err := o.complete(ctx, body)
+		if err == nil {
+			return content, nil
+		}
+
+		if !isRetriableError(err) {
+			return "", err
+		}
+
+		delay := baseDelay * time.Duration(1<<i)
+		jitter := time.Duration(rand.Int63n(int64(delay) / 5))
+		sleepTime := delay + jitter
+
+		log.Printf("⚠️ Rate limit or transient error detected. Retrying in %v (Attempt %d/%d). Error: %v", sleepTime, i+1, maxRetries, err)
+
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(sleepTime):
+		}
+	}
+
+	return "", fmt.Errorf("chat completion failed after %d retries due to rate limits/transient errors", maxRetries)
+}
+
+func (o *openAIClient) complete(ctx context.Context, body chatRequest) (string, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	req, err := nethttp.NewRequestWithContext(ctx, nethttp.MethodPost, o.baseURL+"/chat/completions", bytes.NewReader(payload))
+	if err != nil {
+		return "", fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if o.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+o.apiKey)
+	}
+
+	resp, err := o.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to call chat completions: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode != nethttp.StatusOK {
+		return "", &apiError{StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(respBody))}
+	}
+
+	var cr chatResponse
+	if err := json.Unmarshal(respBody, &cr); err != nil {
+		return "", fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	if len(cr.Choices) == 0 {
+		return "", fmt.Errorf("%w: no choices in response", domain.ErrMalformedGuidance)
+	}
+
+	return cr.Choices[0].Message.Content, nil
+}
+
+func isRetriableError(err error) bool {
+	if apiErr, ok := err.(*apiError); ok {
+		return apiErr.StatusCode == 429 || apiErr.StatusCode == 500 || apiErr.StatusCode == 502 || apiErr.StatusCode == 503
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+package pdf
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"concall-analyser/internal/domain"
+
+	ledongthuc "github.com/ledongthuc/pdf"
+)
+
+// minCharsPerPage is the average amount of text per page below which a PDF is
+// treated as scanned; real transcripts carry a few thousand characters a page
+const minCharsPerPage = 100
+
+// TextExtractor defines the interface for extracting text from PDFs
+type TextExtractor interface {
+	// Extract returns the page-numbered text of the PDF. Scanned or empty PDFs
+	// are returned with Scanned set rather than as an error.
+	Extract(pdfPath string) (*domain.Transcript, error)
+}
+
+type textExtractor struct{}
+
+// NewTextExtractor creates a new PDF text extractor
+func NewTextExtractor() TextExtractor {
+	return &textExtractor{}
+}
+
+func (e *textExtractor) Extract(pdfPath string) (transcript *domain.Transcript, err error) {
+	// The PDF library reports most malformed input by panicking
+	defer func() {
+		if r := recover(); r != nil {
+			transcript, err = nil, fmt.Errorf("malformed PDF %s: %v", pdfPath, r)
+		}
+	}()
+
+	f, reader, err := ledongthuc.Open(pdfPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open PDF %s: %w", pdfPath, err)
+	}
+	defer f.Close()
+
+	numPages := reader.NumPage()
+	transcript = &domain.Transcript{
+		Pages:       make([]domain.TranscriptPage, 0, numPages),
+		PageCount:   numPages,
+		ExtractedAt: time.Now(),
+	}
+
+	fonts := make(map[string]*ledongthuc.Font)
+	for i := 1; i <= numPages; i++ {
+		page := reader.Page(i)
+		if page.V.IsNull() {
+			continue
+		}
+		for _, name := range page.Fonts() {
+			if _, ok := fonts[name]; !ok {
+				font := page.Font(name)
+				fonts[name] = &font
+			}
+		}
+
+		text, err := page.GetPlainText(fonts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to extract text from page %d of %s: %w", i, pdfPath, err)
+		}
+
+		text = normalizeWhitespace(text)
+		if text == "" {
+			continue
+		}
+		transcript.Pages = append(transcript.Pages, domain.TranscriptPage{Number: i, Text: text})
+		transcript.CharCount += len(text)
+	}
+
+	transcript.Scanned = numPages == 0 || transcript.CharCount < minCharsPerPage*numPages
+	return transcript, nil
+}
+
+// normalizeWhitespace collapses runs of spaces and blank lines left behind by PDF layout
+func normalizeWhitespace(text string) string {
+	lines := strings.Split(text, "\n")
+	out := make([]string, 0, len(lines))
+	for _, line := range lines {
+		line = strings.Join(strings.Fields(line), " ")
+		if line != "" {
+			out = append(out, line)
+		}
+	}
+	return strings.Join(out, "\n")
+}
@@ -0,0 +1,94 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// buildPDF returns a one-page PDF showing text, with a correct cross-reference table
+func buildPDF(text string) []byte {
+	content := fmt.Sprintf("BT /F1 12 Tf 72 720 Td (%s) Tj ET", text)
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R /Resources << /Font << /F1 5 0 R >> >> >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return buf.Bytes()
+}
+
+func writeTemp(t *testing.T, data []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "transcript.pdf")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestExtract(t *testing.T) {
+	text := strings.Repeat("Revenue grew fifteen percent ", 5)
+	transcript, err := NewTextExtractor().Extract(writeTemp(t, buildPDF(text)))
+	if err != nil {
+		t.Fatalf("Extract() error = %v", err)
+	}
+	if transcript.PageCount != 1 || len(transcript.Pages) != 1 {
+		t.Fatalf("Extract() = %d pages, %d with text, want 1", transcript.PageCount, len(transcript.Pages))
+	}
+	if got := transcript.Pages[0].Text; !strings.Contains(got, "Revenue grew fifteen percent") {
+		t.Errorf("page text = %q", got)
+	}
+}
+
+func TestExtractMalformed(t *testing.T) {
+	valid := buildPDF("Revenue grew fifteen percent")
+	xref := bytes.Index(valid, []byte("xref"))
+
+	tests := []struct {
+		name string
+		data []byte
+		// panics is set for input the PDF library panics on rather than rejecting
+		panics bool
+	}{
+		{name: "truncated", data: valid[:len(valid)/2]},
+		// A download cut short but ending in a valid trailer, whose offsets point past the data
+		{name: "truncated body", data: append(append([]byte(nil), valid[:xref/2]...), valid[xref:]...), panics: true},
+		{name: "wrong object offset", data: bytes.Replace(valid, []byte("0000000009 00000 n"), []byte("0000000100 00000 n"), 1), panics: true},
+		{name: "empty", data: []byte{}},
+		{name: "not a PDF", data: []byte("<html>Service Unavailable</html>")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transcript, err := NewTextExtractor().Extract(writeTemp(t, tt.data))
+			if err == nil {
+				t.Fatalf("Extract() = %+v, want an error", transcript)
+			}
+			if transcript != nil {
+				t.Errorf("Extract() returned a transcript with error %v", err)
+			}
+			if recovered := strings.HasPrefix(err.Error(), "malformed PDF"); recovered != tt.panics {
+				t.Errorf("Extract() error = %v, recovered from a panic: %v, want %v", err, recovered, tt.panics)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"concall-analyser/internal/domain"
+	"concall-analyser/internal/interfaces"
+)
+
+// resumeTimeout bounds each attempt to resume an interrupted job
+const resumeTimeout = 30 * time.Second
+
+// ResumeInterrupted resumes a job that a previous process left unfinished. After
+// a crash the dead process's ingestion lease stays live for up to lockTTL, so
+// while the lock is held, or the attempt fails, it tries again every quarter of
+// lockTTL until lockTTL has passed. It returns once a job was resumed, none was
+// waiting or it gave up; the retrier, when enabled, keeps checking after that.
+func ResumeInterrupted(ctx context.Context, ingestor interfaces.Ingestor, lockTTL time.Duration) {
+	interval := lockTTL / 4
+	deadline := time.Now().Add(lockTTL + interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		attemptCtx, cancel := context.WithTimeout(ctx, resumeTimeout)
+		_, err := ingestor.ResumeInterruptedJobs(attemptCtx)
+		cancel()
+		if err == nil {
+			return
+		}
+
+		var held *domain.LockHeldError
+		if !time.Now().Before(deadline) {
+			if errors.As(err, &held) {
+				log.Printf("⏭️ Gave up resuming interrupted jobs at startup, %v", held)
+			} else {
+				log.Printf("⚠️ Gave up resuming interrupted jobs at startup: %v", err)
+			}
+			return
+		}
+		if !errors.As(err, &held) {
+			log.Printf("⚠️ Failed to resume interrupted jobs, trying again in %v: %v", interval, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
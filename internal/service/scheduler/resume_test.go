@@ -0,0 +1,103 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"concall-analyser/internal/domain"
+)
+
+// resumingIngestor answers ResumeInterruptedJobs with errs in turn, then resumes a job
+type resumingIngestor struct {
+	mu    sync.Mutex
+	errs  []error
+	calls int
+}
+
+func (i *resumingIngestor) RunFetch(ctx context.Context, from, to time.Time) (*domain.FetchJob, error) {
+	return nil, nil
+}
+
+func (i *resumingIngestor) RetryDueFailures(ctx context.Context) (*domain.FetchJob, error) {
+	return nil, nil
+}
+
+func (i *resumingIngestor) ResumeInterruptedJobs(ctx context.Context) (*domain.FetchJob, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.calls++
+	if len(i.errs) > 0 {
+		err := i.errs[0]
+		if len(i.errs) > 1 {
+			i.errs = i.errs[1:]
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &domain.FetchJob{}, nil
+}
+
+func (i *resumingIngestor) SyncCompanies(ctx context.Context) error { return nil }
+
+func TestResumeInterrupted(t *testing.T) {
+	const lockTTL = 40 * time.Millisecond
+	held := &domain.LockHeldError{}
+
+	tests := []struct {
+		name      string
+		errs      []error
+		wantCalls int
+		// gaveUp is set when the lock is never released
+		gaveUp bool
+	}{
+		{name: "lock free", errs: nil, wantCalls: 1},
+		{name: "lease of the crashed process expires", errs: []error{held, held, nil}, wantCalls: 3},
+		{name: "database error", errs: []error{errors.New("server selection timeout"), nil}, wantCalls: 2},
+		// Tries at 0, 1/4, 2/4, 3/4, 4/4 and 5/4 of the lock TTL
+		{name: "lock held by a live run", errs: []error{held}, wantCalls: 6, gaveUp: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ingestor := &resumingIngestor{errs: tt.errs}
+			started := time.Now()
+			ResumeInterrupted(context.Background(), ingestor, lockTTL)
+			elapsed := time.Since(started)
+
+			if tt.gaveUp {
+				if ingestor.calls < tt.wantCalls-1 || ingestor.calls > tt.wantCalls {
+					t.Errorf("ResumeInterruptedJobs called %d times, want about %d", ingestor.calls, tt.wantCalls)
+				}
+				if elapsed < lockTTL {
+					t.Errorf("gave up after %v, before the lock TTL of %v", elapsed, lockTTL)
+				}
+				return
+			}
+			if ingestor.calls != tt.wantCalls {
+				t.Errorf("ResumeInterruptedJobs called %d times, want %d", ingestor.calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestResumeInterruptedStopsWithContext(t *testing.T) {
+	ingestor := &resumingIngestor{errs: []error{&domain.LockHeldError{}}}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan struct{})
+	go func() {
+		ResumeInterrupted(ctx, ingestor, time.Hour)
+		close(done)
+	}()
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("ResumeInterrupted did not return once its context was cancelled")
+	}
+}
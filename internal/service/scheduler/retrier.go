@@ -0,0 +1,92 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"concall-analyser/internal/domain"
+	"concall-analyser/internal/interfaces"
+)
+
+type retrier struct {
+	ingestor interfaces.Ingestor
+	interval time.Duration
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewRetrier creates a background loop that, every interval, resumes jobs left
+// unfinished by a process that went away and retries failed announcements from
+// the ingestion ledger once their backoff has elapsed
+func NewRetrier(interval time.Duration, ingestor interfaces.Ingestor) Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &retrier{
+		ingestor: ingestor,
+		interval: interval,
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+func (r *retrier) Start() {
+	r.wg.Add(1)
+	go r.loop()
+	log.Printf("🔁 Failure retrier started, checking every %v", r.interval)
+}
+
+func (r *retrier) Stop() {
+	r.cancel()
+	r.wg.Wait()
+	log.Println("✅ Failure retrier stopped")
+}
+
+func (r *retrier) loop() {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.ctx.Done():
+			return
+		case <-ticker.C:
+			r.run()
+		}
+	}
+}
+
+func (r *retrier) run() {
+	ctx, cancel := context.WithTimeout(r.ctx, runTimeout)
+	defer cancel()
+
+	// A resumed job holds the ingestion lock, so retries wait for the next round
+	resumed, err := r.ingestor.ResumeInterruptedJobs(ctx)
+	var held *domain.LockHeldError
+	if errors.As(err, &held) {
+		log.Printf("⏭️ Resume and failure retry deferred, %v", held)
+		return
+	}
+	if err != nil {
+		log.Printf("❌ Failed to resume interrupted jobs: %v", err)
+	}
+	if resumed != nil {
+		return
+	}
+
+	job, err := r.ingestor.RetryDueFailures(ctx)
+	switch {
+	case errors.As(err, &held):
+		log.Printf("⏭️ Failure retry deferred, %v", held)
+	case err != nil:
+		log.Printf("❌ Failure retry failed to run: %v", err)
+	case job != nil:
+		log.Printf("🔁 Retry job %s finished with state %s (%d/%d succeeded)",
+			job.ID.Hex(), job.State, job.Progress.Succeeded, job.Progress.Total)
+	}
+}
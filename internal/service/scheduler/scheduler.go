@@ -0,0 +1,149 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"concall-analyser/internal/domain"
+	"concall-analyser/internal/interfaces"
+
+	"github.com/robfig/cron/v3"
+)
+
+// IST is the zone schedules are written in. India has no daylight saving, so a
+// fixed offset is exact and does not depend on tzdata being installed.
+var IST = time.FixedZone("IST", 5*3600+30*60)
+
+// runTimeout bounds a single scheduled run, including waiting for its job
+const runTimeout = 2 * time.Hour
+
+// Scheduler runs ingestion work periodically in the background
+type Scheduler interface {
+	Start()
+	// Stop stops scheduling new runs, cancelling a run that is in progress
+	Stop()
+}
+
+type scheduler struct {
+	cron *cron.Cron
+	// ctx is cancelled by Stop so that a running fetch is cancelled rather than awaited
+	ctx          context.Context
+	cancel       context.CancelFunc
+	ingestor     interfaces.Ingestor
+	cursorRepo   domain.IngestionCursorRepository
+	lookbackDays int
+}
+
+// NewScheduler creates a scheduler that runs a fetch at every time matched by the
+// cron spec (interpreted in IST). Each run covers the days since the source's
+// high-water mark, limited to the last lookbackDays days. A run that is due while
+// the previous one is still going is skipped.
+func NewScheduler(spec string, lookbackDays int, ingestor interfaces.Ingestor, cursorRepo domain.IngestionCursorRepository) (Scheduler, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &scheduler{
+		ctx:          ctx,
+		cancel:       cancel,
+		ingestor:     ingestor,
+		cursorRepo:   cursorRepo,
+		lookbackDays: lookbackDays,
+	}
+
+	s.cron = cron.New(
+		cron.WithLocation(IST),
+		cron.WithChain(cron.SkipIfStillRunning(cron.PrintfLogger(log.Default()))),
+	)
+	if _, err := s.cron.AddFunc(spec, s.run); err != nil {
+		cancel()
+		return nil, fmt.Errorf("invalid fetch schedule %q: %w", spec, err)
+	}
+
+	return s, nil
+}
+
+func (s *scheduler) Start() {
+	s.cron.Start()
+	for _, entry := range s.cron.Entries() {
+		log.Printf("⏰ Scheduled fetch enabled, next run at %s", entry.Next.In(IST).Format(time.RFC1123))
+	}
+}
+
+func (s *scheduler) Stop() {
+	s.cancel()
+	<-s.cron.Stop().Done()
+	log.Println("✅ Scheduler stopped")
+}
+
+func (s *scheduler) run() {
+	ctx, cancel := context.WithTimeout(s.ctx, runTimeout)
+	defer cancel()
+
+	cursor, err := s.cursorRepo.Find(ctx, domain.SourceBSE)
+	if err != nil {
+		if !errors.Is(err, domain.ErrCursorNotFound) {
+			log.Printf("❌ Scheduled fetch skipped, failed to load high-water mark: %v", err)
+			return
+		}
+		cursor = &domain.SourceCursor{Source: domain.SourceBSE}
+	}
+
+	from, to := fetchWindow(time.Now(), cursor.HighWaterMark, s.lookbackDays)
+
+	log.Printf("⏰ Scheduled fetch starting (%s → %s, high-water mark %q)",
+		from.Format("2006-01-02"), to.Format("2006-01-02"), cursor.HighWaterMark)
+
+	job, err := s.ingestor.RunFetch(ctx, from, to)
+	var held *domain.LockHeldError
+	if errors.As(err, &held) {
+		log.Printf("⏭️ Scheduled fetch skipped, %v", held)
+		return
+	}
+	if err != nil {
+		log.Printf("❌ Scheduled fetch failed to run: %v", err)
+		return
+	}
+
+	cursor.LastJobID = job.ID
+	cursor.LastState = job.State
+	cursor.LastRunAt = time.Now()
+
+	// Only a run that saw every announcement may move the mark; otherwise the next
+	// run covers the same days again, and NewsID dedup keeps that cheap
+	if job.State == domain.JobCompleted && (job.Fetch == nil || job.Fetch.Complete()) {
+		cursor.HighWaterMark = to.Format("2006-01-02")
+	}
+
+	saveCtx, saveCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer saveCancel()
+	if err := s.cursorRepo.Save(saveCtx, cursor); err != nil {
+		log.Printf("⚠️ Failed to save high-water mark: %v", err)
+		return
+	}
+	log.Printf("⏰ Scheduled fetch job %s finished with state %s, high-water mark %q",
+		job.ID.Hex(), job.State, cursor.HighWaterMark)
+}
+
+// fetchWindow is the days a run at now covers, ending with the current day in IST
+func fetchWindow(now time.Time, highWaterMark string, lookbackDays int) (from, to time.Time) {
+	now = now.In(IST)
+	to = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, IST)
+	return fetchWindowStart(to, highWaterMark, lookbackDays), to
+}
+
+// fetchWindowStart is the first day a run covers: the high-water mark day itself,
+// since announcements filed later that day were not seen yet, but never more than
+// lookbackDays before the run
+func fetchWindowStart(to time.Time, highWaterMark string, lookbackDays int) time.Time {
+	earliest := to.AddDate(0, 0, -lookbackDays)
+
+	mark, err := time.ParseInLocation("2006-01-02", highWaterMark, IST)
+	if err != nil || mark.Before(earliest) {
+		return earliest
+	}
+	if mark.After(to) {
+		return to
+	}
+	return mark
+}
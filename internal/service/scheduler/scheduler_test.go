@@ -0,0 +1,114 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFetchWindow(t *testing.T) {
+	utc := func(value string) time.Time {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			t.Fatalf("bad time %q: %v", value, err)
+		}
+		return parsed
+	}
+
+	tests := []struct {
+		name          string
+		now           time.Time
+		highWaterMark string
+		lookbackDays  int
+		wantFrom      string
+		wantTo        string
+	}{
+		{
+			name:         "first run looks back lookbackDays",
+			now:          utc("2025-06-10T13:30:00Z"), // 19:00 IST
+			lookbackDays: 3,
+			wantFrom:     "2025-06-07",
+			wantTo:       "2025-06-10",
+		},
+		{
+			name:          "daily run starts from the mark day",
+			now:           utc("2025-06-10T13:30:00Z"),
+			highWaterMark: "2025-06-09",
+			lookbackDays:  3,
+			wantFrom:      "2025-06-09",
+			wantTo:        "2025-06-10",
+		},
+		{
+			name:          "run on the mark day covers that day again",
+			now:           utc("2025-06-10T13:30:00Z"),
+			highWaterMark: "2025-06-10",
+			lookbackDays:  3,
+			wantFrom:      "2025-06-10",
+			wantTo:        "2025-06-10",
+		},
+		{
+			name:          "run after downtime is capped by the lookback",
+			now:           utc("2025-06-10T13:30:00Z"),
+			highWaterMark: "2025-05-01",
+			lookbackDays:  3,
+			wantFrom:      "2025-06-07",
+			wantTo:        "2025-06-10",
+		},
+		{
+			name:          "mark in the future",
+			now:           utc("2025-06-10T13:30:00Z"),
+			highWaterMark: "2025-06-12",
+			lookbackDays:  3,
+			wantFrom:      "2025-06-10",
+			wantTo:        "2025-06-10",
+		},
+		{
+			name:          "unreadable mark",
+			now:           utc("2025-06-10T13:30:00Z"),
+			highWaterMark: "yesterday",
+			lookbackDays:  1,
+			wantFrom:      "2025-06-09",
+			wantTo:        "2025-06-10",
+		},
+		{
+			name:          "a minute before midnight IST",
+			now:           utc("2025-06-10T18:29:00Z"), // 23:59 IST on the 10th
+			highWaterMark: "2025-06-09",
+			lookbackDays:  3,
+			wantFrom:      "2025-06-09",
+			wantTo:        "2025-06-10",
+		},
+		{
+			name:          "a minute after midnight IST",
+			now:           utc("2025-06-10T18:31:00Z"), // 00:01 IST on the 11th, still the 10th in UTC
+			highWaterMark: "2025-06-09",
+			lookbackDays:  3,
+			wantFrom:      "2025-06-09",
+			wantTo:        "2025-06-11",
+		},
+		{
+			name:         "lookback across midnight IST",
+			now:          utc("2025-06-10T18:31:00Z"),
+			lookbackDays: 1,
+			wantFrom:     "2025-06-10",
+			wantTo:       "2025-06-11",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, to := fetchWindow(tt.now, tt.highWaterMark, tt.lookbackDays)
+			if got := from.Format("2006-01-02"); got != tt.wantFrom {
+				t.Errorf("from = %s, want %s", got, tt.wantFrom)
+			}
+			if got := to.Format("2006-01-02"); got != tt.wantTo {
+				t.Errorf("to = %s, want %s", got, tt.wantTo)
+			}
+			if from.Location() != IST || to.Location() != IST {
+				t.Errorf("window is in %s → %s, want IST", from.Location(), to.Location())
+			}
+			if from.Hour() != 0 || to.Hour() != 0 {
+				t.Errorf("window %v → %v does not start at midnight", from, to)
+			}
+		})
+	}
+}
@@ -0,0 +1,321 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"concall-analyser/internal/domain"
+)
+
+const (
+	// candidateLimit caps the companies each lookup contributes before scoring
+	candidateLimit = 100
+	// minScore drops candidates that share little more than a few letters with the query
+	minScore = 25
+)
+
+// Result is a company matching a search, scored from 0 to 100
+type Result struct {
+	ScripCode int     `json:"scrip_code"`
+	Name      string  `json:"name"`
+	Symbol    string  `json:"symbol,omitempty"`
+	Sector    string  `json:"sector,omitempty"`
+	Score     float64 `json:"score"`
+	// MatchedOn is what the score comes from: scrip_code, symbol, name, prefix,
+	// words, text or fuzzy
+	MatchedOn string `json:"matched_on"`
+}
+
+// Service finds companies by scrip code, symbol or name, tolerating
+// abbreviations ("HDFC Bk") and typos ("Relaince")
+type Service interface {
+	// Search returns up to limit companies matching query, best first
+	Search(ctx context.Context, query string, limit int) ([]Result, error)
+}
+
+type service struct {
+	companies domain.CompanyRepository
+}
+
+// NewService creates a search service over the company master list
+func NewService(companies domain.CompanyRepository) Service {
+	return &service{companies: companies}
+}
+
+// query is a search string in the forms the signals compare against
+type query struct {
+	raw        string
+	normalized string
+	words      []string
+	trigrams   map[string]bool
+	scripCode  int
+}
+
+func (s *service) Search(ctx context.Context, raw string, limit int) ([]Result, error) {
+	q := newQuery(raw)
+	if q.normalized == "" && q.scripCode == 0 {
+		return []Result{}, nil
+	}
+
+	// Each lookup is cheap and indexed, the scoring below decides what is relevant
+	candidates := make(map[int]domain.Company)
+	textScores := make(map[int]float64)
+
+	if q.scripCode > 0 {
+		company, err := s.companies.FindByScripCode(ctx, q.scripCode)
+		if err != nil && !errors.Is(err, domain.ErrCompanyNotFound) {
+			return nil, err
+		}
+		if company != nil {
+			candidates[company.ScripCode] = *company
+		}
+	}
+
+	if q.normalized != "" {
+		prefixed, err := s.companies.FindByNamePrefix(ctx, q.normalized, candidateLimit)
+		if err != nil {
+			return nil, err
+		}
+		for _, company := range prefixed {
+			candidates[company.ScripCode] = company
+		}
+
+		texts, err := s.companies.SearchText(ctx, q.raw, candidateLimit)
+		if err != nil {
+			return nil, err
+		}
+		for _, scored := range texts {
+			candidates[scored.ScripCode] = scored.Company
+			textScores[scored.ScripCode] = scored.Score
+		}
+
+		similar, err := s.companies.FindByTrigrams(ctx, keys(q.trigrams), candidateLimit)
+		if err != nil {
+			return nil, err
+		}
+		for _, scored := range similar {
+			candidates[scored.ScripCode] = scored.Company
+		}
+	}
+
+	results := make([]Result, 0, len(candidates))
+	for _, company := range candidates {
+		score, matchedOn := q.score(company, textScores[company.ScripCode])
+		if score < minScore {
+			continue
+		}
+		results = append(results, Result{
+			ScripCode: company.ScripCode,
+			Name:      strings.TrimSuffix(company.Name, "-$"),
+			Symbol:    company.Symbol,
+			Sector:    company.Sector,
+			Score:     math.Round(score*10) / 10,
+			MatchedOn: matchedOn,
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		if len(results[i].Name) != len(results[j].Name) {
+			return len(results[i].Name) < len(results[j].Name)
+		}
+		return results[i].Name < results[j].Name
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+func newQuery(raw string) *query {
+	raw = strings.TrimSpace(raw)
+	q := &query{
+		raw:        raw,
+		normalized: domain.NormalizeCompanyName(raw),
+	}
+	q.words = strings.Fields(q.normalized)
+	q.trigrams = matchTrigrams(q.normalized)
+	if code, err := strconv.Atoi(raw); err == nil && code > 0 {
+		q.scripCode = code
+	}
+	return q
+}
+
+// score rates how well a company matches the query as the strongest of its
+// signals, with the trigram similarity breaking ties
+func (q *query) score(company domain.Company, textScore float64) (float64, string) {
+	searchName := company.SearchName
+	if searchName == "" {
+		searchName = domain.NormalizeCompanyName(company.Name)
+	}
+	symbol := strings.ToLower(company.Symbol)
+	similarity := dice(q.trigrams, matchTrigrams(searchName))
+
+	best, matchedOn := 0.0, ""
+	consider := func(score float64, signal string) {
+		if score > best {
+			best, matchedOn = score, signal
+		}
+	}
+
+	if q.scripCode > 0 && company.ScripCode == q.scripCode {
+		consider(100, "scrip_code")
+	}
+	lowered := strings.ToLower(q.raw)
+	if symbol != "" && lowered == symbol {
+		consider(90, "symbol")
+	} else if symbol != "" && len(lowered) >= 2 && strings.HasPrefix(symbol, lowered) {
+		consider(75, "symbol")
+	}
+	if q.normalized != "" {
+		if searchName == q.normalized {
+			consider(85, "name")
+		} else if strings.HasPrefix(searchName, q.normalized) {
+			consider(70+10*float64(len(q.normalized))/float64(len(searchName)), "prefix")
+		}
+	}
+
+	nameWords := strings.Fields(searchName)
+	if symbol != "" {
+		nameWords = append(nameWords, symbol)
+	}
+	consider(65*wordsScore(q.words, nameWords), "words")
+	consider(math.Min(textScore, 3)*10, "text")
+	consider(50*similarity, "fuzzy")
+
+	return best + similarity, matchedOn
+}
+
+// wordsScore averages how well each query word matches its closest name word.
+// A query word matching nothing halves the result.
+func wordsScore(queryWords, nameWords []string) float64 {
+	if len(queryWords) == 0 {
+		return 0
+	}
+
+	total, unmatched := 0.0, false
+	for _, queryWord := range queryWords {
+		best := 0.0
+		for _, nameWord := range nameWords {
+			best = math.Max(best, wordScore(queryWord, nameWord))
+		}
+		if best == 0 {
+			unmatched = true
+		}
+		total += best
+	}
+
+	score := total / float64(len(queryWords))
+	if unmatched {
+		score /= 2
+	}
+	return score
+}
+
+// wordScore compares one query word with one name word: the same word, its
+// start, an abbreviation keeping its first letter ("bk" for "bank") or a typo
+func wordScore(queryWord, nameWord string) float64 {
+	switch {
+	case queryWord == nameWord:
+		return 1
+	case len(queryWord) >= 2 && strings.HasPrefix(nameWord, queryWord):
+		return 0.9
+	}
+
+	queryRunes, nameRunes := []rune(queryWord), []rune(nameWord)
+	if len(queryRunes) >= 2 && queryRunes[0] == nameRunes[0] && isSubsequence(queryRunes, nameRunes) {
+		return 0.7
+	}
+	if len(queryRunes) < 4 {
+		return 0
+	}
+	allowed := 1
+	if len(queryRunes) >= 8 {
+		allowed = 2
+	}
+	distance := editDistance(queryRunes, nameRunes)
+	if distance > allowed {
+		return 0
+	}
+	longest := math.Max(float64(len(queryRunes)), float64(len(nameRunes)))
+	return 0.8 * (1 - float64(distance)/longest)
+}
+
+// isSubsequence reports whether the letters of short appear in long in order
+func isSubsequence(short, long []rune) bool {
+	i := 0
+	for _, r := range long {
+		if i < len(short) && short[i] == r {
+			i++
+		}
+	}
+	return i == len(short)
+}
+
+// editDistance counts the insertions, deletions, substitutions and swaps of
+// adjacent letters that turn a into b
+func editDistance(a, b []rune) int {
+	rows := make([][]int, len(a)+1)
+	for i := range rows {
+		rows[i] = make([]int, len(b)+1)
+		rows[i][0] = i
+	}
+	for j := range rows[0] {
+		rows[0][j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			rows[i][j] = min(rows[i-1][j]+1, rows[i][j-1]+1, rows[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				rows[i][j] = min(rows[i][j], rows[i-2][j-2]+1)
+			}
+		}
+	}
+	return rows[len(a)][len(b)]
+}
+
+// matchTrigrams returns the trigrams of a normalized name without those made of
+// a word's first letter alone, which most names share
+func matchTrigrams(normalized string) map[string]bool {
+	trigrams := make(map[string]bool)
+	for _, trigram := range domain.Trigrams(normalized) {
+		if !strings.HasPrefix(trigram, "  ") {
+			trigrams[trigram] = true
+		}
+	}
+	return trigrams
+}
+
+// dice is the Sørensen–Dice similarity of two trigram sets, from 0 to 1
+func dice(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for trigram := range a {
+		if b[trigram] {
+			shared++
+		}
+	}
+	return 2 * float64(shared) / float64(len(a)+len(b))
+}
+
+func keys(set map[string]bool) []string {
+	list := make([]string, 0, len(set))
+	for key := range set {
+		list = append(list, key)
+	}
+	sort.Strings(list)
+	return list
+}
@@ -0,0 +1,301 @@
+package search
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+
+	"concall-analyser/internal/domain"
+)
+
+// memoryCompanies is a CompanyRepository over a fixed list, answering the
+// lookups search uses the way the Mongo indexes do
+type memoryCompanies struct {
+	companies []domain.Company
+}
+
+func newMemoryCompanies(companies ...domain.Company) *memoryCompanies {
+	for i := range companies {
+		companies[i].SearchName = domain.NormalizeCompanyName(companies[i].Name)
+		companies[i].Trigrams = domain.Trigrams(companies[i].SearchName)
+	}
+	return &memoryCompanies{companies: companies}
+}
+
+func (r *memoryCompanies) EnsureIndexes(ctx context.Context) error                   { return nil }
+func (r *memoryCompanies) Upsert(ctx context.Context, company *domain.Company) error { return nil }
+
+func (r *memoryCompanies) FindByScripCode(ctx context.Context, scripCode int) (*domain.Company, error) {
+	for _, company := range r.companies {
+		if company.ScripCode == scripCode {
+			return &company, nil
+		}
+	}
+	return nil, domain.ErrCompanyNotFound
+}
+
+func (r *memoryCompanies) FindScripCodesBySector(ctx context.Context, sector string) ([]int, error) {
+	return nil, nil
+}
+
+func (r *memoryCompanies) FindProfiled(ctx context.Context, scripCodes []int) (map[int]bool, error) {
+	return nil, nil
+}
+
+func (r *memoryCompanies) Sectors(ctx context.Context) ([]domain.SectorCount, error) {
+	return nil, nil
+}
+
+func (r *memoryCompanies) InsertMissing(ctx context.Context, companies []domain.Company) error {
+	return nil
+}
+
+func (r *memoryCompanies) BackfillSearchKeys(ctx context.Context) (int, error) { return 0, nil }
+
+func (r *memoryCompanies) FindByNamePrefix(ctx context.Context, prefix string, limit int) ([]domain.Company, error) {
+	var found []domain.Company
+	for _, company := range r.companies {
+		if strings.HasPrefix(company.SearchName, prefix) {
+			found = append(found, company)
+		}
+	}
+	return found, nil
+}
+
+// SearchText scores companies by the query words found among their name words or symbol
+func (r *memoryCompanies) SearchText(ctx context.Context, query string, limit int) ([]domain.ScoredCompany, error) {
+	var found []domain.ScoredCompany
+	for _, company := range r.companies {
+		words := append(strings.Fields(company.SearchName), strings.ToLower(company.Symbol))
+		score := 0.0
+		for _, queryWord := range strings.Fields(strings.ToLower(query)) {
+			for _, word := range words {
+				if word == queryWord {
+					score++
+					break
+				}
+			}
+		}
+		if score > 0 {
+			found = append(found, domain.ScoredCompany{Company: company, Score: score})
+		}
+	}
+	return found, nil
+}
+
+func (r *memoryCompanies) FindByTrigrams(ctx context.Context, trigrams []string, limit int) ([]domain.ScoredCompany, error) {
+	var found []domain.ScoredCompany
+	for _, company := range r.companies {
+		shared := 0
+		for _, trigram := range trigrams {
+			for _, own := range company.Trigrams {
+				if own == trigram {
+					shared++
+					break
+				}
+			}
+		}
+		if shared > 0 {
+			found = append(found, domain.ScoredCompany{Company: company, Score: float64(shared)})
+		}
+	}
+	return found, nil
+}
+
+var testCompanies = []domain.Company{
+	{ScripCode: 500180, Name: "HDFC Bank Ltd-$", Symbol: "HDFCBANK"},
+	{ScripCode: 540777, Name: "HDFC Life Insurance Company Ltd", Symbol: "HDFCLIFE"},
+	{ScripCode: 541729, Name: "HDFC Asset Management Company Ltd", Symbol: "HDFCAMC"},
+	{ScripCode: 500325, Name: "Reliance Industries Ltd", Symbol: "RELIANCE"},
+	{ScripCode: 532939, Name: "Reliance Power Ltd", Symbol: "RPOWER"},
+	{ScripCode: 500400, Name: "The Tata Power Co. Ltd.-$", Symbol: "TATAPOWER"},
+	{ScripCode: 500570, Name: "Tata Motors Ltd", Symbol: "TATAMOTORS"},
+	{ScripCode: 500209, Name: "Infosys Ltd", Symbol: "INFY"},
+}
+
+func TestSearch(t *testing.T) {
+	svc := NewService(newMemoryCompanies(append([]domain.Company(nil), testCompanies...)...))
+
+	tests := []struct {
+		query string
+		// want are the scrip codes expected first, in order
+		want      []int
+		matchedOn string
+	}{
+		{query: "HDFC Bk", want: []int{500180}, matchedOn: "words"},
+		{query: "hdfc bank", want: []int{500180}, matchedOn: "name"},
+		{query: "Relaince Industries", want: []int{500325}, matchedOn: "words"},
+		{query: "500325", want: []int{500325}, matchedOn: "scrip_code"},
+		{query: "TATAPOWER", want: []int{500400}, matchedOn: "symbol"},
+		{query: "The Tata Power Co. Ltd.-$", want: []int{500400}, matchedOn: "name"},
+		{query: "infosis", want: []int{500209}, matchedOn: "words"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			results, err := svc.Search(context.Background(), tt.query, 5)
+			if err != nil {
+				t.Fatalf("Search() error = %v", err)
+			}
+			if len(results) < len(tt.want) {
+				t.Fatalf("Search() = %+v, want %v first", results, tt.want)
+			}
+			for i, code := range tt.want {
+				if results[i].ScripCode != code {
+					t.Errorf("result %d = %d %s, want %d", i, results[i].ScripCode, results[i].Name, code)
+				}
+			}
+			if got := results[0].MatchedOn; got != tt.matchedOn {
+				t.Errorf("best match matched on %s, want %s", got, tt.matchedOn)
+			}
+			if strings.HasSuffix(results[0].Name, "-$") {
+				t.Errorf("result name %q keeps the -$ suffix", results[0].Name)
+			}
+		})
+	}
+}
+
+func TestSearchTypoFindsEveryMatch(t *testing.T) {
+	svc := NewService(newMemoryCompanies(append([]domain.Company(nil), testCompanies...)...))
+
+	results, err := svc.Search(context.Background(), "Relaince", 5)
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(results) < 2 {
+		t.Fatalf("Search() = %+v, want both Reliance companies", results)
+	}
+	for _, result := range results[:2] {
+		if result.ScripCode != 500325 && result.ScripCode != 532939 {
+			t.Errorf("Search() = %+v, want the Reliance companies first", results)
+		}
+	}
+}
+
+func TestSearchWithoutMatches(t *testing.T) {
+	svc := NewService(newMemoryCompanies(append([]domain.Company(nil), testCompanies...)...))
+
+	for _, query := range []string{"", "   ", "-$", "zzzzqx"} {
+		results, err := svc.Search(context.Background(), query, 5)
+		if err != nil {
+			t.Fatalf("Search(%q) error = %v", query, err)
+		}
+		if len(results) != 0 {
+			t.Errorf("Search(%q) = %+v, want none", query, results)
+		}
+	}
+}
+
+func TestWordScore(t *testing.T) {
+	tests := []struct {
+		queryWord, nameWord string
+		want                float64
+	}{
+		{queryWord: "bank", nameWord: "bank", want: 1},
+		{queryWord: "ban", nameWord: "bank", want: 0.9},
+		{queryWord: "bk", nameWord: "bank", want: 0.7},
+		{queryWord: "relance", nameWord: "reliance", want: 0.7},
+		// A swap of two letters is one edit, 8 letters allow two
+		{queryWord: "relaince", nameWord: "reliance", want: 0.8 * (1 - 1.0/8)},
+		{queryWord: "infosis", nameWord: "infosys", want: 0.8 * (1 - 1.0/7)},
+		{queryWord: "bnak", nameWord: "bank", want: 0.8 * (1 - 1.0/4)},
+		// Under 8 letters only one edit is allowed
+		{queryWord: "inxosxs", nameWord: "infosys", want: 0},
+		// Abbreviations keep the first letter and single letters match nothing
+		{queryWord: "kb", nameWord: "bank", want: 0},
+		{queryWord: "b", nameWord: "bank", want: 0},
+		// Too short to be taken for a typo
+		{queryWord: "tcs", nameWord: "tvs", want: 0},
+		{queryWord: "hdfc", nameWord: "tata", want: 0},
+	}
+
+	for _, tt := range tests {
+		if got := wordScore(tt.queryWord, tt.nameWord); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("wordScore(%q, %q) = %v, want %v", tt.queryWord, tt.nameWord, got, tt.want)
+		}
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{a: "", b: "", want: 0},
+		{a: "", b: "abc", want: 3},
+		{a: "abc", b: "", want: 3},
+		{a: "bank", b: "bank", want: 0},
+		{a: "kitten", b: "sitting", want: 3},
+		{a: "bnak", b: "bank", want: 1},
+		{a: "relaince", b: "reliance", want: 1},
+		{a: "infosis", b: "infosys", want: 1},
+		{a: "nestle", b: "nestlé", want: 1},
+		// A swapped pair is not edited again
+		{a: "ca", b: "abc", want: 3},
+	}
+
+	for _, tt := range tests {
+		if got := editDistance([]rune(tt.a), []rune(tt.b)); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+		if got := editDistance([]rune(tt.b), []rune(tt.a)); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.b, tt.a, got, tt.want)
+		}
+	}
+}
+
+func TestWordsScore(t *testing.T) {
+	tests := []struct {
+		name       string
+		queryWords []string
+		nameWords  []string
+		want       float64
+	}{
+		{name: "no query words", queryWords: nil, nameWords: []string{"hdfc", "bank"}, want: 0},
+		{name: "every word", queryWords: []string{"hdfc", "bank"}, nameWords: []string{"hdfc", "bank"}, want: 1},
+		{name: "abbreviation", queryWords: []string{"hdfc", "bk"}, nameWords: []string{"hdfc", "bank"}, want: (1 + 0.7) / 2},
+		{name: "best name word per query word", queryWords: []string{"ban"}, nameWords: []string{"bandhan", "bank"}, want: 0.9},
+		{name: "unmatched word halves", queryWords: []string{"hdfc", "xyz"}, nameWords: []string{"hdfc", "bank"}, want: 0.25},
+		{name: "symbol as a word", queryWords: []string{"tatapower"}, nameWords: []string{"tata", "power", "tatapower"}, want: 1},
+	}
+
+	for _, tt := range tests {
+		if got := wordsScore(tt.queryWords, tt.nameWords); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%s: wordsScore(%q, %q) = %v, want %v", tt.name, tt.queryWords, tt.nameWords, got, tt.want)
+		}
+	}
+}
+
+func TestDice(t *testing.T) {
+	set := func(trigrams ...string) map[string]bool {
+		s := make(map[string]bool)
+		for _, trigram := range trigrams {
+			s[trigram] = true
+		}
+		return s
+	}
+
+	tests := []struct {
+		name string
+		a, b map[string]bool
+		want float64
+	}{
+		{name: "empty", a: set(), b: set("tat"), want: 0},
+		{name: "both empty", a: set(), b: set(), want: 0},
+		{name: "identical", a: set("tat", "ata"), b: set("tat", "ata"), want: 1},
+		{name: "half shared", a: set("tat", "ata"), b: set("ata", "tap"), want: 0.5},
+		{name: "subset", a: set("tat"), b: set("tat", "ata", "ta "), want: 0.5},
+		{name: "disjoint", a: set("tat"), b: set("ban"), want: 0},
+		{name: "names", a: matchTrigrams("tata power"), b: matchTrigrams("tata power"), want: 1},
+	}
+
+	for _, tt := range tests {
+		if got := dice(tt.a, tt.b); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%s: dice() = %v, want %v", tt.name, got, tt.want)
+		}
+		if got := dice(tt.b, tt.a); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%s: dice() reversed = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"concall-analyser/internal/infrastructure/file"
+)
+
+type localStore struct {
+	root string
+}
+
+// NewLocalStore creates an object store that keeps files under root
+func NewLocalStore(root string) (ObjectStore, error) {
+	if err := file.CreateDirectory(root); err != nil {
+		return nil, err
+	}
+	return &localStore{root: root}, nil
+}
+
+func (s *localStore) Backend() string {
+	return "local"
+}
+
+// Put writes to a temporary file first so a crash never leaves a partial
+// object under its final key
+func (s *localStore) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	dest := s.path(key)
+	if err := file.CreateDirectory(filepath.Dir(dest)); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(dest), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", key, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", key, err)
+	}
+
+	if err := os.Rename(tmp.Name(), dest); err != nil {
+		return fmt.Errorf("failed to store %s: %w", key, err)
+	}
+	return nil
+}
+
+func (s *localStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	f, err := os.Open(s.path(key))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrObjectNotFound
+		}
+		return nil, fmt.Errorf("failed to open %s: %w", key, err)
+	}
+	return f, nil
+}
+
+func (s *localStore) Exists(ctx context.Context, key string) (bool, error) {
+	_, err := os.Stat(s.path(key))
+	if err == nil {
+		return true, nil
+	}
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	return false, fmt.Errorf("failed to stat %s: %w", key, err)
+}
+
+func (s *localStore) path(key string) string {
+	return filepath.Join(s.root, filepath.FromSlash(key))
+}
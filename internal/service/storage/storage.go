@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+
+	"concall-analyser/config"
+)
+
+// ErrObjectNotFound is returned when no object is stored under a key
+var ErrObjectNotFound = errors.New("object not found")
+
+// ObjectStore keeps archived files by key
+type ObjectStore interface {
+	// Backend names the implementation, recorded on each document so it can be found again
+	Backend() string
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get returns the object's contents or ErrObjectNotFound; the caller closes the reader
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Exists(ctx context.Context, key string) (bool, error)
+}
+
+// New creates the object store selected by STORAGE_BACKEND
+func New(ctx context.Context, cfg *config.Config) (ObjectStore, error) {
+	switch cfg.StorageBackend {
+	case "local":
+		return NewLocalStore(cfg.ArchiveDir)
+	case "s3":
+		return NewS3Store(ctx, S3Options{
+			Endpoint:  cfg.S3Endpoint,
+			Bucket:    cfg.S3Bucket,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+			Region:    cfg.S3Region,
+			UseSSL:    cfg.S3UseSSL,
+		})
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q (expected local or s3)", cfg.StorageBackend)
+	}
+}
+
+// ContentKey is the key a PDF is stored under, derived from its SHA-256. The
+// two character prefix keeps local directories from growing too large.
+func ContentKey(sum string) string {
+	return path.Join("pdf", sum[:2], sum+".pdf")
+}
+
+// HashFile returns the hex encoded SHA-256 of a file and its size
+func HashFile(filePath string) (string, int64, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to open %s: %w", filePath, err)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to hash %s: %w", filePath, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), size, nil
+}
@@ -0,0 +1,403 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"concall-analyser/internal/domain"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Headers sent with every delivery
+const (
+	HeaderEvent     = "X-Concall-Event"
+	HeaderDelivery  = "X-Concall-Delivery"
+	HeaderTimestamp = "X-Concall-Timestamp"
+	// HeaderSignature is "sha256=" followed by the hex HMAC-SHA256 of
+	// "<timestamp>.<body>" keyed with the webhook secret
+	HeaderSignature = "X-Concall-Signature"
+)
+
+const (
+	// retryBaseDelay is the wait before the second attempt, quadrupled for each one after
+	retryBaseDelay = 30 * time.Second
+	// retryMaxDelay caps the wait between attempts
+	retryMaxDelay = 6 * time.Hour
+	// maxLoggedBody is how much of a failed response is kept in the delivery log
+	maxLoggedBody = 512
+)
+
+// Dispatcher sends signed payloads to registered webhooks, retrying failed
+// deliveries in the background
+type Dispatcher interface {
+	// ConcallAdded queues a delivery of a newly stored summary to every active
+	// webhook whose filter matches. Deliveries are attempted in the background.
+	ConcallAdded(summary domain.ConcallSummary)
+
+	// Test sends a ping to a webhook once, without retrying, and returns the delivery
+	Test(ctx context.Context, webhook *domain.Webhook) (*domain.WebhookDelivery, error)
+
+	// Start starts the retry loop
+	Start()
+	// Stop stops the retry loop and waits for deliveries in flight; the ones it
+	// interrupts are retried later
+	Stop()
+}
+
+// Options tune delivery
+type Options struct {
+	// MaxAttempts is how many times a delivery is tried before it is marked failed
+	MaxAttempts int
+	// Timeout bounds each attempt
+	Timeout time.Duration
+	// RetryInterval is how often due retries are looked for
+	RetryInterval time.Duration
+}
+
+// Payload is the JSON body of a delivery
+type Payload struct {
+	ID        string      `json:"id"` // delivery ID, the same across retries
+	Event     string      `json:"event"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// ConcallAddedData is the data of a concall.added payload
+type ConcallAddedData struct {
+	Concall domain.ConcallLite `json:"concall"`
+}
+
+// PingData is the data of a ping payload
+type PingData struct {
+	WebhookID string `json:"webhook_id"`
+	Message   string `json:"message"`
+}
+
+type dispatcher struct {
+	webhooks   domain.WebhookRepository
+	deliveries domain.WebhookDeliveryRepository
+	watchlists domain.WatchlistRepository
+	client     *http.Client
+	opts       Options
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewDispatcher creates a Dispatcher. Watchlist filters are resolved through watchlists.
+func NewDispatcher(webhooks domain.WebhookRepository, deliveries domain.WebhookDeliveryRepository, watchlists domain.WatchlistRepository, opts Options) Dispatcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &dispatcher{
+		webhooks:   webhooks,
+		deliveries: deliveries,
+		watchlists: watchlists,
+		client:     &http.Client{Timeout: opts.Timeout},
+		opts:       opts,
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+}
+
+// Sign returns the signature of a delivery body sent at timestamp (Unix seconds)
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (d *dispatcher) Start() {
+	d.wg.Add(1)
+	go d.loop()
+	log.Printf("🪝 Webhook dispatcher started, retrying every %v", d.opts.RetryInterval)
+}
+
+func (d *dispatcher) Stop() {
+	d.cancel()
+	d.wg.Wait()
+	log.Println("✅ Webhook dispatcher stopped")
+}
+
+func (d *dispatcher) ConcallAdded(summary domain.ConcallSummary) {
+	if d.ctx.Err() != nil {
+		return
+	}
+
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+
+		ctx, cancel := context.WithTimeout(d.ctx, 10*time.Second)
+		webhooks, err := d.webhooks.FindActive(ctx)
+		cancel()
+		if err != nil {
+			log.Printf("⚠️ Failed to load webhooks for %s: %v", summary.NewsID, err)
+			return
+		}
+
+		for i := range webhooks {
+			webhook := &webhooks[i]
+			if !d.matches(webhook, summary) {
+				continue
+			}
+			delivery, err := d.queue(webhook, domain.WebhookEventConcallAdded, summary.NewsID, ConcallAddedData{Concall: summary.Lite()})
+			if err != nil {
+				log.Printf("⚠️ Failed to queue webhook %s for %s: %v", webhook.ID.Hex(), summary.NewsID, err)
+				continue
+			}
+			d.attempt(webhook, delivery, true)
+		}
+	}()
+}
+
+func (d *dispatcher) Test(ctx context.Context, webhook *domain.Webhook) (*domain.WebhookDelivery, error) {
+	delivery, err := d.queue(webhook, domain.WebhookEventPing, "", PingData{
+		WebhookID: webhook.ID.Hex(),
+		Message:   "Test delivery from Concall-Analyser",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	attempt, state := d.attempt(webhook, delivery, false)
+	delivery.Attempts = append(delivery.Attempts, attempt)
+	delivery.State = state
+	delivery.NextAttemptAt = nil
+	return delivery, nil
+}
+
+// matches reports whether a webhook's filter selects summary
+func (d *dispatcher) matches(webhook *domain.Webhook, summary domain.ConcallSummary) bool {
+	filter := webhook.Filter
+	if filter.HasGuidance && (summary.Guidance == "" || summary.Guidance == "NA") {
+		return false
+	}
+	if len(filter.ScripCodes) > 0 && !containsCode(filter.ScripCodes, summary.ScripCode) {
+		return false
+	}
+	if filter.WatchlistID != "" {
+		id, err := primitive.ObjectIDFromHex(filter.WatchlistID)
+		if err != nil {
+			return false
+		}
+		ctx, cancel := context.WithTimeout(d.ctx, 10*time.Second)
+		defer cancel()
+		watchlist, err := d.watchlists.FindByID(ctx, id)
+		if err != nil {
+			if !errors.Is(err, domain.ErrWatchlistNotFound) {
+				log.Printf("⚠️ Failed to load watchlist %s for webhook %s: %v", filter.WatchlistID, webhook.ID.Hex(), err)
+			}
+			return false
+		}
+		if !containsCode(watchlist.ScripCodes(), summary.ScripCode) {
+			return false
+		}
+	}
+	return true
+}
+
+// queue stores a pending delivery whose first attempt is about to be made.
+// Its next attempt is set past that attempt so the retry loop leaves it alone.
+func (d *dispatcher) queue(webhook *domain.Webhook, event, newsID string, data interface{}) (*domain.WebhookDelivery, error) {
+	now := time.Now()
+	id := primitive.NewObjectID()
+	body, err := json.Marshal(Payload{
+		ID:        id.Hex(),
+		Event:     event,
+		CreatedAt: now,
+		Data:      data,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode payload: %w", err)
+	}
+
+	lease := now.Add(d.lease())
+	delivery := &domain.WebhookDelivery{
+		ID:            id,
+		WebhookID:     webhook.ID,
+		Event:         event,
+		NewsID:        newsID,
+		Payload:       string(body),
+		State:         domain.DeliveryPending,
+		NextAttemptAt: &lease,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+
+	ctx, cancel := context.WithTimeout(d.ctx, 10*time.Second)
+	defer cancel()
+	if err := d.deliveries.Create(ctx, delivery); err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// attempt POSTs a delivery once and records the outcome. With retry, a failure
+// short of MaxAttempts schedules another attempt.
+func (d *dispatcher) attempt(webhook *domain.Webhook, delivery *domain.WebhookDelivery, retry bool) (domain.DeliveryAttempt, domain.DeliveryState) {
+	attempt := d.post(webhook, delivery)
+
+	state := domain.DeliverySucceeded
+	var next *time.Time
+	if attempt.Error != "" {
+		state = domain.DeliveryFailed
+		attempts := len(delivery.Attempts) + 1
+		if retry && attempts < d.opts.MaxAttempts {
+			state = domain.DeliveryPending
+			at := time.Now().Add(backoff(attempts))
+			next = &at
+		}
+		log.Printf("⚠️ Webhook %s delivery %s attempt %d failed: %s", webhook.ID.Hex(), delivery.ID.Hex(), attempts, attempt.Error)
+	}
+
+	// Recorded even when stopping, so the log shows the attempt
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := d.deliveries.RecordAttempt(ctx, delivery.ID, attempt, state, next); err != nil {
+		log.Printf("⚠️ Failed to record webhook delivery %s: %v", delivery.ID.Hex(), err)
+	}
+	return attempt, state
+}
+
+// post sends a delivery to the webhook URL, signed with its secret
+func (d *dispatcher) post(webhook *domain.Webhook, delivery *domain.WebhookDelivery) domain.DeliveryAttempt {
+	started := time.Now()
+	attempt := domain.DeliveryAttempt{At: started}
+
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(d.ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		attempt.Error = err.Error()
+		attempt.DurationMS = time.Since(started).Milliseconds()
+		return attempt
+	}
+
+	timestamp := strconv.FormatInt(started.Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Concall-Analyser-Webhook/1")
+	req.Header.Set(HeaderEvent, delivery.Event)
+	req.Header.Set(HeaderDelivery, delivery.ID.Hex())
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, Sign(webhook.Secret, timestamp, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		attempt.Error = err.Error()
+		attempt.DurationMS = time.Since(started).Milliseconds()
+		return attempt
+	}
+	defer resp.Body.Close()
+
+	attempt.StatusCode = resp.StatusCode
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, maxLoggedBody))
+		attempt.Error = strings.TrimSpace(fmt.Sprintf("unexpected status %d: %s", resp.StatusCode, snippet))
+	} else {
+		io.Copy(io.Discard, resp.Body)
+	}
+	attempt.DurationMS = time.Since(started).Milliseconds()
+	return attempt
+}
+
+func (d *dispatcher) loop() {
+	defer d.wg.Done()
+
+	ticker := time.NewTicker(d.opts.RetryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-d.ctx.Done():
+			return
+		case <-ticker.C:
+			d.retryDue()
+		}
+	}
+}
+
+// retryDue attempts every pending delivery whose next attempt is due
+func (d *dispatcher) retryDue() {
+	for d.ctx.Err() == nil {
+		ctx, cancel := context.WithTimeout(d.ctx, 10*time.Second)
+		now := time.Now()
+		delivery, err := d.deliveries.ClaimDue(ctx, now, now.Add(d.lease()))
+		if errors.Is(err, domain.ErrDeliveryNotFound) {
+			cancel()
+			return
+		}
+		if err != nil {
+			cancel()
+			log.Printf("⚠️ Failed to claim webhook delivery: %v", err)
+			return
+		}
+
+		webhook, err := d.webhooks.FindByID(ctx, delivery.WebhookID)
+		cancel()
+		if err != nil || !webhook.Active {
+			reason := "webhook was deleted"
+			if err == nil {
+				reason = "webhook was disabled"
+			} else if !errors.Is(err, domain.ErrWebhookNotFound) {
+				log.Printf("⚠️ Failed to load webhook %s: %v", delivery.WebhookID.Hex(), err)
+				continue
+			}
+			d.abandon(delivery, reason)
+			continue
+		}
+
+		d.attempt(webhook, delivery, true)
+	}
+}
+
+// abandon marks a delivery failed without sending it
+func (d *dispatcher) abandon(delivery *domain.WebhookDelivery, reason string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	attempt := domain.DeliveryAttempt{At: time.Now(), Error: reason}
+	if err := d.deliveries.RecordAttempt(ctx, delivery.ID, attempt, domain.DeliveryFailed, nil); err != nil {
+		log.Printf("⚠️ Failed to record webhook delivery %s: %v", delivery.ID.Hex(), err)
+	}
+}
+
+// lease is how long a claimed delivery is left alone by other replicas
+func (d *dispatcher) lease() time.Duration {
+	return 2*d.opts.Timeout + time.Minute
+}
+
+// backoff is the wait after the given number of failed attempts
+func backoff(attempts int) time.Duration {
+	delay := retryBaseDelay
+	for i := 1; i < attempts && delay < retryMaxDelay; i++ {
+		delay *= 4
+	}
+	if delay > retryMaxDelay {
+		delay = retryMaxDelay
+	}
+	return delay
+}
+
+func containsCode(codes []int, code int) bool {
+	for _, c := range codes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
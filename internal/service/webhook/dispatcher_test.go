@@ -0,0 +1,411 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"concall-analyser/internal/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// memoryWebhooks is a WebhookRepository kept in memory
+type memoryWebhooks struct {
+	mu       sync.Mutex
+	webhooks []domain.Webhook
+}
+
+func (r *memoryWebhooks) add(url, secret string) *domain.Webhook {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	webhook := domain.Webhook{ID: primitive.NewObjectID(), Name: "test", URL: url, Secret: secret, Active: true}
+	r.webhooks = append(r.webhooks, webhook)
+	return &webhook
+}
+
+func (r *memoryWebhooks) Create(ctx context.Context, webhook *domain.Webhook) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	webhook.ID = primitive.NewObjectID()
+	r.webhooks = append(r.webhooks, *webhook)
+	return nil
+}
+
+func (r *memoryWebhooks) FindByID(ctx context.Context, id primitive.ObjectID) (*domain.Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, webhook := range r.webhooks {
+		if webhook.ID == id {
+			return &webhook, nil
+		}
+	}
+	return nil, domain.ErrWebhookNotFound
+}
+
+func (r *memoryWebhooks) List(ctx context.Context) ([]domain.Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]domain.Webhook(nil), r.webhooks...), nil
+}
+
+func (r *memoryWebhooks) FindActive(ctx context.Context) ([]domain.Webhook, error) {
+	return r.List(ctx)
+}
+
+func (r *memoryWebhooks) Update(ctx context.Context, id primitive.ObjectID, fields bson.M) error {
+	return nil
+}
+
+func (r *memoryWebhooks) Delete(ctx context.Context, id primitive.ObjectID) error {
+	return nil
+}
+
+// memoryDeliveries is a WebhookDeliveryRepository kept in memory, claiming due
+// deliveries the way the Mongo one does
+type memoryDeliveries struct {
+	mu         sync.Mutex
+	deliveries []*domain.WebhookDelivery
+}
+
+func (r *memoryDeliveries) EnsureIndexes(ctx context.Context) error { return nil }
+
+func (r *memoryDeliveries) Create(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored := *delivery
+	r.deliveries = append(r.deliveries, &stored)
+	return nil
+}
+
+func (r *memoryDeliveries) RecordAttempt(ctx context.Context, id primitive.ObjectID, attempt domain.DeliveryAttempt, state domain.DeliveryState, nextAttemptAt *time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, delivery := range r.deliveries {
+		if delivery.ID == id {
+			delivery.Attempts = append(delivery.Attempts, attempt)
+			delivery.State = state
+			delivery.NextAttemptAt = nextAttemptAt
+			return nil
+		}
+	}
+	return domain.ErrDeliveryNotFound
+}
+
+func (r *memoryDeliveries) ClaimDue(ctx context.Context, now, leaseUntil time.Time) (*domain.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, delivery := range r.deliveries {
+		if delivery.State == domain.DeliveryPending && delivery.NextAttemptAt != nil && !delivery.NextAttemptAt.After(now) {
+			delivery.NextAttemptAt = &leaseUntil
+			claimed := *delivery
+			claimed.Attempts = append([]domain.DeliveryAttempt(nil), delivery.Attempts...)
+			return &claimed, nil
+		}
+	}
+	return nil, domain.ErrDeliveryNotFound
+}
+
+func (r *memoryDeliveries) FindByWebhook(ctx context.Context, webhookID primitive.ObjectID, filter bson.M, opts *options.FindOptions) ([]domain.WebhookDelivery, error) {
+	return nil, nil
+}
+
+func (r *memoryDeliveries) CountByWebhook(ctx context.Context, webhookID primitive.ObjectID, filter bson.M) (int64, error) {
+	return 0, nil
+}
+
+// only returns a copy of the single stored delivery
+func (r *memoryDeliveries) only(t *testing.T) domain.WebhookDelivery {
+	t.Helper()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.deliveries) != 1 {
+		t.Fatalf("%d deliveries stored, want 1", len(r.deliveries))
+	}
+	return *r.deliveries[0]
+}
+
+// makeDue moves every scheduled attempt to the past, as if the backoff had elapsed
+func (r *memoryDeliveries) makeDue() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	past := time.Now().Add(-time.Second)
+	for _, delivery := range r.deliveries {
+		if delivery.NextAttemptAt != nil {
+			delivery.NextAttemptAt = &past
+		}
+	}
+}
+
+// receiver is a webhook endpoint answering with statuses in turn, repeating
+// the last one, and keeping the requests it got
+type receiver struct {
+	*httptest.Server
+	mu       sync.Mutex
+	statuses []int
+	requests []receivedRequest
+}
+
+type receivedRequest struct {
+	header http.Header
+	body   []byte
+}
+
+func newReceiver(t *testing.T, statuses ...int) *receiver {
+	t.Helper()
+	r := &receiver{statuses: statuses}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		r.mu.Lock()
+		r.requests = append(r.requests, receivedRequest{header: req.Header.Clone(), body: body})
+		status := r.statuses[0]
+		if len(r.statuses) > 1 {
+			r.statuses = r.statuses[1:]
+		}
+		r.mu.Unlock()
+		w.WriteHeader(status)
+		io.WriteString(w, http.StatusText(status))
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+func (r *receiver) received() []receivedRequest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]receivedRequest(nil), r.requests...)
+}
+
+func newTestDispatcher(maxAttempts int) (*dispatcher, *memoryWebhooks, *memoryDeliveries) {
+	webhooks, deliveries := &memoryWebhooks{}, &memoryDeliveries{}
+	d := NewDispatcher(webhooks, deliveries, nil, Options{
+		MaxAttempts:   maxAttempts,
+		Timeout:       5 * time.Second,
+		RetryInterval: time.Minute,
+	})
+	return d.(*dispatcher), webhooks, deliveries
+}
+
+var testSummary = domain.ConcallSummary{NewsID: "news-1", ScripCode: 500325, Name: "Reliance Industries Ltd", Guidance: "FY26 revenue growth of 15%"}
+
+// deliver sends testSummary and waits for its first attempt
+func deliver(d *dispatcher) {
+	d.ConcallAdded(testSummary)
+	d.wg.Wait()
+}
+
+// checkSchedule checks that a pending delivery's next attempt waits backoff(attempts)
+func checkSchedule(t *testing.T, delivery domain.WebhookDelivery, attempts int) {
+	t.Helper()
+	if delivery.State != domain.DeliveryPending {
+		t.Fatalf("state after %d failed attempts = %s, want %s", attempts, delivery.State, domain.DeliveryPending)
+	}
+	if delivery.NextAttemptAt == nil {
+		t.Fatalf("no next attempt scheduled after %d failed attempts", attempts)
+	}
+	wait := delivery.NextAttemptAt.Sub(delivery.Attempts[attempts-1].At)
+	if want := backoff(attempts); wait < want || wait > want+5*time.Second {
+		t.Errorf("next attempt after %d failures waits %v, want %v", attempts, wait, want)
+	}
+}
+
+func TestDeliverySignature(t *testing.T) {
+	server := newReceiver(t, http.StatusOK)
+	d, webhooks, deliveries := newTestDispatcher(3)
+	webhooks.add(server.URL, "s3cret")
+
+	before := time.Now().Unix()
+	deliver(d)
+	after := time.Now().Unix()
+
+	requests := server.received()
+	if len(requests) != 1 {
+		t.Fatalf("%d requests received, want 1", len(requests))
+	}
+	req, delivery := requests[0], deliveries.only(t)
+
+	timestamp := req.header.Get(HeaderTimestamp)
+	sent, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || sent < before || sent > after {
+		t.Errorf("%s = %q, want the Unix time of sending (%d to %d)", HeaderTimestamp, timestamp, before, after)
+	}
+	if got, want := req.header.Get(HeaderSignature), Sign("s3cret", timestamp, req.body); got != want {
+		t.Errorf("%s = %q, want %q", HeaderSignature, got, want)
+	}
+	if req.header.Get(HeaderSignature) == Sign("other", timestamp, req.body) {
+		t.Errorf("signature does not depend on the secret")
+	}
+	if req.header.Get(HeaderSignature) == Sign("s3cret", strconv.FormatInt(sent+1, 10), req.body) {
+		t.Errorf("signature does not depend on the timestamp")
+	}
+	if got := req.header.Get(HeaderEvent); got != domain.WebhookEventConcallAdded {
+		t.Errorf("%s = %q, want %q", HeaderEvent, got, domain.WebhookEventConcallAdded)
+	}
+	if got := req.header.Get(HeaderDelivery); got != delivery.ID.Hex() {
+		t.Errorf("%s = %q, want %q", HeaderDelivery, got, delivery.ID.Hex())
+	}
+	if got := req.header.Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", got)
+	}
+
+	var payload struct {
+		ID    string           `json:"id"`
+		Event string           `json:"event"`
+		Data  ConcallAddedData `json:"data"`
+	}
+	if err := json.Unmarshal(req.body, &payload); err != nil {
+		t.Fatalf("undecodable body %q: %v", req.body, err)
+	}
+	if payload.ID != delivery.ID.Hex() || payload.Event != domain.WebhookEventConcallAdded || payload.Data.Concall.NewsID != testSummary.NewsID {
+		t.Errorf("payload = %+v, want delivery %s of %s for %s", payload, delivery.ID.Hex(), domain.WebhookEventConcallAdded, testSummary.NewsID)
+	}
+}
+
+func TestDeliveryRetriesServerErrorsWithBackoff(t *testing.T) {
+	server := newReceiver(t, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusOK)
+	d, webhooks, deliveries := newTestDispatcher(5)
+	webhooks.add(server.URL, "s3cret")
+
+	deliver(d)
+	delivery := deliveries.only(t)
+	if got := delivery.Attempts[0].StatusCode; got != http.StatusBadGateway {
+		t.Fatalf("first attempt status = %d, want %d", got, http.StatusBadGateway)
+	}
+	checkSchedule(t, delivery, 1)
+
+	// Nothing is retried before the backoff elapses
+	d.retryDue()
+	if got := len(server.received()); got != 1 {
+		t.Fatalf("%d requests before the backoff elapsed, want 1", got)
+	}
+
+	deliveries.makeDue()
+	d.retryDue()
+	delivery = deliveries.only(t)
+	if len(delivery.Attempts) != 2 || delivery.Attempts[1].StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("attempts after the first retry = %+v, want a second one answered %d", delivery.Attempts, http.StatusServiceUnavailable)
+	}
+	checkSchedule(t, delivery, 2)
+
+	deliveries.makeDue()
+	d.retryDue()
+	delivery = deliveries.only(t)
+	if delivery.State != domain.DeliverySucceeded || delivery.NextAttemptAt != nil {
+		t.Fatalf("after a 200 state = %s, next attempt = %v, want %s and none", delivery.State, delivery.NextAttemptAt, domain.DeliverySucceeded)
+	}
+
+	// Every retry carries the same delivery, signed afresh
+	requests := server.received()
+	if len(requests) != 3 {
+		t.Fatalf("%d requests received, want 3", len(requests))
+	}
+	for i, req := range requests {
+		if got := req.header.Get(HeaderDelivery); got != delivery.ID.Hex() {
+			t.Errorf("request %d %s = %q, want %q", i+1, HeaderDelivery, got, delivery.ID.Hex())
+		}
+		if got, want := req.header.Get(HeaderSignature), Sign("s3cret", req.header.Get(HeaderTimestamp), req.body); got != want {
+			t.Errorf("request %d %s = %q, want %q", i+1, HeaderSignature, got, want)
+		}
+	}
+}
+
+func TestDeliveryGivesUpAfterMaxAttempts(t *testing.T) {
+	const maxAttempts = 3
+	server := newReceiver(t, http.StatusInternalServerError)
+	d, webhooks, deliveries := newTestDispatcher(maxAttempts)
+	webhooks.add(server.URL, "s3cret")
+
+	deliver(d)
+	for i := 1; i < maxAttempts; i++ {
+		checkSchedule(t, deliveries.only(t), i)
+		deliveries.makeDue()
+		d.retryDue()
+	}
+
+	delivery := deliveries.only(t)
+	if delivery.State != domain.DeliveryFailed || delivery.NextAttemptAt != nil {
+		t.Fatalf("after %d failures state = %s, next attempt = %v, want %s and none", maxAttempts, delivery.State, delivery.NextAttemptAt, domain.DeliveryFailed)
+	}
+	if len(delivery.Attempts) != maxAttempts {
+		t.Errorf("%d attempts recorded, want %d", len(delivery.Attempts), maxAttempts)
+	}
+	if got := delivery.Attempts[maxAttempts-1].Error; got != "unexpected status 500: Internal Server Error" {
+		t.Errorf("last attempt error = %q", got)
+	}
+
+	deliveries.makeDue()
+	d.retryDue()
+	if got := len(server.received()); got != maxAttempts {
+		t.Errorf("%d requests received, want %d", got, maxAttempts)
+	}
+}
+
+func TestDeliveryNotRetriedOnSuccess(t *testing.T) {
+	for _, status := range []int{http.StatusOK, http.StatusAccepted, http.StatusNoContent} {
+		t.Run(strconv.Itoa(status), func(t *testing.T) {
+			server := newReceiver(t, status)
+			d, webhooks, deliveries := newTestDispatcher(5)
+			webhooks.add(server.URL, "s3cret")
+
+			deliver(d)
+			delivery := deliveries.only(t)
+			if delivery.State != domain.DeliverySucceeded || delivery.NextAttemptAt != nil {
+				t.Fatalf("state = %s, next attempt = %v, want %s and none", delivery.State, delivery.NextAttemptAt, domain.DeliverySucceeded)
+			}
+			if len(delivery.Attempts) != 1 || delivery.Attempts[0].StatusCode != status || delivery.Attempts[0].Error != "" {
+				t.Errorf("attempts = %+v, want one answered %d", delivery.Attempts, status)
+			}
+
+			deliveries.makeDue()
+			d.retryDue()
+			if got := len(server.received()); got != 1 {
+				t.Errorf("%d requests received, want 1", got)
+			}
+		})
+	}
+}
+
+func TestTestDeliveryIsNotRetried(t *testing.T) {
+	server := newReceiver(t, http.StatusInternalServerError)
+	d, webhooks, deliveries := newTestDispatcher(5)
+	webhook := webhooks.add(server.URL, "s3cret")
+
+	delivery, err := d.Test(context.Background(), webhook)
+	if err != nil {
+		t.Fatalf("Test() error = %v", err)
+	}
+	if delivery.State != domain.DeliveryFailed || delivery.NextAttemptAt != nil {
+		t.Errorf("state = %s, next attempt = %v, want %s and none", delivery.State, delivery.NextAttemptAt, domain.DeliveryFailed)
+	}
+	if stored := deliveries.only(t); stored.State != domain.DeliveryFailed {
+		t.Errorf("stored state = %s, want %s", stored.State, domain.DeliveryFailed)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{attempts: 1, want: 30 * time.Second},
+		{attempts: 2, want: 2 * time.Minute},
+		{attempts: 3, want: 8 * time.Minute},
+		{attempts: 4, want: 32 * time.Minute},
+		{attempts: 5, want: 128 * time.Minute},
+		{attempts: 6, want: 6 * time.Hour},
+		{attempts: 20, want: 6 * time.Hour},
+	}
+	for _, tt := range tests {
+		if got := backoff(tt.attempts); got != tt.want {
+			t.Errorf("backoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
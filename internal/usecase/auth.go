@@ -0,0 +1,186 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"concall-analyser/internal/domain"
+	"concall-analyser/internal/middleware"
+	"concall-analyser/internal/service/auth"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type loginRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+type createUserRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+	Role     string `json:"role"`
+}
+
+type createAPIKeyRequest struct {
+	Name string `json:"name"`
+	Role string `json:"role"`
+}
+
+// LoginHandler exchanges an email and password for a signed session token
+func (cf *concallFetcher) LoginHandler(c *gin.Context) {
+	var req loginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid request body: %v", err)})
+		return
+	}
+	if req.Email == "" || req.Password == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "email and password are required"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	session, err := cf.authService.Login(ctx, req.Email, req.Password)
+	if err != nil {
+		if errors.Is(err, auth.ErrInvalidCredentials) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to log in",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	log.Printf("🔑 %s logged in as %s", session.User.Email, session.User.Role)
+	c.JSON(http.StatusOK, session)
+}
+
+// CurrentUserHandler returns who the request was authenticated as
+func (cf *concallFetcher) CurrentUserHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, middleware.CurrentPrincipal(c))
+}
+
+// CreateUserHandler adds a user who can log in with the given role
+func (cf *concallFetcher) CreateUserHandler(c *gin.Context) {
+	var req createUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid request body: %v", err)})
+		return
+	}
+	role, err := domain.ParseRole(req.Role)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	user, err := cf.authService.CreateUser(ctx, req.Email, req.Password, role)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserExists) {
+			c.JSON(http.StatusConflict, gin.H{"error": "a user with this email already exists"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to create user",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	log.Printf("👤 %s created %s user %s", middleware.CurrentPrincipal(c).Subject, user.Role, user.Email)
+	c.JSON(http.StatusCreated, user)
+}
+
+// CreateAPIKeyHandler generates an API key. The key is only ever returned here.
+func (cf *concallFetcher) CreateAPIKeyHandler(c *gin.Context) {
+	var req createAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid request body: %v", err)})
+		return
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name is required"})
+		return
+	}
+	role, err := domain.ParseRole(req.Role)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	createdBy := middleware.CurrentPrincipal(c).Subject
+	key, record, err := cf.authService.CreateAPIKey(ctx, name, role, createdBy)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to create API key",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	log.Printf("🔑 %s created %s API key %q (%s)", createdBy, record.Role, record.Name, record.Prefix)
+	c.JSON(http.StatusCreated, gin.H{
+		"key":     key,
+		"api_key": record,
+	})
+}
+
+// ListAPIKeysHandler lists API keys without the keys themselves
+func (cf *concallFetcher) ListAPIKeysHandler(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	keys, err := cf.authService.ListAPIKeys(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to list API keys",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": keys})
+}
+
+// RevokeAPIKeyHandler stops an API key from authenticating
+func (cf *concallFetcher) RevokeAPIKeyHandler(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid API key id"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := cf.authService.RevokeAPIKey(ctx, id); err != nil {
+		if errors.Is(err, domain.ErrAPIKeyNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "API key not found or already revoked"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to revoke API key",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	log.Printf("🔑 %s revoked API key %s", middleware.CurrentPrincipal(c).Subject, id.Hex())
+	c.JSON(http.StatusOK, gin.H{"id": id.Hex(), "revoked": true})
+}
@@ -2,6 +2,7 @@ package usecase
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"net/http"
 	"time"
@@ -13,100 +14,121 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// DuplicateGroup is a set of summaries that describe the same transcript
 type DuplicateGroup struct {
-	Name  string                  `bson:"_id"`
-	Docs  []domain.ConcallSummary `bson:"docs"`
-	Count int                     `bson:"count"`
+	Key  interface{} `bson:"_id"`
+	Docs []struct {
+		ID        primitive.ObjectID `bson:"id"`
+		CreatedAt time.Time          `bson:"created_at"`
+	} `bson:"docs"`
+	Count int `bson:"count"`
 }
 
 func (cf *concallFetcher) CleanupConcallHandler(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(context.Background(), 3600*time.Second)
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), 3600*time.Second)
 	defer cancel()
 
-	// Step 1: Delete all records with guidance == "NA"
-	naFilter := bson.M{"guidance": "NA"}
-	naDeletedCount, err := cf.repo.DeleteMany(ctx, naFilter)
+	// Cleanup deletes summaries, so it must not interleave with a fetch or reprocess inserting them
+	lease, err := cf.locker.Acquire(timeoutCtx, domain.LockIngestion, "cleanup")
 	if err != nil {
-		log.Printf("❌ Failed to delete NA guidance records: %v", err)
+		if respondLockHeld(c, err) {
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to delete NA guidance records",
+			"error":   "Failed to acquire the ingestion lock",
 			"details": err.Error(),
 		})
 		return
 	}
-	log.Printf("🗑️ Deleted %d records with guidance='NA'", naDeletedCount)
+	defer lease.Release()
+
+	ctx, cancelCause := context.WithCancelCause(timeoutCtx)
+	defer cancelCause(nil)
+	go watchLease(ctx, lease, cancelCause)
+
+	// Step 1: Summaries without guidance ("NA") are kept unless delete_na=true asks
+	// for the old behaviour: they mark the transcript as done so the next fetch
+	// does not summarize it again, and reprocessing with na_only looks for them.
+	var naDeletedCount int64
+	if c.Query("delete_na") == "true" {
+		naDeletedCount, err = cf.repo.DeleteMany(ctx, bson.M{"guidance": domain.NoGuidance})
+		if err != nil {
+			log.Printf("❌ Failed to delete NA guidance records: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to delete NA guidance records",
+				"details": err.Error(),
+			})
+			return
+		}
+		log.Printf("🗑️ Deleted %d records with guidance='NA'", naDeletedCount)
+	}
 
-	// Step 2: Find and delete duplicates based on name field
-	pipeline := []bson.M{
+	// Step 2: Delete duplicate summaries of the same transcript. Summaries carrying
+	// a BSE NewsID are grouped by it; older ones without it fall back to name +
+	// date, so different quarters of the same company are kept.
+	groupings := []struct {
+		label string
+		match bson.M
+		key   interface{}
+	}{
 		{
-			"$group": bson.M{
-				"_id": "$name",
-				"docs": bson.M{
-					"$push": "$$ROOT",
-				},
-				"count": bson.M{"$sum": 1},
-			},
+			label: "news_id",
+			match: bson.M{"news_id": bson.M{"$type": "string", "$ne": ""}},
+			key:   "$news_id",
 		},
 		{
-			"$match": bson.M{
-				"count": bson.M{"$gt": 1},
-			},
+			label: "name+date",
+			match: bson.M{"$or": []bson.M{{"news_id": bson.M{"$exists": false}}, {"news_id": ""}}},
+			key:   bson.M{"name": "$name", "date": "$date"},
 		},
 	}
 
-	cursor, err := cf.repo.Aggregate(ctx, pipeline)
-	if err != nil {
-		log.Printf("❌ Failed to find duplicates: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to find duplicates",
-			"details": err.Error(),
-		})
-		return
-	}
-	defer cursor.Close(ctx)
-
-	var duplicateGroups []DuplicateGroup
-	if err := cursor.All(ctx, &duplicateGroups); err != nil {
-		log.Printf("❌ Failed to decode duplicate groups: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to decode duplicate groups",
-			"details": err.Error(),
-		})
-		return
-	}
-
 	duplicateDeletedCount := int64(0)
-	duplicateNamesProcessed := 0
+	duplicateGroupsProcessed := 0
 
-	for _, group := range duplicateGroups {
-		if len(group.Docs) <= 1 {
-			continue
+	for _, grouping := range groupings {
+		duplicateGroups, err := cf.findDuplicateGroups(ctx, grouping.match, grouping.key)
+		if err != nil {
+			log.Printf("❌ Failed to find duplicates by %s: %v", grouping.label, err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to find duplicates",
+				"details": err.Error(),
+			})
+			return
 		}
 
-		var keepID primitive.ObjectID
-		var latestTime time.Time
+		for _, group := range duplicateGroups {
+			if len(group.Docs) <= 1 {
+				continue
+			}
+
+			var keepID primitive.ObjectID
+			var latestTime time.Time
 
-		for _, doc := range group.Docs {
-			if doc.CreatedAt.After(latestTime) || latestTime.IsZero() {
-				latestTime = doc.CreatedAt
-				keepID = doc.ID
+			for _, doc := range group.Docs {
+				if doc.CreatedAt.After(latestTime) || latestTime.IsZero() {
+					latestTime = doc.CreatedAt
+					keepID = doc.ID
+				}
 			}
-		}
 
-		deleteFilter := bson.M{
-			"name": group.Name,
-			"_id":  bson.M{"$ne": keepID},
-		}
+			deleteIDs := make([]primitive.ObjectID, 0, len(group.Docs)-1)
+			for _, doc := range group.Docs {
+				if doc.ID != keepID {
+					deleteIDs = append(deleteIDs, doc.ID)
+				}
+			}
 
-		deleted, err := cf.repo.DeleteMany(ctx, deleteFilter)
-		if err != nil {
-			log.Printf("⚠️ Failed to delete duplicates for name '%s': %v", group.Name, err)
-			continue
-		}
+			deleted, err := cf.repo.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": deleteIDs}})
+			if err != nil {
+				log.Printf("⚠️ Failed to delete duplicates for %s %v: %v", grouping.label, group.Key, err)
+				continue
+			}
 
-		duplicateDeletedCount += deleted
-		duplicateNamesProcessed++
-		log.Printf("🗑️ Deleted %d duplicate(s) for name '%s' (kept most recent)", deleted, group.Name)
+			duplicateDeletedCount += deleted
+			duplicateGroupsProcessed++
+			log.Printf("🗑️ Deleted %d duplicate(s) for %s %v (kept most recent)", deleted, grouping.label, group.Key)
+		}
 	}
 
 	totalDeleted := naDeletedCount + duplicateDeletedCount
@@ -117,11 +139,44 @@ func (cf *concallFetcher) CleanupConcallHandler(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Cleanup completed successfully",
 		"summary": gin.H{
-			"naGuidanceDeleted":       naDeletedCount,
-			"duplicatesDeleted":       duplicateDeletedCount,
-			"duplicateNamesProcessed": duplicateNamesProcessed,
-			"totalDeleted":            totalDeleted,
+			"naGuidanceDeleted":        naDeletedCount,
+			"duplicatesDeleted":        duplicateDeletedCount,
+			"duplicateGroupsProcessed": duplicateGroupsProcessed,
+			"totalDeleted":             totalDeleted,
 		},
 	})
 }
 
+// findDuplicateGroups groups the summaries matching filter by key and returns
+// the groups holding more than one document
+func (cf *concallFetcher) findDuplicateGroups(ctx context.Context, match bson.M, key interface{}) ([]DuplicateGroup, error) {
+	pipeline := []bson.M{
+		{"$match": match},
+		{
+			"$group": bson.M{
+				"_id": key,
+				"docs": bson.M{
+					"$push": bson.M{"id": "$_id", "created_at": "$created_at"},
+				},
+				"count": bson.M{"$sum": 1},
+			},
+		},
+		{
+			"$match": bson.M{
+				"count": bson.M{"$gt": 1},
+			},
+		},
+	}
+
+	cursor, err := cf.repo.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var duplicateGroups []DuplicateGroup
+	if err := cursor.All(ctx, &duplicateGroups); err != nil {
+		return nil, fmt.Errorf("failed to decode duplicate groups: %w", err)
+	}
+	return duplicateGroups, nil
+}
@@ -0,0 +1,131 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"concall-analyser/internal/domain"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// companyProfileInterval spaces out company lookups so a backfill does not hammer BSE
+const companyProfileInterval = 250 * time.Millisecond
+
+// ListSectorsHandler lists the sectors of the companies with stored concalls,
+// for the sector filter of list_concalls
+func (cf *concallFetcher) ListSectorsHandler(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	sectors, err := cf.companyRepo.Sectors(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to list sectors",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": sectors})
+}
+
+// SyncCompanies makes every company with stored concalls searchable and reads
+// the profile of those that do not have one yet from BSE
+func (cf *concallFetcher) SyncCompanies(ctx context.Context) error {
+	backfilled, err := cf.companyRepo.BackfillSearchKeys(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to backfill company search keys: %w", err)
+	}
+	if backfilled > 0 {
+		log.Printf("🔎 Indexed %d companies for search", backfilled)
+	}
+
+	companies, err := cf.findCompanies(ctx, bson.M{"scrip_code": bson.M{"$gt": 0}}, 0)
+	if err != nil {
+		return fmt.Errorf("failed to list companies: %w", err)
+	}
+	cf.profileCompanies(ctx, companies)
+	return nil
+}
+
+// profileCompaniesOf reads the profiles of the companies a job stored summaries
+// for in the background
+func (cf *concallFetcher) profileCompaniesOf(job *domain.FetchJob) {
+	seen := make(map[int]bool)
+	var companies []companyMatch
+	for _, summary := range job.Summaries {
+		if summary.ScripCode > 0 && !seen[summary.ScripCode] {
+			seen[summary.ScripCode] = true
+			companies = append(companies, companyMatch{ScripCode: summary.ScripCode, Name: summary.Name})
+		}
+	}
+	if len(companies) == 0 {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+		defer cancel()
+		cf.profileCompanies(ctx, companies)
+	}()
+}
+
+// profileCompanies stores the companies that are new and looks up those without
+// a profile on BSE. A company BSE cannot describe keeps its name only and is
+// looked up again next time.
+func (cf *concallFetcher) profileCompanies(ctx context.Context, companies []companyMatch) {
+	codes := make([]int, len(companies))
+	known := make([]domain.Company, len(companies))
+	for i, company := range companies {
+		codes[i] = company.ScripCode
+		known[i] = domain.Company{ScripCode: company.ScripCode, Name: company.Name}
+	}
+
+	// Store new companies by name first so they can be searched while BSE is read
+	if err := cf.companyRepo.InsertMissing(ctx, known); err != nil {
+		log.Printf("⚠️ Failed to store companies: %v", err)
+	}
+	profiled, err := cf.companyRepo.FindProfiled(ctx, codes)
+	if err != nil {
+		log.Printf("⚠️ Failed to find profiled companies: %v", err)
+		return
+	}
+
+	fetched, failed := 0, 0
+	for _, company := range companies {
+		if profiled[company.ScripCode] {
+			continue
+		}
+		if fetched+failed > 0 {
+			select {
+			case <-time.After(companyProfileInterval):
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		profile, err := cf.bseClient.FetchCompany(ctx, company.ScripCode)
+		if err != nil {
+			log.Printf("⚠️ Failed to read the BSE profile of %s (%d): %v", company.Name, company.ScripCode, err)
+			profile = &domain.Company{ScripCode: company.ScripCode}
+			failed++
+		} else {
+			fetched++
+		}
+		if profile.Name == "" {
+			profile.Name = company.Name
+		}
+		if err := cf.companyRepo.Upsert(ctx, profile); err != nil {
+			log.Printf("⚠️ Failed to store company %d: %v", company.ScripCode, err)
+		}
+	}
+
+	if fetched+failed > 0 {
+		log.Printf("🏢 Read %d company profiles from BSE, %d failed", fetched, failed)
+	}
+}
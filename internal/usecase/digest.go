@@ -0,0 +1,234 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"concall-analyser/internal/domain"
+	"concall-analyser/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// maxPreviewHours bounds how far back a digest preview without a job looks
+	maxPreviewHours = 30 * 24
+	// maxPreviewSummaries caps the summaries a digest preview renders
+	maxPreviewSummaries = 500
+)
+
+type digestSubscriptionRequest struct {
+	WatchlistID string `json:"watchlist_id"`
+}
+
+// GetDigestSubscriptionHandler returns the current user's digest subscription
+func (cf *concallFetcher) GetDigestSubscriptionHandler(c *gin.Context) {
+	email, ok := digestEmail(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	subscription, err := cf.digestRepo.FindByEmail(ctx, email)
+	if err != nil {
+		writeDigestError(c, "Failed to fetch digest subscription", err)
+		return
+	}
+	c.JSON(http.StatusOK, subscription)
+}
+
+// UpdateDigestSubscriptionHandler subscribes the current user to the digest,
+// or changes the watchlist it is limited to. An empty watchlist_id asks for all
+// new guidance.
+func (cf *concallFetcher) UpdateDigestSubscriptionHandler(c *gin.Context) {
+	email, ok := digestEmail(c)
+	if !ok {
+		return
+	}
+
+	var req digestSubscriptionRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid request body: %v", err)})
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	subscription := &domain.DigestSubscription{Email: email}
+	if watchlistID := strings.TrimSpace(req.WatchlistID); watchlistID != "" {
+		watchlist, ok := cf.ownWatchlist(ctx, c, watchlistID)
+		if !ok {
+			return
+		}
+		subscription.WatchlistID = watchlist.ID.Hex()
+	}
+
+	if err := cf.digestRepo.Upsert(ctx, subscription); err != nil {
+		writeDigestError(c, "Failed to save digest subscription", err)
+		return
+	}
+
+	log.Printf("📧 %s subscribed to the digest", email)
+	c.JSON(http.StatusOK, subscription)
+}
+
+// DeleteDigestSubscriptionHandler unsubscribes the current user from the digest
+func (cf *concallFetcher) DeleteDigestSubscriptionHandler(c *gin.Context) {
+	email, ok := digestEmail(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := cf.digestRepo.Delete(ctx, email); err != nil {
+		writeDigestError(c, "Failed to delete digest subscription", err)
+		return
+	}
+
+	log.Printf("📧 %s unsubscribed from the digest", email)
+	c.JSON(http.StatusOK, gin.H{"email": email, "deleted": true})
+}
+
+// PreviewDigestHandler renders the digest of a job's summaries, or of those
+// stored in the last `hours` (default 24), as the email would show it. The
+// format is html (default), text or json for the subject and both bodies.
+func (cf *concallFetcher) PreviewDigestHandler(c *gin.Context) {
+	format := strings.ToLower(c.DefaultQuery("format", "html"))
+	if format != "html" && format != "text" && format != "json" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("invalid 'format' %q, expected html, text or json", format),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var watchlist *domain.Watchlist
+	if watchlistID := c.Query("watchlist_id"); watchlistID != "" {
+		var ok bool
+		if watchlist, ok = cf.ownWatchlist(ctx, c, watchlistID); !ok {
+			return
+		}
+	}
+
+	var summaries []domain.ConcallSummary
+	if jobIDStr := c.Query("job_id"); jobIDStr != "" {
+		jobID, err := primitive.ObjectIDFromHex(jobIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid job id"})
+			return
+		}
+		job, err := cf.jobRepo.FindByID(ctx, jobID)
+		if errors.Is(err, domain.ErrJobNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "job not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to fetch job",
+				"details": err.Error(),
+			})
+			return
+		}
+		summaries = job.Summaries
+	} else {
+		hours, err := strconv.Atoi(c.DefaultQuery("hours", "24"))
+		if err != nil || hours <= 0 || hours > maxPreviewHours {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": fmt.Sprintf("invalid 'hours', expected 1 to %d", maxPreviewHours),
+			})
+			return
+		}
+		filter := bson.M{
+			"created_at": bson.M{"$gte": time.Now().Add(-time.Duration(hours) * time.Hour)},
+			"guidance":   bson.M{"$ne": "NA"},
+		}
+		findOpts := options.Find().
+			SetSort(bson.D{{Key: "created_at", Value: -1}}).
+			SetLimit(maxPreviewSummaries)
+		summaries, err = cf.repo.FindSummaries(ctx, filter, findOpts)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to query concalls",
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+
+	email, err := cf.digests.Compose(summaries, watchlist)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to render digest",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	switch format {
+	case "text":
+		c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(email.Text))
+	case "json":
+		c.JSON(http.StatusOK, email)
+	default:
+		c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(email.HTML))
+	}
+}
+
+// sendDigest mails the summaries a fetch or retry job stored to the digest
+// subscribers. Reprocessing does not produce new guidance, so it sends nothing.
+func (cf *concallFetcher) sendDigest(job *domain.FetchJob) {
+	if !cf.digests.Enabled() || len(job.Summaries) == 0 {
+		return
+	}
+	if kind := jobKind(*job); kind != domain.JobKindFetch && kind != domain.JobKindRetry {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+		defer cancel()
+		if err := cf.digests.SendRun(ctx, job.Summaries); err != nil {
+			log.Printf("❌ Failed to send digests for job %s: %v", job.ID.Hex(), err)
+		}
+	}()
+}
+
+// digestEmail returns the email of the logged-in user, writing the error
+// response when the caller is not a user. API keys have no address to mail.
+func digestEmail(c *gin.Context) (string, bool) {
+	principal := middleware.CurrentPrincipal(c)
+	if principal.Method != "session" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "digest subscriptions need a user login, not an API key"})
+		return "", false
+	}
+	return principal.Subject, true
+}
+
+func writeDigestError(c *gin.Context, message string, err error) {
+	if errors.Is(err, domain.ErrDigestSubscriptionNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "not subscribed to the digest"})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"error":   message,
+		"details": err.Error(),
+	})
+}
@@ -0,0 +1,109 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"concall-analyser/internal/domain"
+	"concall-analyser/internal/infrastructure/file"
+	"concall-analyser/internal/service/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DownloadDocumentHandler streams the archived transcript PDF of a concall, looked
+// up by its BSE NewsID
+func (cf *concallFetcher) DownloadDocumentHandler(c *gin.Context) {
+	newsID := c.Param("news_id")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	doc, err := cf.documentRepo.FindByNewsID(ctx, newsID)
+	if err != nil {
+		if errors.Is(err, domain.ErrDocumentNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "no archived document for this concall"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to fetch document",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if doc.Backend != cf.archive.Backend() {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": fmt.Sprintf("document is archived in %s storage, but this server uses %s", doc.Backend, cf.archive.Backend()),
+		})
+		return
+	}
+
+	reader, err := cf.archive.Get(ctx, doc.Key)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			log.Printf("⚠️ Document %s is recorded but missing from the archive", doc.ID)
+			c.JSON(http.StatusNotFound, gin.H{"error": "archived file is missing"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to read document",
+			"details": err.Error(),
+		})
+		return
+	}
+	defer reader.Close()
+
+	filename := doc.AttachmentName
+	if filename == "" {
+		filename = doc.ID + ".pdf"
+	}
+
+	c.DataFromReader(http.StatusOK, doc.Size, doc.ContentType, reader, map[string]string{
+		"Content-Disposition": fmt.Sprintf(`inline; filename="%s"`, filename),
+		"ETag":                `"` + doc.ID + `"`,
+	})
+}
+
+// restoreDocument copies the archived PDF of a NewsID into DestDir, returning the
+// document and the path of the copy. The caller removes the file.
+func (cf *concallFetcher) restoreDocument(ctx context.Context, newsID string) (*domain.Document, string, error) {
+	doc, err := cf.documentRepo.FindByNewsID(ctx, newsID)
+	if err != nil {
+		return nil, "", err
+	}
+	if doc.Backend != cf.archive.Backend() {
+		return nil, "", fmt.Errorf("document %s is archived in %s storage, but this server uses %s", doc.ID, doc.Backend, cf.archive.Backend())
+	}
+
+	reader, err := cf.archive.Get(ctx, doc.Key)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read archived document %s: %w", doc.ID, err)
+	}
+	defer reader.Close()
+
+	if err := file.CreateDirectory(cf.cfg.DestDir); err != nil {
+		return nil, "", err
+	}
+
+	path := filepath.Join(cf.cfg.DestDir, fmt.Sprintf("%s_%s.pdf", newsID, doc.ID))
+	out, err := os.Create(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create file: %w", err)
+	}
+	defer out.Close()
+
+	if _, err := io.Copy(out, reader); err != nil {
+		removeTempFile(path)
+		return nil, "", fmt.Errorf("failed to restore document %s: %w", doc.ID, err)
+	}
+
+	return doc, path, nil
+}
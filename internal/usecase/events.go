@@ -0,0 +1,39 @@
+package usecase
+
+import (
+	"time"
+
+	"concall-analyser/internal/domain"
+	ws "concall-analyser/internal/websocket"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// publish sends an ingestion event to the subscribers of the ingestion topic
+// and, when scripCode is set, of that company's topic and the watchlists
+// following it, if a hub is attached
+func (cf *concallFetcher) publish(eventType string, scripCode int, event interface{}) {
+	if cf.hub == nil {
+		return
+	}
+	cf.hub.Publish(cf.ingestionTopics(scripCode), eventType, event)
+}
+
+func (cf *concallFetcher) publishRunStarted(jobID primitive.ObjectID, total int) {
+	cf.publish(ws.EventRunStarted, 0, ws.RunStarted{
+		JobID:     jobID.Hex(),
+		Total:     total,
+		StartedAt: time.Now(),
+	})
+}
+
+func (cf *concallFetcher) publishRunFinished(job *domain.FetchJob, state domain.JobState, errs []string) {
+	cf.publish(ws.EventRunFinished, 0, ws.RunFinished{
+		JobID:      job.ID.Hex(),
+		Kind:       jobKind(*job),
+		State:      state,
+		Totals:     job.Progress,
+		Errors:     errs,
+		FinishedAt: time.Now(),
+	})
+}
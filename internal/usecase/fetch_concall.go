@@ -5,22 +5,20 @@ import (
 	"fmt"
 	"log"
 	"net/http"
-	"os"
-	"strings"
 	"time"
 
 	"concall-analyser/internal/domain"
 	"concall-analyser/internal/infrastructure/file"
-	"concall-analyser/internal/service/gemini"
+	"concall-analyser/internal/service/llm/provider"
 
 	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// FetchConcallDataHandler submits a fetch job for the requested date range and
+// returns immediately; progress is exposed through GET /api/jobs/:id
 func (cf *concallFetcher) FetchConcallDataHandler(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(context.Background(), 3600*time.Second)
-	defer cancel()
-
 	// Parse date parameters
 	fromDateStr := c.Query("from")
 	toDateStr := c.Query("to")
@@ -56,23 +54,69 @@ func (cf *concallFetcher) FetchConcallDataHandler(c *gin.Context) {
 		return
 	}
 
+	// Optional fiscal years to extract guidance for, e.g. fy=FY27,FY28
+	var fiscalYears []string
+	if fyStr := c.Query("fy"); fyStr != "" {
+		fiscalYears, err = domain.ParseFiscalYears(fyStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid 'fy': %v", err)})
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	job, _, err := cf.submitFetchJob(ctx, fromDate, toDate, fiscalYears)
+	if err != nil {
+		if respondLockHeld(c, err) {
+			return
+		}
+		log.Printf("❌ Failed to submit fetch job: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to submit fetch job",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message":    "Fetch job submitted",
+		"job_id":     job.ID.Hex(),
+		"state":      job.State,
+		"status_url": "/api/jobs/" + job.ID.Hex(),
+	})
+}
+
+// runFetchJob runs the fetch + summarize pipeline for a job, recording its
+// progress and outcome on the job document as it goes
+func (cf *concallFetcher) runFetchJob(ctx context.Context, jobID primitive.ObjectID, fromDate, toDate time.Time, fiscalYears []string) {
+	startedAt := time.Now()
+	cf.updateJob(jobID, bson.M{"state": domain.JobRunning, "started_at": startedAt})
+
 	// Fetch announcements
-	announcements, err := cf.bseClient.FetchAnnouncements(ctx, fromDate, toDate)
+	fetchResult, err := cf.bseClient.FetchAnnouncements(ctx, fromDate, toDate)
 	if err != nil {
 		log.Printf("Failed to fetch announcements: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to fetch announcements: %v", err)})
+		cf.finishJob(ctx, jobID, fmt.Errorf("failed to fetch announcements: %w", err))
 		return
 	}
+	announcements := fetchResult.Announcements
 
-	log.Printf("📊 Found %d announcements from API", len(announcements))
+	log.Printf("📊 Found %d announcements from API (pages %d/%d, rows %d/%d)",
+		len(announcements), fetchResult.PagesFetched, fetchResult.PagesExpected,
+		fetchResult.RowsSeen, fetchResult.RowsExpected)
+
+	fields := bson.M{"fetch": fetchResult.FetchStats}
+	if !fetchResult.Complete() {
+		fields["errors"] = []string{fmt.Sprintf("incomplete BSE fetch: pages %d/%d, rows %d/%d",
+			fetchResult.PagesFetched, fetchResult.PagesExpected, fetchResult.RowsSeen, fetchResult.RowsExpected)}
+	}
+	cf.updateJob(jobID, fields)
 
 	if len(announcements) == 0 {
 		log.Printf("⚠️ No announcements found for the given date range")
-		c.JSON(http.StatusOK, gin.H{
-			"message":   "No announcements found for the given date range",
-			"count":     0,
-			"summaries": []domain.ConcallSummary{},
-		})
+		cf.finishJob(ctx, jobID, nil)
 		return
 	}
 
@@ -80,20 +124,12 @@ func (cf *concallFetcher) FetchConcallDataHandler(c *gin.Context) {
 	filteredAnnouncements, err := cf.filterNewAnnouncements(ctx, announcements)
 	if err != nil {
 		log.Printf("❌ Failed to filter announcements: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to filter announcements: %v", err)})
+		cf.finishJob(ctx, jobID, fmt.Errorf("failed to filter announcements: %w", err))
 		return
 	}
 
 	log.Printf("🆕 %d new announcements to process (out of %d total)", len(filteredAnnouncements), len(announcements))
 
-	if len(filteredAnnouncements) == 0 {
-		c.JSON(http.StatusOK, gin.H{
-			"message": "All announcements already processed",
-			"count":   0,
-		})
-		return
-	}
-
 	// Count announcements with PDFs
 	pdfCount := 0
 	for _, a := range announcements {
@@ -103,173 +139,94 @@ func (cf *concallFetcher) FetchConcallDataHandler(c *gin.Context) {
 	}
 	log.Printf("📄 Found %d announcements with PDFs out of %d total", pdfCount, len(announcements))
 
+	cf.processAndStore(ctx, jobID, filteredAnnouncements, fiscalYears)
+}
+
+// processAndStore runs announcements through the download + summarize pipeline
+// as items of a job, stores the summaries and finishes the job
+func (cf *concallFetcher) processAndStore(ctx context.Context, jobID primitive.ObjectID, filteredAnnouncements []domain.Announcement, fiscalYears []string) {
+	items := make([]domain.JobItem, 0, len(filteredAnnouncements))
+	for _, a := range filteredAnnouncements {
+		items = append(items, domain.JobItem{
+			NewsID:         a.NewsID,
+			Name:           a.ShortLongName,
+			AttachmentName: a.AttachmentName,
+			Status:         domain.ItemPending,
+			UpdatedAt:      time.Now(),
+		})
+	}
+	cf.updateJob(jobID, bson.M{"items": items, "progress": domain.JobProgress{Total: len(items)}})
+
+	if len(filteredAnnouncements) == 0 {
+		log.Printf("✅ All announcements already processed")
+		cf.finishJob(ctx, jobID, nil)
+		return
+	}
+
+	cf.ledgerEnqueue(jobID, filteredAnnouncements)
+	cf.runPipeline(ctx, jobID, filteredAnnouncements, fiscalYears)
+}
+
+// runPipeline processes announcements that are already items of the job. Each
+// summary is stored as soon as it is produced, so an interrupted job loses at
+// most the announcements that were in flight.
+func (cf *concallFetcher) runPipeline(ctx context.Context, jobID primitive.ObjectID, announcements []domain.Announcement, fiscalYears []string) {
 	// Create destination directory
 	if err := file.CreateDirectory(cf.cfg.DestDir); err != nil {
 		log.Printf("Failed to create directory: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to create directory: %v", err)})
+		cf.finishJob(ctx, jobID, fmt.Errorf("failed to create directory: %w", err))
 		return
 	}
 
-	// Initialize Gemini client
-	geminiClient, err := gemini.NewGeminiClient(ctx, cf.cfg.APIKey)
+	// Initialize the configured summarizer
+	summarizer, err := provider.New(ctx, cf.cfg, cf.httpClient)
 	if err != nil {
-		log.Printf("Failed to initialize Gemini client: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to initialize Gemini client: %v", err)})
+		log.Printf("Failed to initialize %s summarizer: %v", cf.cfg.LLMProvider, err)
+		cf.finishJob(ctx, jobID, fmt.Errorf("failed to initialize %s summarizer: %w", cf.cfg.LLMProvider, err))
 		return
 	}
-	defer geminiClient.Close()
+	defer summarizer.Close()
 
 	// Process announcements
-	log.Printf("🚀 Starting to process %d announcements...", len(filteredAnnouncements))
-	summaries := cf.processAnnouncementsSequentially(ctx, geminiClient, filteredAnnouncements)
-	log.Printf("✅ Finished processing. Got %d summaries", len(summaries))
-
-	// Store summaries in MongoDB
-	if len(summaries) > 0 {
-		if err := cf.repo.InsertMany(ctx, summaries); err != nil {
-			log.Printf("Failed to save summaries to MongoDB: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error":   fmt.Sprintf("Failed to save summaries to MongoDB: %v", err),
-				"summary": "Processed but failed to save",
-				"count":   len(summaries),
-			})
-			return
-		}
-		log.Printf("✅ Successfully inserted %d summaries to MongoDB", len(summaries))
-	} else {
-		log.Printf("⚠️ No summaries to save (all announcements may have been skipped)")
-	}
+	log.Printf("🚀 Starting to process %d announcements...", len(announcements))
+	cf.publishRunStarted(jobID, len(announcements))
+	summaries := cf.processAnnouncementsConcurrently(ctx, jobID, summarizer, announcements, fiscalYears)
+	log.Printf("✅ Finished processing. Stored %d summaries", len(summaries))
 
-	c.JSON(http.StatusOK, gin.H{
-		"message":   "Announcements processed and saved successfully",
-		"count":     len(summaries),
-		"summaries": summaries,
-	})
+	cf.finishJob(ctx, jobID, nil)
 }
 
+// filterNewAnnouncements drops announcements whose transcript (BSE NewsID) already
+// has a summary, so every new quarter of a known company is still processed
 func (cf *concallFetcher) filterNewAnnouncements(ctx context.Context, announcements []domain.Announcement) ([]domain.Announcement, error) {
 	if len(announcements) == 0 {
 		return []domain.Announcement{}, nil
 	}
 
-	names := make([]string, 0, len(announcements))
+	newsIDs := make([]string, 0, len(announcements))
 	for _, a := range announcements {
-		names = append(names, a.ShortLongName)
+		if a.NewsID != "" {
+			newsIDs = append(newsIDs, a.NewsID)
+		}
 	}
 
-	existingNames, err := cf.repo.FindExistingNames(ctx, names)
+	existingIDs, err := cf.repo.FindExistingNewsIDs(ctx, newsIDs)
 	if err != nil {
 		return nil, err
 	}
 
 	filtered := make([]domain.Announcement, 0, len(announcements))
 	for _, a := range announcements {
-		if !existingNames[a.ShortLongName] {
+		if !existingIDs[a.NewsID] {
 			filtered = append(filtered, a)
 		} else {
-			log.Printf("🗑️ Skipping existing announcement: %s", a.ShortLongName)
+			log.Printf("🗑️ Skipping existing announcement: %s (NewsID: %s)", a.ShortLongName, a.NewsID)
 		}
 	}
 
 	return filtered, nil
 }
 
-func (cf *concallFetcher) processAnnouncementsSequentially(
-	ctx context.Context,
-	geminiClient gemini.GeminiClient,
-	announcements []domain.Announcement,
-) []domain.ConcallSummary {
-	results := make([]domain.ConcallSummary, 0)
-	skippedCount := 0
-	errorCount := 0
-
-	log.Printf("⚙️ Starting sequential processing of %d announcements...", len(announcements))
-
-	for i, a := range announcements {
-		log.Printf("🔹 [%d/%d] Processing: %s", i+1, len(announcements), a.ShortLongName)
-
-		summary, err := cf.processAnnouncement(ctx, geminiClient, a)
-
-		if err != nil {
-			log.Printf("❌ Error processing announcement %s (PDFFlag: %d, Attachment: %s): %v",
-				a.ShortLongName, a.PDFFlag, a.AttachmentName, err)
-			errorCount++
-			continue
-		}
-
-		if summary != nil {
-			// Remove "-$" suffix from name if present
-			if strings.HasSuffix(summary.Name, "-$") {
-				summary.Name = strings.TrimSuffix(summary.Name, "-$")
-			}
-
-			results = append(results, *summary)
-			log.Printf("✅ Processed successfully: %s", a.ShortLongName)
-		} else {
-			skippedCount++
-			log.Printf("⏭️ Skipped announcement: %s (PDFFlag: %d, Attachment: %s)",
-				a.ShortLongName, a.PDFFlag, a.AttachmentName)
-		}
-
-		time.Sleep(1 * time.Second)
-	}
-
-	log.Printf("📈 Processing complete - Success: %d, Skipped: %d, Errors: %d",
-		len(results), skippedCount, errorCount)
-
-	return results
-}
-
-func (cf *concallFetcher) processAnnouncement(ctx context.Context, geminiClient gemini.GeminiClient, a domain.Announcement) (*domain.ConcallSummary, error) {
-	if a.AttachmentName == "" {
-		log.Printf("⏭️ Skipping announcement AttachmentName='%s'", a.ShortLongName)
-		return nil, nil
-	}
-
-	datePart := strings.Split(a.NewsDate, "T")[0]
-	companyPart := file.SanitizeFileName(a.ShortLongName)
-	saveAs := fmt.Sprintf("%s_%s.pdf", companyPart, datePart)
-
-	log.Printf("📥 Downloading PDF: %s (from %s)", saveAs, a.AttachmentName)
-	path, err := cf.pdfDownloader.Download(ctx, a.AttachmentName, cf.cfg.DestDir, saveAs)
-	if err != nil {
-		return nil, fmt.Errorf("download error for %s: %w", saveAs, err)
-	}
-
-	fileInfo, err := os.Stat(path)
-	if err != nil {
-		return nil, fmt.Errorf("file stat error for %s: %w", path, err)
-	}
-	if fileInfo.Size() == 0 {
-		return nil, fmt.Errorf("PDF file is empty at %s", path)
-	}
-
-	log.Printf("✅ PDF saved to %s (size: %d bytes)", path, fileInfo.Size())
-
-	defer func() {
-		if err := os.Remove(path); err != nil {
-			log.Printf("⚠️ Warning: failed to remove temp file %s: %v", path, err)
-		}
-	}()
-
-	log.Printf("🤖 Uploading and summarizing PDF: %s", saveAs)
-	summary, err := geminiClient.SummarizePDF(ctx, path)
-	if err != nil {
-		return nil, fmt.Errorf("summarization error for %s: %w", saveAs, err)
-	}
-	log.Printf("✅ Summary generated for %s:", saveAs)
-
-	concallSummary := &domain.ConcallSummary{
-		ID:        primitive.NewObjectID(),
-		Name:      a.ShortLongName,
-		Date:      datePart,
-		Guidance:  summary,
-		CreatedAt: time.Now(),
-	}
-
-	return concallSummary, nil
-}
-
 // parseHumanReadableDate parses a human-readable date string into time.Time
 func parseHumanReadableDate(dateStr string) (time.Time, error) {
 	formats := []string{
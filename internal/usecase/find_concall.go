@@ -8,11 +8,18 @@ import (
 	"strings"
 	"time"
 
+	"concall-analyser/internal/domain"
+	"concall-analyser/internal/service/search"
+
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// FindConcallHandler finds the concalls of the companies matching a name,
+// symbol or scrip code, the best matching company first and each company's
+// latest concall first. Concalls stored without a scrip code are only found
+// when no company matches.
 func (cf *concallFetcher) FindConcallHandler(c *gin.Context) {
 	rawName := c.Query("name")
 	if strings.TrimSpace(rawName) == "" {
@@ -38,41 +45,69 @@ func (cf *concallFetcher) FindConcallHandler(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(context.Background(), 3600*time.Second)
 	defer cancel()
 
-	escaped := regexp.QuoteMeta(name)
-	filter := bson.M{
-		"name": bson.M{
-			"$regex":   escaped,
-			"$options": "i",
-		},
+	companies, err := cf.searchCompanies(ctx, name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to search companies", "details": err.Error()})
+		return
 	}
 
 	projection := bson.M{
-		"name":     1,
-		"date":     1,
-		"guidance": 1,
-		"_id":      0,
+		"name":           1,
+		"date":           1,
+		"guidance":       1,
+		"guidance_items": 1,
+		"guidance_by_fy": 1,
+		"news_id":        1,
+		"document_id":    1,
+		"scrip_code":     1,
+		"quarter_id":     1,
+		"_id":            0,
 	}
 
-	findOpts := options.Find().
-		SetProjection(projection).
-		SetSort(bson.D{{Key: "date", Value: -1}}).
-		SetSkip(skip).
-		SetLimit(limit64)
-
-	totalCount, err := cf.repo.CountDocuments(ctx, filter)
+	var filter bson.M
+	var results []domain.ConcallLite
+	if len(companies) > 0 {
+		codes := make([]int, len(companies))
+		for i, company := range companies {
+			codes[i] = company.ScripCode
+		}
+		filter = bson.M{"scrip_code": bson.M{"$in": codes}}
+		results, err = cf.findRankedConcalls(ctx, filter, codes, projection, skip, limit64)
+	} else {
+		filter = bson.M{
+			"name": bson.M{
+				"$regex":   regexp.QuoteMeta(name),
+				"$options": "i",
+			},
+		}
+		findOpts := options.Find().
+			SetProjection(projection).
+			SetSort(bson.D{{Key: "date", Value: -1}}).
+			SetSkip(skip).
+			SetLimit(limit64)
+		results, err = cf.repo.FindWithFilter(ctx, filter, findOpts)
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to count documents", "details": err.Error()})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to query MongoDB", "details": err.Error()})
 		return
 	}
 
-	results, err := cf.repo.FindWithFilter(ctx, filter, findOpts)
+	totalCount, err := cf.repo.CountDocuments(ctx, filter)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to query MongoDB", "details": err.Error()})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to count documents", "details": err.Error()})
 		return
 	}
 
 	totalPages := (totalCount + int64(limit) - 1) / int64(limit)
 
+	// Remove "-$" suffix from names if present
+	for i := range results {
+		results[i].Name = strings.TrimSuffix(results[i].Name, "-$")
+	}
+	if companies == nil {
+		companies = []search.Result{}
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"meta": gin.H{
 			"query":      name,
@@ -80,8 +115,33 @@ func (cf *concallFetcher) FindConcallHandler(c *gin.Context) {
 			"limit":      limit,
 			"total":      totalCount,
 			"totalPages": totalPages,
+			"companies":  companies,
 		},
 		"data": results,
 	})
 }
 
+// findRankedConcalls returns a page of the concalls matching filter ordered by
+// the position of their scrip code in codes, then latest first
+func (cf *concallFetcher) findRankedConcalls(ctx context.Context, filter bson.M, codes []int, projection bson.M, skip, limit int64) ([]domain.ConcallLite, error) {
+	pipeline := []bson.M{
+		{"$match": filter},
+		{"$addFields": bson.M{"rank": bson.M{"$indexOfArray": bson.A{codes, "$scrip_code"}}}},
+		{"$sort": bson.D{{Key: "rank", Value: 1}, {Key: "date", Value: -1}}},
+		{"$skip": skip},
+		{"$limit": limit},
+		{"$project": projection},
+	}
+
+	cursor, err := cf.repo.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	results := []domain.ConcallLite{}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
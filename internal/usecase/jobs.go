@@ -0,0 +1,318 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"concall-analyser/internal/domain"
+	"concall-analyser/internal/service/lock"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// jobTimeout bounds a single fetch job end to end
+	jobTimeout = 3600 * time.Second
+	// cancelPollInterval is how often a running job checks whether it was cancelled,
+	// which also picks up cancellations issued through another replica
+	cancelPollInterval = 3 * time.Second
+)
+
+// jobRunner keeps the cancel functions of the jobs running in this process
+type jobRunner struct {
+	mu      sync.Mutex
+	cancels map[primitive.ObjectID]context.CancelFunc
+}
+
+func newJobRunner() *jobRunner {
+	return &jobRunner{
+		cancels: make(map[primitive.ObjectID]context.CancelFunc),
+	}
+}
+
+func (r *jobRunner) add(id primitive.ObjectID, cancel context.CancelFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cancels[id] = cancel
+}
+
+func (r *jobRunner) remove(id primitive.ObjectID) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.cancels, id)
+}
+
+func (r *jobRunner) cancel(id primitive.ObjectID) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cancel, ok := r.cancels[id]
+	if ok {
+		cancel()
+	}
+	return ok
+}
+
+// submitFetchJob persists a queued job for the date range and starts running it in the
+// background. fiscalYears, when set, overrides the fiscal years guidance is extracted for.
+// The returned channel is closed once the job has finished.
+func (cf *concallFetcher) submitFetchJob(ctx context.Context, fromDate, toDate time.Time, fiscalYears []string) (*domain.FetchJob, <-chan struct{}, error) {
+	lease, err := cf.locker.Acquire(ctx, domain.LockIngestion, string(domain.JobKindFetch))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	job := &domain.FetchJob{
+		Kind:        domain.JobKindFetch,
+		State:       domain.JobQueued,
+		From:        fromDate.Format("2006-01-02"),
+		To:          toDate.Format("2006-01-02"),
+		FiscalYears: fiscalYears,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if err := cf.jobRepo.Create(ctx, job); err != nil {
+		lease.Release()
+		return nil, nil, err
+	}
+	log.Printf("📝 Submitted fetch job %s (%s → %s)", job.ID.Hex(), job.From, job.To)
+
+	done := cf.startJob(job.ID, lease, func(jobCtx context.Context) {
+		cf.runFetchJob(jobCtx, job.ID, fromDate, toDate, fiscalYears)
+	})
+
+	return job, done, nil
+}
+
+// RunFetch submits a fetch job for the date range and waits for it to finish.
+// If ctx ends first the job is cancelled.
+func (cf *concallFetcher) RunFetch(ctx context.Context, fromDate, toDate time.Time) (*domain.FetchJob, error) {
+	job, done, err := cf.submitFetchJob(ctx, fromDate, toDate, nil)
+	if err != nil {
+		return nil, err
+	}
+	return cf.waitForJob(ctx, job.ID, done)
+}
+
+// startJob runs a persisted job in the background under the job timeout,
+// making it cancellable from this process and from other replicas. The job
+// owns the lease: it is released when run returns, and losing it stops the
+// job. The returned channel is closed once the lease is released.
+func (cf *concallFetcher) startJob(jobID primitive.ObjectID, lease lock.Handle, run func(ctx context.Context)) <-chan struct{} {
+	setCtx, cancelSet := context.WithTimeout(context.Background(), 10*time.Second)
+	if err := lease.SetJob(setCtx, jobID.Hex()); err != nil {
+		log.Printf("⚠️ Failed to record job %s on the %s lock: %v", jobID.Hex(), lease.Lease().Name, err)
+	}
+	cancelSet()
+
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), jobTimeout)
+	jobCtx, cancelCause := context.WithCancelCause(timeoutCtx)
+	cf.jobs.add(jobID, cancel)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer lease.Release()
+		defer cf.jobs.remove(jobID)
+		defer cancel()
+
+		go cf.watchCancellation(jobCtx, jobID, cancel)
+		go watchLease(jobCtx, lease, cancelCause)
+		run(jobCtx)
+	}()
+
+	return done
+}
+
+// watchLease cancels ctx with ErrLockLost if the lease is lost while ctx is live
+func watchLease(ctx context.Context, lease lock.Handle, cancel context.CancelCauseFunc) {
+	select {
+	case <-ctx.Done():
+	case <-lease.Lost():
+		cancel(domain.ErrLockLost)
+	}
+}
+
+// respondLockHeld answers 409 with the current holder if err says the lock is taken
+func respondLockHeld(c *gin.Context, err error) bool {
+	var held *domain.LockHeldError
+	if !errors.As(err, &held) {
+		return false
+	}
+
+	log.Printf("⏳ Rejected request, %v", held)
+	c.JSON(http.StatusConflict, gin.H{
+		"error":  "another ingestion, cleanup or reprocess run is in progress",
+		"holder": held.Lease,
+	})
+	return true
+}
+
+// watchCancellation cancels a running job once its cancel_requested flag is set
+func (cf *concallFetcher) watchCancellation(ctx context.Context, jobID primitive.ObjectID, cancel context.CancelFunc) {
+	ticker := time.NewTicker(cancelPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			job, err := cf.jobRepo.FindByID(ctx, jobID)
+			if err != nil {
+				continue
+			}
+			if job.CancelRequested {
+				log.Printf("🛑 Cancellation requested for job %s", jobID.Hex())
+				cancel()
+				return
+			}
+		}
+	}
+}
+
+// updateJob writes job fields using its own context so that progress is still
+// recorded after the job context has been cancelled
+func (cf *concallFetcher) updateJob(jobID primitive.ObjectID, fields bson.M) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := cf.jobRepo.Update(ctx, jobID, fields); err != nil {
+		log.Printf("⚠️ Failed to update job %s: %v", jobID.Hex(), err)
+	}
+}
+
+func (cf *concallFetcher) updateJobItem(jobID primitive.ObjectID, newsID string, status domain.JobItemStatus, itemErr error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	errMsg := ""
+	if itemErr != nil {
+		errMsg = itemErr.Error()
+	}
+
+	if err := cf.jobRepo.UpdateItem(ctx, jobID, newsID, status, errMsg); err != nil {
+		log.Printf("⚠️ Failed to update item %s of job %s: %v", newsID, jobID.Hex(), err)
+	}
+}
+
+// finishJob moves a job to its terminal state. A job whose context ended
+// without an error was either cancelled by a user or ran out of time.
+func (cf *concallFetcher) finishJob(ctx context.Context, jobID primitive.ObjectID, runErr error) {
+	readCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	job, err := cf.jobRepo.FindByID(readCtx, jobID)
+	if err != nil {
+		log.Printf("⚠️ Failed to load job %s to finish it: %v", jobID.Hex(), err)
+		return
+	}
+
+	state := domain.JobCompleted
+	errs := job.Errors
+	switch {
+	case job.CancelRequested:
+		state = domain.JobCancelled
+	case runErr != nil:
+		state = domain.JobFailed
+		errs = append(errs, runErr.Error())
+	case errors.Is(context.Cause(ctx), domain.ErrLockLost):
+		state = domain.JobFailed
+		errs = append(errs, "lost the ingestion lock to another run")
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		state = domain.JobFailed
+		errs = append(errs, "job timed out")
+	}
+
+	cf.updateJob(jobID, bson.M{
+		"state":       state,
+		"errors":      errs,
+		"finished_at": time.Now(),
+	})
+	log.Printf("🏁 Job %s finished with state %s", jobID.Hex(), state)
+	cf.publishRunFinished(job, state, errs)
+	cf.sendDigest(job)
+	cf.profileCompaniesOf(job)
+}
+
+func (cf *concallFetcher) GetJobHandler(c *gin.Context) {
+	jobID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid job id"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	job, err := cf.jobRepo.FindByID(ctx, jobID)
+	if err != nil {
+		if errors.Is(err, domain.ErrJobNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "job not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to fetch job",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+func (cf *concallFetcher) CancelJobHandler(c *gin.Context) {
+	jobID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid job id"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	requested, err := cf.jobRepo.RequestCancel(ctx, jobID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to cancel job",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if !requested {
+		job, err := cf.jobRepo.FindByID(ctx, jobID)
+		if errors.Is(err, domain.ErrJobNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "job not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to fetch job",
+				"details": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "job has already finished",
+			"state": job.State,
+		})
+		return
+	}
+
+	// Jobs running in another replica pick the flag up through watchCancellation
+	cf.jobs.cancel(jobID)
+	log.Printf("🛑 Cancellation requested for job %s", jobID.Hex())
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "Cancellation requested",
+		"job_id":  jobID.Hex(),
+	})
+}
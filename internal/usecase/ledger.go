@@ -0,0 +1,343 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"concall-analyser/internal/domain"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	skipReasonNoAttachment = "announcement has no PDF attachment"
+
+	// maxRetryBatch caps how many ledger entries a single retry job picks up
+	maxRetryBatch = 200
+	// maxRetryDelay caps the exponential backoff between automatic retries
+	maxRetryDelay = 24 * time.Hour
+)
+
+// ledgerEnqueue records that a job is about to process the announcements
+func (cf *concallFetcher) ledgerEnqueue(jobID primitive.ObjectID, announcements []domain.Announcement) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := cf.ledgerRepo.Enqueue(ctx, jobID, announcements); err != nil {
+		log.Printf("⚠️ Failed to enqueue %d announcements in the ledger: %v", len(announcements), err)
+	}
+}
+
+func (cf *concallFetcher) ledgerDownloaded(newsID string) {
+	if newsID == "" {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := cf.ledgerRepo.Update(ctx, newsID, bson.M{"state": domain.LedgerDownloaded, "reason": ""}); err != nil {
+		log.Printf("⚠️ Failed to update ledger entry %s: %v", newsID, err)
+	}
+}
+
+// ledgerAttempt records the final outcome of an attempt at an announcement
+func (cf *concallFetcher) ledgerAttempt(newsID string, state domain.LedgerState, reason string) *domain.LedgerEntry {
+	if newsID == "" {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	entry, err := cf.ledgerRepo.RecordAttempt(ctx, newsID, state, reason)
+	if err != nil {
+		log.Printf("⚠️ Failed to record attempt for ledger entry %s: %v", newsID, err)
+		return nil
+	}
+	return entry
+}
+
+// ledgerFailed records a failed attempt and schedules the next automatic retry
+// with exponential backoff, or moves the entry to the dead letters once it has
+// used up its attempts
+func (cf *concallFetcher) ledgerFailed(newsID string, cause error) {
+	entry := cf.ledgerAttempt(newsID, domain.LedgerFailed, cause.Error())
+	if entry == nil {
+		return
+	}
+
+	fields := bson.M{}
+	if entry.Attempts >= cf.cfg.RetryMaxAttempts {
+		fields["state"] = domain.LedgerDead
+		log.Printf("🪦 Giving up on %s after %d attempts", entry.Name, entry.Attempts)
+	} else {
+		next := time.Now().Add(cf.retryDelay(entry.Attempts))
+		fields["next_retry_at"] = next
+		log.Printf("🔁 Will retry %s after %s (attempt %d/%d)",
+			entry.Name, next.Format(time.RFC3339), entry.Attempts, cf.cfg.RetryMaxAttempts)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := cf.ledgerRepo.Update(ctx, newsID, fields); err != nil {
+		log.Printf("⚠️ Failed to schedule retry for ledger entry %s: %v", newsID, err)
+	}
+}
+
+// retryDelay doubles the base delay for every attempt already made
+func (cf *concallFetcher) retryDelay(attempts int) time.Duration {
+	delay := cf.cfg.RetryBaseDelay
+	for i := 1; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+	return delay
+}
+
+// ListIngestionFailuresHandler lists ledger entries that did not produce a summary,
+// most recently updated first
+func (cf *concallFetcher) ListIngestionFailuresHandler(c *gin.Context) {
+	states := []domain.LedgerState{domain.LedgerFailed, domain.LedgerDead}
+	if stateStr := c.Query("state"); stateStr != "" {
+		states = states[:0]
+		for _, s := range strings.Split(stateStr, ",") {
+			state := domain.LedgerState(strings.TrimSpace(strings.ToLower(s)))
+			switch state {
+			case domain.LedgerFailed, domain.LedgerDead, domain.LedgerSkipped:
+				states = append(states, state)
+			default:
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": fmt.Sprintf("invalid 'state' %q, expected failed, dead or skipped", s),
+				})
+				return
+			}
+		}
+	}
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page <= 0 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit <= 0 {
+		limit = 20
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	filter := bson.M{"state": bson.M{"$in": states}}
+	findOpts := options.Find().
+		SetSort(bson.D{{Key: "updated_at", Value: -1}}).
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit))
+
+	entries, err := cf.ledgerRepo.Find(ctx, filter, findOpts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to query ingestion ledger",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	totalCount, err := cf.ledgerRepo.Count(ctx, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to count ledger entries",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"meta": gin.H{
+			"page":       page,
+			"limit":      limit,
+			"total":      totalCount,
+			"totalPages": (totalCount + int64(limit) - 1) / int64(limit),
+			"states":     states,
+		},
+		"data": entries,
+	})
+}
+
+type retryRequest struct {
+	NewsIDs []string `json:"news_ids"`
+}
+
+// RetryIngestionHandler submits a job that runs failed announcements again, either
+// the given NewsIDs or every failed and dead entry. Dead entries are only ever
+// retried this way.
+func (cf *concallFetcher) RetryIngestionHandler(c *gin.Context) {
+	var req retryRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid request body: %v", err)})
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	filter := bson.M{"state": bson.M{"$in": []domain.LedgerState{domain.LedgerFailed, domain.LedgerDead}}}
+	if len(req.NewsIDs) > 0 {
+		filter["_id"] = bson.M{"$in": req.NewsIDs}
+	}
+
+	entries, err := cf.ledgerRepo.Find(ctx, filter, options.Find().SetLimit(maxRetryBatch))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to query ingestion ledger",
+			"details": err.Error(),
+		})
+		return
+	}
+	if len(entries) == 0 {
+		c.JSON(http.StatusOK, gin.H{"message": "Nothing to retry"})
+		return
+	}
+
+	job, _, err := cf.submitRetryJob(ctx, entries)
+	if err != nil {
+		if respondLockHeld(c, err) {
+			return
+		}
+		log.Printf("❌ Failed to submit retry job: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to submit retry job",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message":    fmt.Sprintf("Retry job submitted for %d announcements", len(entries)),
+		"job_id":     job.ID.Hex(),
+		"state":      job.State,
+		"status_url": "/api/jobs/" + job.ID.Hex(),
+	})
+}
+
+// RetryDueFailures runs the failed announcements whose backoff has elapsed and
+// waits for the job to finish. It returns a nil job when nothing is due.
+func (cf *concallFetcher) RetryDueFailures(ctx context.Context) (*domain.FetchJob, error) {
+	filter := bson.M{
+		"state":         domain.LedgerFailed,
+		"next_retry_at": bson.M{"$lte": time.Now()},
+	}
+	findOpts := options.Find().
+		SetSort(bson.D{{Key: "next_retry_at", Value: 1}}).
+		SetLimit(maxRetryBatch)
+
+	entries, err := cf.ledgerRepo.Find(ctx, filter, findOpts)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, nil
+	}
+
+	job, done, err := cf.submitRetryJob(ctx, entries)
+	if err != nil {
+		return nil, err
+	}
+	return cf.waitForJob(ctx, job.ID, done)
+}
+
+func (cf *concallFetcher) submitRetryJob(ctx context.Context, entries []domain.LedgerEntry) (*domain.FetchJob, <-chan struct{}, error) {
+	lease, err := cf.locker.Acquire(ctx, domain.LockIngestion, string(domain.JobKindRetry))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	job := &domain.FetchJob{
+		Kind:      domain.JobKindRetry,
+		State:     domain.JobQueued,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := cf.jobRepo.Create(ctx, job); err != nil {
+		lease.Release()
+		return nil, nil, err
+	}
+	log.Printf("📝 Submitted retry job %s for %d announcements", job.ID.Hex(), len(entries))
+
+	announcements := make([]domain.Announcement, 0, len(entries))
+	for _, e := range entries {
+		announcements = append(announcements, e.Announcement)
+	}
+
+	done := cf.startJob(job.ID, lease, func(jobCtx context.Context) {
+		cf.runRetryJob(jobCtx, job.ID, announcements)
+	})
+
+	return job, done, nil
+}
+
+func (cf *concallFetcher) runRetryJob(ctx context.Context, jobID primitive.ObjectID, announcements []domain.Announcement) {
+	cf.updateJob(jobID, bson.M{"state": domain.JobRunning, "started_at": time.Now()})
+
+	// A later fetch may already have summarized some of them
+	pending, err := cf.filterNewAnnouncements(ctx, announcements)
+	if err != nil {
+		log.Printf("❌ Failed to filter announcements: %v", err)
+		cf.finishJob(ctx, jobID, fmt.Errorf("failed to filter announcements: %w", err))
+		return
+	}
+	if len(pending) < len(announcements) {
+		stillPending := make(map[string]bool, len(pending))
+		for _, a := range pending {
+			stillPending[a.NewsID] = true
+		}
+		for _, a := range announcements {
+			if !stillPending[a.NewsID] {
+				cf.ledgerAttempt(a.NewsID, domain.LedgerSummarized, "")
+			}
+		}
+	}
+
+	log.Printf("🔁 Retrying %d failed announcements", len(pending))
+	cf.processAndStore(ctx, jobID, pending, nil)
+}
+
+// waitForJob waits for a job started by this process to finish and returns its
+// final state. If ctx ends first the job is cancelled.
+func (cf *concallFetcher) waitForJob(ctx context.Context, jobID primitive.ObjectID, done <-chan struct{}) (*domain.FetchJob, error) {
+	select {
+	case <-done:
+	case <-ctx.Done():
+		// Flag it first so finishJob records the job as cancelled rather than completed
+		cancelCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		if _, err := cf.jobRepo.RequestCancel(cancelCtx, jobID); err != nil {
+			log.Printf("⚠️ Failed to flag job %s for cancellation: %v", jobID.Hex(), err)
+		}
+		cancel()
+		cf.jobs.cancel(jobID)
+		<-done
+	}
+
+	readCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	job, err := cf.jobRepo.FindByID(readCtx, jobID)
+	if errors.Is(err, domain.ErrJobNotFound) {
+		return nil, fmt.Errorf("job %s disappeared: %w", jobID.Hex(), err)
+	}
+	return job, err
+}
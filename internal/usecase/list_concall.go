@@ -2,16 +2,34 @@ package usecase
 
 import (
 	"context"
+	"fmt"
+	"math"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
 
+	"concall-analyser/internal/domain"
+
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// listFilters are the validated filter and sort parameters of list_concalls,
+// echoed back in meta.filters
+type listFilters struct {
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+	// HasGuidance is "true" (the default), "false" or "any"
+	HasGuidance string                  `json:"has_guidance"`
+	Metrics     []domain.GuidanceMetric `json:"metric,omitempty"`
+	Sector      string                  `json:"sector,omitempty"`
+	MinGrowth   *float64                `json:"min_growth,omitempty"`
+	Sort        string                  `json:"sort"`
+	Order       string                  `json:"order"`
+}
+
 func (cf *concallFetcher) ListConcallHandler(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(context.Background(), 3600*time.Second)
 	defer cancel()
@@ -31,21 +49,37 @@ func (cf *concallFetcher) ListConcallHandler(c *gin.Context) {
 	skip := int64((page - 1) * limit)
 	limit64 := int64(limit)
 
-	// Filter to exclude documents where guidance is "NA"
-	filter := bson.M{
-		"guidance": bson.M{"$ne": "NA"},
+	filters, err := parseListFilters(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	filter, err := cf.listFilter(ctx, filters)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to resolve sector",
+			"details": err.Error(),
+		})
+		return
 	}
 
 	projection := bson.M{
-		"name":     1,
-		"date":     1,
-		"guidance": 1,
-		"_id":      0,
+		"name":           1,
+		"date":           1,
+		"guidance":       1,
+		"guidance_items": 1,
+		"guidance_by_fy": 1,
+		"news_id":        1,
+		"document_id":    1,
+		"scrip_code":     1,
+		"quarter_id":     1,
+		"_id":            0,
 	}
 
 	findOpts := options.Find().
 		SetProjection(projection).
-		SetSort(bson.D{{Key: "date", Value: -1}}).
+		SetSort(listSort(filters)).
 		SetSkip(skip).
 		SetLimit(limit64)
 
@@ -80,7 +114,154 @@ func (cf *concallFetcher) ListConcallHandler(c *gin.Context) {
 			"limit":      limit,
 			"total":      totalCount,
 			"totalPages": totalPages,
+			"filters":    filters,
 		},
 		"data": results,
 	})
 }
+
+// parseListFilters reads and validates the filter and sort query parameters
+func parseListFilters(c *gin.Context) (*listFilters, error) {
+	filters := &listFilters{
+		From:        strings.TrimSpace(c.Query("from")),
+		To:          strings.TrimSpace(c.Query("to")),
+		HasGuidance: strings.ToLower(strings.TrimSpace(c.DefaultQuery("has_guidance", "true"))),
+		Sector:      strings.TrimSpace(c.Query("sector")),
+		Sort:        strings.ToLower(strings.TrimSpace(c.DefaultQuery("sort", "date"))),
+		Order:       strings.ToLower(strings.TrimSpace(c.Query("order"))),
+	}
+
+	for _, param := range []struct {
+		name  string
+		value string
+	}{{"from", filters.From}, {"to", filters.To}} {
+		if param.value == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", param.value); err != nil {
+			return nil, fmt.Errorf("invalid '%s' %q, expected YYYY-MM-DD", param.name, param.value)
+		}
+	}
+	if filters.From != "" && filters.To != "" && filters.From > filters.To {
+		return nil, fmt.Errorf("'from' must not be after 'to'")
+	}
+
+	switch filters.HasGuidance {
+	case "true", "false", "any":
+	default:
+		return nil, fmt.Errorf("invalid 'has_guidance' %q, expected true, false or any", filters.HasGuidance)
+	}
+
+	if metrics := c.Query("metric"); metrics != "" {
+		for _, name := range strings.Split(metrics, ",") {
+			metric := domain.GuidanceMetric(strings.ToLower(strings.TrimSpace(name)))
+			if !validMetric(metric) {
+				return nil, fmt.Errorf("invalid 'metric' %q, expected revenue, profit or eps", name)
+			}
+			filters.Metrics = append(filters.Metrics, metric)
+		}
+	}
+
+	if minGrowth := c.Query("min_growth"); minGrowth != "" {
+		value, err := strconv.ParseFloat(minGrowth, 64)
+		if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+			return nil, fmt.Errorf("invalid 'min_growth' %q, expected a percentage", minGrowth)
+		}
+		filters.MinGrowth = &value
+	}
+
+	if (len(filters.Metrics) > 0 || filters.MinGrowth != nil) && filters.HasGuidance == "false" {
+		return nil, fmt.Errorf("'metric' and 'min_growth' only match concalls with guidance")
+	}
+
+	switch filters.Sort {
+	case "date", "growth":
+		if filters.Order == "" {
+			filters.Order = "desc"
+		}
+	case "name":
+		if filters.Order == "" {
+			filters.Order = "asc"
+		}
+	default:
+		return nil, fmt.Errorf("invalid 'sort' %q, expected date, name or growth", filters.Sort)
+	}
+	if filters.Order != "asc" && filters.Order != "desc" {
+		return nil, fmt.Errorf("invalid 'order' %q, expected asc or desc", filters.Order)
+	}
+
+	return filters, nil
+}
+
+func validMetric(metric domain.GuidanceMetric) bool {
+	for _, known := range domain.GuidanceMetrics {
+		if metric == known {
+			return true
+		}
+	}
+	return false
+}
+
+// listFilter translates the filters into a query on the guidances collection.
+// A sector is resolved to its scrip codes through the company master list.
+func (cf *concallFetcher) listFilter(ctx context.Context, filters *listFilters) (bson.M, error) {
+	filter := bson.M{}
+
+	switch filters.HasGuidance {
+	case "true":
+		filter["guidance"] = bson.M{"$ne": domain.NoGuidance}
+	case "false":
+		filter["guidance"] = domain.NoGuidance
+	}
+
+	if filters.From != "" || filters.To != "" {
+		dateRange := bson.M{}
+		if filters.From != "" {
+			dateRange["$gte"] = filters.From
+		}
+		if filters.To != "" {
+			dateRange["$lte"] = filters.To
+		}
+		filter["date"] = dateRange
+	}
+
+	// Both conditions must hold for the same guidance item
+	item := bson.M{}
+	if len(filters.Metrics) > 0 {
+		item["metric"] = bson.M{"$in": filters.Metrics}
+	}
+	if filters.MinGrowth != nil {
+		item["growth_pct"] = bson.M{"$gte": *filters.MinGrowth}
+	}
+	if len(item) > 0 {
+		filter["guidance_items"] = bson.M{"$elemMatch": item}
+	}
+
+	if filters.Sector != "" {
+		scripCodes, err := cf.companyRepo.FindScripCodesBySector(ctx, filters.Sector)
+		if err != nil {
+			return nil, err
+		}
+		filter["scrip_code"] = bson.M{"$in": scripCodes}
+	}
+
+	return filter, nil
+}
+
+// listSort orders by the chosen key, then by date. Sorting by growth uses each
+// concall's highest guided growth when descending and its lowest when ascending.
+func listSort(filters *listFilters) bson.D {
+	direction := -1
+	if filters.Order == "asc" {
+		direction = 1
+	}
+
+	switch filters.Sort {
+	case "name":
+		return bson.D{{Key: "name", Value: direction}, {Key: "date", Value: -1}}
+	case "growth":
+		return bson.D{{Key: "guidance_items.growth_pct", Value: direction}, {Key: "date", Value: -1}}
+	default:
+		return bson.D{{Key: "date", Value: direction}}
+	}
+}
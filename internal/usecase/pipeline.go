@@ -0,0 +1,453 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"concall-analyser/internal/domain"
+	"concall-analyser/internal/infrastructure/file"
+	"concall-analyser/internal/service/llm"
+	"concall-analyser/internal/service/storage"
+	ws "concall-analyser/internal/websocket"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// downloadedAnnouncement is handed from the download stage to the summarize stage
+type downloadedAnnouncement struct {
+	announcement domain.Announcement
+	path         string
+	saveAs       string
+	// documentID is the SHA-256 of the archived PDF, empty if archiving failed
+	documentID string
+	// transcript is nil when text extraction failed; the PDF is then summarized directly
+	transcript *domain.Transcript
+}
+
+// pipelineStats holds the success/skip/error accounting shared by both stages
+type pipelineStats struct {
+	mu       sync.Mutex
+	total    int
+	results  []domain.ConcallSummary
+	skipped  int
+	errCount int
+}
+
+// processed counts the announcements that have left the pipeline
+func (s *pipelineStats) processed() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.results) + s.skipped + s.errCount
+}
+
+// clampWorkers bounds a pool to the items it works on, keeping at least one
+// worker so the queue feeding it is always drained
+func clampWorkers(workers, items int) int {
+	if workers > items {
+		workers = items
+	}
+	if workers < 1 {
+		workers = 1
+	}
+	return workers
+}
+
+// processAnnouncementsConcurrently runs announcements through two worker pools,
+// one downloading PDFs and one summarizing them, each bounded by MaxWorkers.
+// Summarization calls additionally share the fetcher's LLM rate limiter.
+func (cf *concallFetcher) processAnnouncementsConcurrently(
+	ctx context.Context,
+	jobID primitive.ObjectID,
+	summarizer llm.Summarizer,
+	announcements []domain.Announcement,
+	fiscalYears []string,
+) []domain.ConcallSummary {
+	workers := clampWorkers(cf.cfg.MaxWorkers, len(announcements))
+
+	total := len(announcements)
+	stats := &pipelineStats{total: total, results: make([]domain.ConcallSummary, 0)}
+
+	log.Printf("⚙️ Starting concurrent processing of %d announcements with %d workers per stage...", total, workers)
+
+	queue := make(chan domain.Announcement)
+	downloaded := make(chan downloadedAnnouncement, workers)
+
+	go func() {
+		defer close(queue)
+		for i, a := range announcements {
+			select {
+			case queue <- a:
+			case <-ctx.Done():
+				log.Printf("🛑 Stopping after queueing %d/%d announcements: %v", i, total, ctx.Err())
+				return
+			}
+		}
+	}()
+
+	var downloadWG sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		downloadWG.Add(1)
+		go func() {
+			defer downloadWG.Done()
+			for a := range queue {
+				cf.updateJobItem(jobID, a.NewsID, domain.ItemProcessing, nil)
+
+				d, err := cf.downloadAnnouncement(ctx, a)
+				switch {
+				case err != nil:
+					cf.recordFailure(jobID, stats, a, err)
+				case d == nil:
+					cf.recordSkip(jobID, stats, a)
+				default:
+					cf.ledgerDownloaded(a.NewsID)
+					cf.publish(ws.EventAnnouncementDownloaded, a.ScripCode, ws.AnnouncementDownloaded{
+						JobID:     jobID.Hex(),
+						NewsID:    a.NewsID,
+						Name:      strings.TrimSuffix(a.ShortLongName, "-$"),
+						ScripCode: a.ScripCode,
+						Done:      stats.processed(),
+						Total:     total,
+					})
+					downloaded <- *d
+				}
+			}
+		}()
+	}
+
+	go func() {
+		downloadWG.Wait()
+		close(downloaded)
+	}()
+
+	var summarizeWG sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		summarizeWG.Add(1)
+		go func() {
+			defer summarizeWG.Done()
+			for d := range downloaded {
+				summary, err := cf.summarizeAnnouncement(ctx, summarizer, d, cf.targetFiscalYears(d.announcement.NewsDate, fiscalYears))
+				if err != nil {
+					cf.recordFailure(jobID, stats, d.announcement, err)
+					continue
+				}
+				if err := cf.persistSummary(jobID, summary); err != nil {
+					cf.recordFailure(jobID, stats, d.announcement, err)
+					continue
+				}
+
+				stats.mu.Lock()
+				stats.results = append(stats.results, *summary)
+				done := len(stats.results) + stats.skipped + stats.errCount
+				stats.mu.Unlock()
+
+				cf.updateJobItem(jobID, d.announcement.NewsID, domain.ItemSucceeded, nil)
+				cf.ledgerAttempt(d.announcement.NewsID, domain.LedgerSummarized, "")
+				cf.publish(ws.EventAnnouncementSummarized, summary.ScripCode, ws.AnnouncementSummarized{
+					JobID:   jobID.Hex(),
+					Concall: summary.Lite(),
+					Done:    done,
+					Total:   total,
+				})
+				log.Printf("✅ [%d/%d] Processed successfully: %s", done, total, d.announcement.ShortLongName)
+			}
+		}()
+	}
+
+	summarizeWG.Wait()
+
+	log.Printf("📈 Processing complete - Success: %d, Skipped: %d, Errors: %d",
+		len(stats.results), stats.skipped, stats.errCount)
+
+	return stats.results
+}
+
+// persistSummary stores a summary as soon as it is produced and adds it to the
+// job's results. It deliberately does not use the job context: a summary that
+// was paid for is worth keeping even if the job is being cancelled.
+func (cf *concallFetcher) persistSummary(jobID primitive.ObjectID, summary *domain.ConcallSummary) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	inserted, err := cf.repo.Upsert(ctx, summary)
+	if err != nil {
+		return fmt.Errorf("failed to save summary for %s: %w", summary.Name, err)
+	}
+	// Only new transcripts are announced; a resumed or repeated run rewriting a
+	// stored summary is not a new concall. A bus that watches the guidances
+	// collection derives the event from the insert itself.
+	if inserted {
+		cf.publish(ws.EventConcallAdded, summary.ScripCode, ws.ConcallAdded{Concall: summary.Lite()})
+		if cf.webhooks != nil {
+			cf.webhooks.ConcallAdded(*summary)
+		}
+	}
+	if err := cf.jobRepo.AppendSummary(ctx, jobID, *summary); err != nil {
+		log.Printf("⚠️ Failed to add summary of %s to job %s: %v", summary.Name, jobID.Hex(), err)
+	}
+	return nil
+}
+
+func (cf *concallFetcher) recordFailure(jobID primitive.ObjectID, stats *pipelineStats, a domain.Announcement, err error) {
+	stats.mu.Lock()
+	stats.errCount++
+	stats.mu.Unlock()
+
+	log.Printf("❌ Error processing announcement %s (PDFFlag: %d, Attachment: %s): %v",
+		a.ShortLongName, a.PDFFlag, a.AttachmentName, err)
+	cf.updateJobItem(jobID, a.NewsID, domain.ItemFailed, err)
+	cf.ledgerFailed(a.NewsID, err)
+	cf.publish(ws.EventAnnouncementFailed, a.ScripCode, ws.AnnouncementFailed{
+		JobID:     jobID.Hex(),
+		NewsID:    a.NewsID,
+		Name:      strings.TrimSuffix(a.ShortLongName, "-$"),
+		ScripCode: a.ScripCode,
+		Error:     err.Error(),
+		Done:      stats.processed(),
+		Total:     stats.total,
+	})
+}
+
+func (cf *concallFetcher) recordSkip(jobID primitive.ObjectID, stats *pipelineStats, a domain.Announcement) {
+	stats.mu.Lock()
+	stats.skipped++
+	stats.mu.Unlock()
+
+	log.Printf("⏭️ Skipped announcement: %s (PDFFlag: %d, Attachment: %s)",
+		a.ShortLongName, a.PDFFlag, a.AttachmentName)
+	cf.updateJobItem(jobID, a.NewsID, domain.ItemSkipped, nil)
+	cf.ledgerAttempt(a.NewsID, domain.LedgerSkipped, skipReasonNoAttachment)
+}
+
+// downloadAnnouncement fetches the announcement's PDF into DestDir. It returns
+// nil without an error for announcements that have no attachment.
+func (cf *concallFetcher) downloadAnnouncement(ctx context.Context, a domain.Announcement) (*downloadedAnnouncement, error) {
+	if a.AttachmentName == "" {
+		log.Printf("⏭️ Skipping announcement AttachmentName='%s'", a.ShortLongName)
+		return nil, nil
+	}
+
+	datePart := strings.Split(a.NewsDate, "T")[0]
+	companyPart := file.SanitizeFileName(a.ShortLongName)
+	// NewsID keeps file names unique when one company files twice on the same day
+	saveAs := fmt.Sprintf("%s_%s_%s.pdf", companyPart, datePart, a.NewsID)
+
+	log.Printf("📥 Downloading PDF: %s (from %s)", saveAs, a.AttachmentName)
+	path, err := cf.pdfDownloader.Download(ctx, a.AttachmentName, cf.cfg.DestDir, saveAs)
+	if err != nil {
+		return nil, fmt.Errorf("download error for %s: %w", saveAs, err)
+	}
+
+	fileInfo, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("file stat error for %s: %w", path, err)
+	}
+	if fileInfo.Size() == 0 {
+		removeTempFile(path)
+		return nil, fmt.Errorf("PDF file is empty at %s", path)
+	}
+
+	log.Printf("✅ PDF saved to %s (size: %d bytes)", path, fileInfo.Size())
+
+	documentID := cf.archiveDocument(ctx, a, path)
+
+	return &downloadedAnnouncement{
+		announcement: a,
+		path:         path,
+		saveAs:       saveAs,
+		documentID:   documentID,
+		transcript:   cf.extractTranscript(ctx, a, path, documentID),
+	}, nil
+}
+
+// archiveDocument keeps a copy of the raw PDF in the archive, keyed by its
+// SHA-256, so it can be reprocessed or downloaded without going back to BSE.
+// Failures are logged and only cost the archived copy.
+func (cf *concallFetcher) archiveDocument(ctx context.Context, a domain.Announcement, path string) string {
+	sum, size, err := storage.HashFile(path)
+	if err != nil {
+		log.Printf("⚠️ Failed to hash %s for the archive: %v", path, err)
+		return ""
+	}
+	key := storage.ContentKey(sum)
+
+	exists, err := cf.archive.Exists(ctx, key)
+	if err != nil {
+		log.Printf("⚠️ Failed to check archive for %s: %v", a.ShortLongName, err)
+		return ""
+	}
+	if !exists {
+		f, err := os.Open(path)
+		if err != nil {
+			log.Printf("⚠️ Failed to open %s for the archive: %v", path, err)
+			return ""
+		}
+		err = cf.archive.Put(ctx, key, f, size, "application/pdf")
+		f.Close()
+		if err != nil {
+			log.Printf("⚠️ Failed to archive %s: %v", a.ShortLongName, err)
+			return ""
+		}
+		log.Printf("🗄️ Archived %s as %s (%s)", a.ShortLongName, key, cf.archive.Backend())
+	}
+
+	doc := &domain.Document{
+		ID:             sum,
+		Backend:        cf.archive.Backend(),
+		Key:            key,
+		Size:           size,
+		ContentType:    "application/pdf",
+		AttachmentName: a.AttachmentName,
+		CreatedAt:      time.Now(),
+	}
+	if a.NewsID != "" {
+		doc.NewsIDs = []string{a.NewsID}
+	}
+	if err := cf.documentRepo.Upsert(ctx, doc); err != nil {
+		log.Printf("⚠️ Failed to store document metadata for %s: %v", a.ShortLongName, err)
+		return ""
+	}
+
+	return sum
+}
+
+// extractTranscript pulls the text out of the PDF and stores it for later
+// re-analysis. Failures are logged and leave the PDF to be summarized as is.
+func (cf *concallFetcher) extractTranscript(ctx context.Context, a domain.Announcement, path, documentID string) *domain.Transcript {
+	transcript, err := cf.textExtractor.Extract(path)
+	if err != nil {
+		log.Printf("⚠️ Text extraction failed for %s, falling back to the PDF: %v", a.ShortLongName, err)
+		return nil
+	}
+
+	transcript.NewsID = a.NewsID
+	transcript.ScripCode = a.ScripCode
+	transcript.Name = strings.TrimSuffix(a.ShortLongName, "-$")
+	transcript.Date = strings.Split(a.NewsDate, "T")[0]
+	transcript.AttachmentName = a.AttachmentName
+	transcript.DocumentID = documentID
+
+	if transcript.Scanned {
+		log.Printf("🖨️ %s looks scanned (%d chars over %d pages)", a.ShortLongName, transcript.CharCount, transcript.PageCount)
+	} else {
+		log.Printf("📝 Extracted %d chars over %d pages from %s", transcript.CharCount, transcript.PageCount, a.ShortLongName)
+	}
+
+	if a.NewsID != "" {
+		if err := cf.transcriptRepo.Upsert(ctx, transcript); err != nil {
+			log.Printf("⚠️ Failed to store transcript text for %s: %v", a.ShortLongName, err)
+		}
+	}
+
+	return transcript
+}
+
+// targetFiscalYears picks the fiscal years to extract guidance for: an explicit
+// override from the request, then the configured override, and otherwise the
+// years following the announcement date
+func (cf *concallFetcher) targetFiscalYears(announcedOn string, override []string) []string {
+	if len(override) > 0 {
+		return override
+	}
+	if len(cf.cfg.TargetFiscalYears) > 0 {
+		return cf.cfg.TargetFiscalYears
+	}
+
+	announced, err := time.Parse("2006-01-02", strings.Split(announcedOn, "T")[0])
+	if err != nil {
+		announced = time.Now()
+	}
+	return domain.TargetFiscalYears(announced, cf.cfg.FiscalYearHorizons)
+}
+
+// summarizeAnnouncement summarizes the downloaded transcript and removes the PDF afterwards
+func (cf *concallFetcher) summarizeAnnouncement(ctx context.Context, summarizer llm.Summarizer, d downloadedAnnouncement, fiscalYears []string) (*domain.ConcallSummary, error) {
+	defer removeTempFile(d.path)
+
+	result, err := cf.summarizeTranscript(ctx, summarizer, d.saveAs, d.path, d.transcript, fiscalYears)
+	if err != nil {
+		return nil, err
+	}
+
+	a := d.announcement
+	quarterID := ""
+	if a.QuarterID != nil {
+		quarterID = *a.QuarterID
+	}
+
+	return &domain.ConcallSummary{
+		ID:             primitive.NewObjectID(),
+		NewsID:         a.NewsID,
+		ScripCode:      a.ScripCode,
+		QuarterID:      quarterID,
+		AttachmentName: a.AttachmentName,
+		Name:           strings.TrimSuffix(a.ShortLongName, "-$"),
+		Date:           strings.Split(a.NewsDate, "T")[0],
+		Guidance:       result.Summary,
+		GuidanceItems:  result.Items,
+		FiscalYears:    fiscalYears,
+		GuidanceByFY:   result.ByFiscalYear,
+		DocumentID:     d.documentID,
+		PromptVersion:  llm.PromptVersion,
+		LLMProvider:    summarizer.Provider(),
+		LLMModel:       summarizer.Model(),
+		CreatedAt:      time.Now(),
+	}, nil
+}
+
+// summarizeTranscript sends the extracted text to the summarizer, split into
+// page-aligned chunks when it is long, and falls back to the PDF itself when
+// there is no usable text. Every call waits for a token from the shared limiter.
+func (cf *concallFetcher) summarizeTranscript(
+	ctx context.Context,
+	summarizer llm.Summarizer,
+	label string,
+	pdfPath string,
+	transcript *domain.Transcript,
+	fiscalYears []string,
+) (*domain.GuidanceResult, error) {
+	requests := []llm.Request{{PDFPath: pdfPath, FiscalYears: fiscalYears}}
+	if transcript != nil && !transcript.Scanned {
+		chunks := llm.ChunkPages(transcript.Pages, cf.cfg.LLMMaxChunkChars)
+		requests = make([]llm.Request, 0, len(chunks))
+		for _, chunk := range chunks {
+			requests = append(requests, llm.Request{PDFPath: pdfPath, Text: chunk, FiscalYears: fiscalYears})
+		}
+	}
+
+	log.Printf("🤖 Summarizing %s with %s/%s in %d call(s) (%s)",
+		label, summarizer.Provider(), summarizer.Model(), len(requests), strings.Join(fiscalYears, ", "))
+
+	results := make([]*domain.GuidanceResult, 0, len(requests))
+	for _, req := range requests {
+		if err := cf.llmLimiter.Wait(ctx); err != nil {
+			return nil, fmt.Errorf("rate limiter wait for %s: %w", label, err)
+		}
+
+		result, err := summarizer.Summarize(ctx, req)
+		if err != nil {
+			if errors.Is(err, domain.ErrMalformedGuidance) {
+				log.Printf("🚩 Flagging %s: model kept returning malformed guidance", label)
+			}
+			if errors.Is(err, llm.ErrTextRequired) && transcript != nil && transcript.Scanned {
+				err = fmt.Errorf("%w: %v", domain.ErrScannedPDF, err)
+			}
+			return nil, fmt.Errorf("summarization error for %s: %w", label, err)
+		}
+		results = append(results, result)
+	}
+	log.Printf("✅ Summary generated for %s:", label)
+
+	return llm.MergeResults(results, fiscalYears), nil
+}
+
+func removeTempFile(path string) {
+	if err := os.Remove(path); err != nil {
+		log.Printf("⚠️ Warning: failed to remove temp file %s: %v", path, err)
+	}
+}
@@ -0,0 +1,416 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"concall-analyser/internal/domain"
+	"concall-analyser/internal/service/llm"
+	"concall-analyser/internal/service/llm/provider"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// maxReprocessLimit caps how many summaries a single reprocess job may pick up
+const maxReprocessLimit = 1000
+
+type reprocessRequest struct {
+	Name          string `json:"name"`
+	ScripCode     int    `json:"scrip_code"`
+	From          string `json:"from"`
+	To            string `json:"to"`
+	PromptVersion string `json:"prompt_version"`
+	NAOnly        bool   `json:"na_only"`
+	FY            string `json:"fy"`
+	Limit         int    `json:"limit"`
+}
+
+// ReprocessConcallsHandler submits a job that runs stored concalls matching the
+// filter through the current summarizer again, using their archived transcripts
+func (cf *concallFetcher) ReprocessConcallsHandler(c *gin.Context) {
+	var req reprocessRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid request body: %v", err)})
+		return
+	}
+
+	filter := domain.ReprocessFilter{
+		Name:          strings.TrimSpace(req.Name),
+		ScripCode:     req.ScripCode,
+		PromptVersion: strings.TrimSpace(req.PromptVersion),
+		NAOnly:        req.NAOnly,
+		Limit:         req.Limit,
+	}
+
+	if req.From != "" {
+		fromDate, err := parseHumanReadableDate(req.From)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid 'from' date: %v", err)})
+			return
+		}
+		filter.From = fromDate.Format("2006-01-02")
+	}
+	if req.To != "" {
+		toDate, err := parseHumanReadableDate(req.To)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid 'to' date: %v", err)})
+			return
+		}
+		filter.To = toDate.Format("2006-01-02")
+	}
+	if filter.From != "" && filter.To != "" && filter.From > filter.To {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("'from' date (%s) cannot be after 'to' date (%s)", filter.From, filter.To),
+		})
+		return
+	}
+
+	var fiscalYears []string
+	if req.FY != "" {
+		var err error
+		fiscalYears, err = domain.ParseFiscalYears(req.FY)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid 'fy': %v", err)})
+			return
+		}
+	}
+
+	// Reprocessing spends LLM credits, so an empty filter is never taken to mean "everything"
+	if filter.Name == "" && filter.ScripCode == 0 && filter.From == "" && filter.To == "" &&
+		filter.PromptVersion == "" && !filter.NAOnly {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "at least one of name, scrip_code, from, to, prompt_version or na_only is required",
+		})
+		return
+	}
+	if filter.Limit <= 0 || filter.Limit > maxReprocessLimit {
+		filter.Limit = maxReprocessLimit
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	job, err := cf.submitReprocessJob(ctx, filter, fiscalYears)
+	if err != nil {
+		if respondLockHeld(c, err) {
+			return
+		}
+		log.Printf("❌ Failed to submit reprocess job: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to submit reprocess job",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message":    "Reprocess job submitted",
+		"job_id":     job.ID.Hex(),
+		"state":      job.State,
+		"status_url": "/api/jobs/" + job.ID.Hex(),
+	})
+}
+
+// GetConcallVersionsHandler returns the current summary of a concall together with
+// the versions it superseded, newest first
+func (cf *concallFetcher) GetConcallVersionsHandler(c *gin.Context) {
+	newsID := c.Param("news_id")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	current, err := cf.repo.FindSummaries(ctx, bson.M{"news_id": newsID}, options.Find().SetLimit(1))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to fetch concall",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	versions, err := cf.versionRepo.FindByNewsID(ctx, newsID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to fetch previous versions",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if len(current) == 0 && len(versions) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "concall not found"})
+		return
+	}
+
+	var latest *domain.ConcallSummary
+	if len(current) > 0 {
+		latest = &current[0]
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"current":  latest,
+		"previous": versions,
+	})
+}
+
+func (cf *concallFetcher) submitReprocessJob(ctx context.Context, filter domain.ReprocessFilter, fiscalYears []string) (*domain.FetchJob, error) {
+	lease, err := cf.locker.Acquire(ctx, domain.LockIngestion, string(domain.JobKindReprocess))
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	job := &domain.FetchJob{
+		Kind:        domain.JobKindReprocess,
+		State:       domain.JobQueued,
+		From:        filter.From,
+		To:          filter.To,
+		FiscalYears: fiscalYears,
+		Reprocess:   &filter,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if err := cf.jobRepo.Create(ctx, job); err != nil {
+		lease.Release()
+		return nil, err
+	}
+	log.Printf("📝 Submitted reprocess job %s", job.ID.Hex())
+
+	cf.startJob(job.ID, lease, func(jobCtx context.Context) {
+		cf.runReprocessJob(jobCtx, job.ID, filter, fiscalYears)
+	})
+
+	return job, nil
+}
+
+// reprocessQuery translates a reprocess filter into a Mongo filter. Only summaries
+// with a NewsID can be matched to an archived transcript.
+func reprocessQuery(filter domain.ReprocessFilter) bson.M {
+	query := bson.M{"news_id": bson.M{"$type": "string"}}
+
+	if filter.Name != "" {
+		query["name"] = bson.M{"$regex": regexp.QuoteMeta(filter.Name), "$options": "i"}
+	}
+	if filter.ScripCode != 0 {
+		query["scrip_code"] = filter.ScripCode
+	}
+
+	dateRange := bson.M{}
+	if filter.From != "" {
+		dateRange["$gte"] = filter.From
+	}
+	if filter.To != "" {
+		dateRange["$lte"] = filter.To
+	}
+	if len(dateRange) > 0 {
+		query["date"] = dateRange
+	}
+
+	switch filter.PromptVersion {
+	case "":
+	case llm.LegacyPromptVersion:
+		// Summaries from before prompt versions were recorded have no prompt_version
+		query["prompt_version"] = bson.M{"$in": bson.A{llm.LegacyPromptVersion, nil}}
+	default:
+		query["prompt_version"] = filter.PromptVersion
+	}
+
+	if filter.NAOnly {
+		query["guidance"] = domain.NoGuidance
+	}
+
+	return query
+}
+
+// runReprocessJob re-summarizes every stored concall matching the filter, keeping
+// the summary it replaces as a version
+func (cf *concallFetcher) runReprocessJob(ctx context.Context, jobID primitive.ObjectID, filter domain.ReprocessFilter, fiscalYears []string) {
+	cf.updateJob(jobID, bson.M{"state": domain.JobRunning, "started_at": time.Now()})
+
+	findOpts := options.Find().
+		SetSort(bson.D{{Key: "date", Value: -1}}).
+		SetLimit(int64(filter.Limit))
+
+	summaries, err := cf.repo.FindSummaries(ctx, reprocessQuery(filter), findOpts)
+	if err != nil {
+		log.Printf("❌ Failed to load concalls to reprocess: %v", err)
+		cf.finishJob(ctx, jobID, fmt.Errorf("failed to load concalls to reprocess: %w", err))
+		return
+	}
+
+	items := make([]domain.JobItem, 0, len(summaries))
+	for _, s := range summaries {
+		items = append(items, domain.JobItem{
+			NewsID:    s.NewsID,
+			Name:      s.Name,
+			Status:    domain.ItemPending,
+			UpdatedAt: time.Now(),
+		})
+	}
+	cf.updateJob(jobID, bson.M{"items": items, "progress": domain.JobProgress{Total: len(items)}})
+
+	if len(summaries) == 0 {
+		log.Printf("✅ No concalls match the reprocess filter")
+		cf.finishJob(ctx, jobID, nil)
+		return
+	}
+
+	cf.reprocessSummaries(ctx, jobID, summaries, fiscalYears)
+}
+
+// reprocessSummaries runs summaries that are already items of the job through the
+// summarizer, storing each result as soon as it is produced
+func (cf *concallFetcher) reprocessSummaries(ctx context.Context, jobID primitive.ObjectID, summaries []domain.ConcallSummary, fiscalYears []string) {
+	summarizer, err := provider.New(ctx, cf.cfg, cf.httpClient)
+	if err != nil {
+		log.Printf("Failed to initialize %s summarizer: %v", cf.cfg.LLMProvider, err)
+		cf.finishJob(ctx, jobID, fmt.Errorf("failed to initialize %s summarizer: %w", cf.cfg.LLMProvider, err))
+		return
+	}
+	defer summarizer.Close()
+
+	workers := clampWorkers(cf.cfg.MaxWorkers, len(summaries))
+	log.Printf("🔁 Reprocessing %d concalls with %s/%s (%d workers)", len(summaries), summarizer.Provider(), summarizer.Model(), workers)
+
+	queue := make(chan domain.ConcallSummary)
+	go func() {
+		defer close(queue)
+		for _, s := range summaries {
+			select {
+			case queue <- s:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		succeeded int
+	)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for s := range queue {
+				cf.updateJobItem(jobID, s.NewsID, domain.ItemProcessing, nil)
+
+				updated, err := cf.reprocessSummary(ctx, jobID, summarizer, s, fiscalYears)
+				if err != nil {
+					log.Printf("❌ Failed to reprocess %s (NewsID: %s): %v", s.Name, s.NewsID, err)
+					cf.updateJobItem(jobID, s.NewsID, domain.ItemFailed, err)
+					continue
+				}
+
+				mu.Lock()
+				succeeded++
+				mu.Unlock()
+				cf.updateJobItem(jobID, s.NewsID, domain.ItemSucceeded, nil)
+				if err := cf.jobRepo.AppendSummary(context.Background(), jobID, *updated); err != nil {
+					log.Printf("⚠️ Failed to add summary of %s to job %s: %v", updated.Name, jobID.Hex(), err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	log.Printf("✅ Reprocessed %d/%d concalls", succeeded, len(summaries))
+
+	cf.finishJob(ctx, jobID, nil)
+}
+
+// reprocessSummary summarizes the archived transcript of a stored concall again.
+// The previous summary is saved as a version before it is replaced.
+func (cf *concallFetcher) reprocessSummary(
+	ctx context.Context,
+	jobID primitive.ObjectID,
+	summarizer llm.Summarizer,
+	s domain.ConcallSummary,
+	fiscalYears []string,
+) (*domain.ConcallSummary, error) {
+	transcript, err := cf.transcriptRepo.FindByNewsID(ctx, s.NewsID)
+	if err != nil && !errors.Is(err, domain.ErrTranscriptNotFound) {
+		return nil, err
+	}
+
+	// Without usable text the PDF itself is needed, either to extract the text
+	// again or to hand it to a provider that reads documents
+	documentID := s.DocumentID
+	pdfPath := ""
+	if transcript == nil || transcript.Scanned {
+		doc, path, err := cf.restoreDocument(ctx, s.NewsID)
+		if errors.Is(err, domain.ErrDocumentNotFound) {
+			return nil, fmt.Errorf("no archived document for NewsID %s, it has to be fetched again", s.NewsID)
+		}
+		if err != nil {
+			return nil, err
+		}
+		defer removeTempFile(path)
+
+		pdfPath = path
+		documentID = doc.ID
+		if transcript == nil {
+			transcript = cf.extractTranscript(ctx, announcementOf(s), path, doc.ID)
+		}
+	}
+
+	fys := cf.targetFiscalYears(s.Date, fiscalYears)
+	result, err := cf.summarizeTranscript(ctx, summarizer, s.Name, pdfPath, transcript, fys)
+	if err != nil {
+		return nil, err
+	}
+
+	// The new summary was paid for, so store it even if the job is being cancelled
+	saveCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	if err := cf.versionRepo.Insert(saveCtx, &domain.SummaryVersion{
+		SummaryID:    s.ID,
+		NewsID:       s.NewsID,
+		Summary:      s,
+		SupersededAt: now,
+		SupersededBy: jobID,
+	}); err != nil {
+		return nil, err
+	}
+
+	updated := s
+	updated.Guidance = result.Summary
+	updated.GuidanceItems = result.Items
+	updated.FiscalYears = fys
+	updated.GuidanceByFY = result.ByFiscalYear
+	updated.DocumentID = documentID
+	updated.PromptVersion = llm.PromptVersion
+	updated.LLMProvider = summarizer.Provider()
+	updated.LLMModel = summarizer.Model()
+	updated.ReprocessedAt = &now
+
+	if err := cf.repo.ReplaceSummary(saveCtx, &updated); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// announcementOf rebuilds the announcement fields a stored summary still knows about
+func announcementOf(s domain.ConcallSummary) domain.Announcement {
+	return domain.Announcement{
+		NewsID:         s.NewsID,
+		ScripCode:      s.ScripCode,
+		ShortLongName:  s.Name,
+		NewsDate:       s.Date,
+		AttachmentName: s.AttachmentName,
+	}
+}
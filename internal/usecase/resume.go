@@ -0,0 +1,188 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"concall-analyser/internal/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ResumeInterruptedJobs picks up the oldest job whose process went away before it
+// finished and continues it from its per-item checkpoints. Every running job holds
+// the ingestion lock, so once the lock can be taken any unfinished job must have
+// been interrupted. Jobs whose cancellation was requested are finished as
+// cancelled instead. The job runs in the background; a nil job means none was
+// waiting, and a *domain.LockHeldError that another run holds the lock.
+func (cf *concallFetcher) ResumeInterruptedJobs(ctx context.Context) (*domain.FetchJob, error) {
+	lease, err := cf.locker.Acquire(ctx, domain.LockIngestion, "resume")
+	if err != nil {
+		return nil, err
+	}
+
+	unfinished, err := cf.jobRepo.FindUnfinished(ctx)
+	if err != nil {
+		lease.Release()
+		return nil, err
+	}
+
+	// A job cancelled before its process went away is finished, not resumed
+	jobs := unfinished[:0]
+	for _, job := range unfinished {
+		if job.CancelRequested {
+			log.Printf("🛑 Interrupted job %s was cancelled, not resuming it", job.ID.Hex())
+			cf.finishJob(ctx, job.ID, nil)
+			continue
+		}
+		jobs = append(jobs, job)
+	}
+	if len(jobs) == 0 {
+		lease.Release()
+		return nil, nil
+	}
+
+	job := jobs[0]
+	if len(jobs) > 1 {
+		log.Printf("⏸️ %d interrupted jobs waiting, resuming %s first", len(jobs), job.ID.Hex())
+	}
+
+	if err := cf.jobRepo.MarkResumed(ctx, job.ID); err != nil {
+		lease.Release()
+		return nil, err
+	}
+	log.Printf("▶️ Resuming interrupted %s job %s", jobKind(job), job.ID.Hex())
+
+	cf.startJob(job.ID, lease, func(jobCtx context.Context) {
+		cf.resumeJob(jobCtx, &job)
+	})
+
+	return &job, nil
+}
+
+// resumeJob continues a job with the items that had not finished. A job that was
+// interrupted before it listed its items is started over.
+func (cf *concallFetcher) resumeJob(ctx context.Context, job *domain.FetchJob) {
+	cf.updateJob(job.ID, bson.M{"state": domain.JobRunning})
+
+	pending := make([]string, 0, len(job.Items))
+	for _, item := range job.Items {
+		if item.Status == domain.ItemPending || item.Status == domain.ItemProcessing {
+			pending = append(pending, item.NewsID)
+		}
+	}
+
+	switch jobKind(*job) {
+	case domain.JobKindFetch:
+		if len(job.Items) == 0 {
+			cf.restartFetchJob(ctx, job)
+			return
+		}
+		cf.resumePipeline(ctx, job, pending)
+
+	case domain.JobKindRetry:
+		cf.resumePipeline(ctx, job, pending)
+
+	case domain.JobKindReprocess:
+		if len(job.Items) == 0 && job.Reprocess != nil {
+			cf.runReprocessJob(ctx, job.ID, *job.Reprocess, job.FiscalYears)
+			return
+		}
+		cf.resumeReprocess(ctx, job, pending)
+
+	default:
+		cf.finishJob(ctx, job.ID, fmt.Errorf("cannot resume job of kind %q", job.Kind))
+	}
+}
+
+func (cf *concallFetcher) restartFetchJob(ctx context.Context, job *domain.FetchJob) {
+	fromDate, err := time.Parse("2006-01-02", job.From)
+	if err != nil {
+		cf.finishJob(ctx, job.ID, fmt.Errorf("cannot resume job with 'from' %q: %w", job.From, err))
+		return
+	}
+	toDate, err := time.Parse("2006-01-02", job.To)
+	if err != nil {
+		cf.finishJob(ctx, job.ID, fmt.Errorf("cannot resume job with 'to' %q: %w", job.To, err))
+		return
+	}
+
+	cf.runFetchJob(ctx, job.ID, fromDate, toDate, job.FiscalYears)
+}
+
+// resumePipeline runs the unfinished items of a fetch or retry job again, taking
+// the announcements from the ingestion ledger
+func (cf *concallFetcher) resumePipeline(ctx context.Context, job *domain.FetchJob, pending []string) {
+	entries, err := cf.ledgerRepo.Find(ctx, bson.M{"_id": bson.M{"$in": pending}}, options.Find())
+	if err != nil {
+		cf.finishJob(ctx, job.ID, fmt.Errorf("failed to load announcements to resume: %w", err))
+		return
+	}
+	if len(entries) < len(pending) {
+		log.Printf("⚠️ %d unfinished items of job %s have no ledger entry and cannot be resumed",
+			len(pending)-len(entries), job.ID.Hex())
+	}
+
+	announcements := make([]domain.Announcement, 0, len(entries))
+	for _, e := range entries {
+		announcements = append(announcements, e.Announcement)
+	}
+
+	// A summary stored just before the interruption may not have had its item updated yet
+	remaining, err := cf.filterNewAnnouncements(ctx, announcements)
+	if err != nil {
+		cf.finishJob(ctx, job.ID, fmt.Errorf("failed to filter announcements: %w", err))
+		return
+	}
+	cf.markAlreadyStored(job.ID, announcements, remaining)
+
+	if len(remaining) == 0 {
+		cf.finishJob(ctx, job.ID, nil)
+		return
+	}
+
+	log.Printf("▶️ Resuming job %s with %d of %d items left", job.ID.Hex(), len(remaining), len(job.Items))
+	cf.runPipeline(ctx, job.ID, remaining, job.FiscalYears)
+}
+
+// markAlreadyStored completes the items of announcements that turned out to have a summary
+func (cf *concallFetcher) markAlreadyStored(jobID primitive.ObjectID, announcements, remaining []domain.Announcement) {
+	left := make(map[string]bool, len(remaining))
+	for _, a := range remaining {
+		left[a.NewsID] = true
+	}
+	for _, a := range announcements {
+		if !left[a.NewsID] {
+			cf.updateJobItem(jobID, a.NewsID, domain.ItemSucceeded, nil)
+			cf.ledgerAttempt(a.NewsID, domain.LedgerSummarized, "")
+		}
+	}
+}
+
+func (cf *concallFetcher) resumeReprocess(ctx context.Context, job *domain.FetchJob, pending []string) {
+	if len(pending) == 0 {
+		cf.finishJob(ctx, job.ID, nil)
+		return
+	}
+
+	summaries, err := cf.repo.FindSummaries(ctx, bson.M{"news_id": bson.M{"$in": pending}}, options.Find())
+	if err != nil {
+		cf.finishJob(ctx, job.ID, fmt.Errorf("failed to load concalls to resume: %w", err))
+		return
+	}
+
+	log.Printf("▶️ Resuming reprocess job %s with %d of %d items left", job.ID.Hex(), len(summaries), len(job.Items))
+	cf.reprocessSummaries(ctx, job.ID, summaries, job.FiscalYears)
+}
+
+// jobKind treats jobs stored before kinds existed as fetch jobs
+func jobKind(job domain.FetchJob) domain.JobKind {
+	if job.Kind == "" {
+		return domain.JobKindFetch
+	}
+	return job.Kind
+}
@@ -0,0 +1,72 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"concall-analyser/internal/service/search"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// defaultSuggestions and maxSuggestions bound the companies an autocomplete returns
+	defaultSuggestions = 8
+	maxSuggestions     = 20
+	// findConcallCompanies caps the companies whose concalls find_concalls returns
+	findConcallCompanies = 20
+)
+
+// SuggestCompaniesHandler autocompletes a company name, symbol or scrip code
+// from the company master list
+func (cf *concallFetcher) SuggestCompaniesHandler(c *gin.Context) {
+	q := strings.TrimSpace(c.Query("q"))
+	if q == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "query parameter 'q' is required"})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultSuggestions)))
+	if err != nil || limit <= 0 || limit > maxSuggestions {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("invalid 'limit', expected 1 to %d", maxSuggestions),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	results, err := cf.search.Search(ctx, q, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to search companies",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"query": q, "data": results})
+}
+
+// searchCompanies finds the companies a find_concalls query is about: the best
+// match and those scoring at least half as well, so "HDFC" finds every HDFC
+// company while "HDFC Bank" does not drag in the rest
+func (cf *concallFetcher) searchCompanies(ctx context.Context, q string) ([]search.Result, error) {
+	results, err := cf.search.Search(ctx, q, findConcallCompanies)
+	if err != nil || len(results) == 0 {
+		return results, err
+	}
+
+	cutoff := results[0].Score / 2
+	for i, result := range results {
+		if result.Score < cutoff {
+			return results[:i], nil
+		}
+	}
+	return results, nil
+}
@@ -1,6 +1,10 @@
 package usecase
 
 import (
+	"context"
+	"fmt"
+	"time"
+
 	"concall-analyser/config"
 	"concall-analyser/internal/db"
 	"concall-analyser/internal/domain"
@@ -8,30 +12,151 @@ import (
 	"concall-analyser/internal/interfaces"
 	"concall-analyser/internal/repository/mongo"
 	"concall-analyser/internal/service/analytics"
+	"concall-analyser/internal/service/auth"
 	"concall-analyser/internal/service/bse"
+	"concall-analyser/internal/service/digest"
+	"concall-analyser/internal/service/lock"
 	"concall-analyser/internal/service/pdf"
+	"concall-analyser/internal/service/search"
+	"concall-analyser/internal/service/storage"
+	"concall-analyser/internal/service/webhook"
+	ws "concall-analyser/internal/websocket"
+
+	"golang.org/x/time/rate"
 )
 
 type concallFetcher struct {
 	repo             domain.ConcallRepository
+	jobRepo          domain.JobRepository
+	transcriptRepo   domain.TranscriptRepository
+	documentRepo     domain.DocumentRepository
+	versionRepo      domain.SummaryVersionRepository
+	ledgerRepo       domain.LedgerRepository
+	watchlistRepo    domain.WatchlistRepository
+	webhookRepo      domain.WebhookRepository
+	deliveryRepo     domain.WebhookDeliveryRepository
+	digestRepo       domain.DigestSubscriptionRepository
+	companyRepo      domain.CompanyRepository
+	jobs             *jobRunner
 	bseClient        bse.BSEClient
+	httpClient       http.Client
 	pdfDownloader    pdf.PDFDownloader
+	textExtractor    pdf.TextExtractor
+	archive          storage.ObjectStore
+	locker           lock.Locker
 	analyticsService analytics.AnalyticsService
+	authService      auth.AuthService
+	webhooks         webhook.Dispatcher
+	digests          digest.Service
+	search           search.Service
+	hub              *ws.Hub
+	llmLimiter       *rate.Limiter
 	cfg              *config.Config
 }
 
-// NewConcallFetcher creates a new usecase instance with dependency injection
-func NewConcallFetcher(db *db.MongoDB, cfg *config.Config, analyticsService analytics.AnalyticsService) (interfaces.Usecase, error) {
+// NewConcallFetcher creates a new usecase instance with dependency injection.
+// Ingestion progress is published through hub, which may be nil, and new
+// summaries are sent to webhooks and, after each run, to digest subscribers.
+func NewConcallFetcher(db *db.MongoDB, cfg *config.Config, analyticsService analytics.AnalyticsService, authService auth.AuthService, webhooks webhook.Dispatcher, hub *ws.Hub) (interfaces.Usecase, error) {
 	repo := mongo.NewConcallRepository(db)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := repo.EnsureIndexes(ctx); err != nil {
+		return nil, fmt.Errorf("failed to ensure concall indexes: %w", err)
+	}
+
+	documentRepo := mongo.NewDocumentRepository(db)
+	if err := documentRepo.EnsureIndexes(ctx); err != nil {
+		return nil, fmt.Errorf("failed to ensure document indexes: %w", err)
+	}
+
+	versionRepo := mongo.NewSummaryVersionRepository(db)
+	if err := versionRepo.EnsureIndexes(ctx); err != nil {
+		return nil, fmt.Errorf("failed to ensure summary version indexes: %w", err)
+	}
+
+	ledgerRepo := mongo.NewLedgerRepository(db)
+	if err := ledgerRepo.EnsureIndexes(ctx); err != nil {
+		return nil, fmt.Errorf("failed to ensure ledger indexes: %w", err)
+	}
+
+	watchlistRepo := mongo.NewWatchlistRepository(db)
+	if err := watchlistRepo.EnsureIndexes(ctx); err != nil {
+		return nil, fmt.Errorf("failed to ensure watchlist indexes: %w", err)
+	}
+
+	deliveryRepo := mongo.NewWebhookDeliveryRepository(db)
+	if err := deliveryRepo.EnsureIndexes(ctx); err != nil {
+		return nil, fmt.Errorf("failed to ensure webhook delivery indexes: %w", err)
+	}
+
+	digestRepo := mongo.NewDigestSubscriptionRepository(db)
+	if err := digestRepo.EnsureIndexes(ctx); err != nil {
+		return nil, fmt.Errorf("failed to ensure digest subscription indexes: %w", err)
+	}
+
+	// Digests can be previewed without an SMTP server but are only sent with one
+	var mailer digest.Mailer
+	if cfg.SMTPHost != "" {
+		smtpMailer, err := digest.NewSMTPMailer(digest.SMTPOptions{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.SMTPFrom,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure SMTP: %w", err)
+		}
+		mailer = smtpMailer
+	}
+
+	companyRepo := mongo.NewCompanyRepository(db)
+	if err := companyRepo.EnsureIndexes(ctx); err != nil {
+		return nil, fmt.Errorf("failed to ensure company indexes: %w", err)
+	}
+
+	lockRepo := mongo.NewLockRepository(db)
+	if err := lockRepo.EnsureIndexes(ctx); err != nil {
+		return nil, fmt.Errorf("failed to ensure lock indexes: %w", err)
+	}
+
+	archive, err := storage.New(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize %s document storage: %w", cfg.StorageBackend, err)
+	}
+
 	httpClient := http.NewHTTPClient()
 	bseClient := bse.NewBSEClient(httpClient)
 	pdfDownloader := pdf.NewPDFDownloader(httpClient)
 
 	return &concallFetcher{
 		repo:             repo,
+		jobRepo:          mongo.NewJobRepository(db),
+		transcriptRepo:   mongo.NewTranscriptRepository(db),
+		documentRepo:     documentRepo,
+		versionRepo:      versionRepo,
+		ledgerRepo:       ledgerRepo,
+		watchlistRepo:    watchlistRepo,
+		webhookRepo:      mongo.NewWebhookRepository(db),
+		deliveryRepo:     deliveryRepo,
+		digestRepo:       digestRepo,
+		companyRepo:      companyRepo,
+		jobs:             newJobRunner(),
 		bseClient:        bseClient,
+		httpClient:       httpClient,
 		pdfDownloader:    pdfDownloader,
+		textExtractor:    pdf.NewTextExtractor(),
+		archive:          archive,
+		locker:           lock.NewLocker(lockRepo, cfg.LockTTL),
 		analyticsService: analyticsService,
+		authService:      authService,
+		webhooks:         webhooks,
+		digests:          digest.NewService(digestRepo, watchlistRepo, mailer, digest.Options{SiteURL: cfg.SiteURL}),
+		search:           search.NewService(companyRepo),
+		hub:              hub,
+		llmLimiter:       rate.NewLimiter(rate.Every(time.Minute/time.Duration(cfg.LLMRPM)), cfg.LLMBurst),
 		cfg:              cfg,
 	}, nil
 }
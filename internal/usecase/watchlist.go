@@ -0,0 +1,477 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"concall-analyser/internal/domain"
+	"concall-analyser/internal/middleware"
+	ws "concall-analyser/internal/websocket"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// maxCompanyCandidates is how many matches are listed when a company name is ambiguous
+const maxCompanyCandidates = 10
+
+// errCompanyNotFound is returned when no stored concall belongs to the requested company
+var errCompanyNotFound = errors.New("no concalls found for this company")
+
+// ambiguousCompanyError is returned when a name matches several companies
+type ambiguousCompanyError struct {
+	Candidates []companyMatch
+}
+
+func (e *ambiguousCompanyError) Error() string {
+	return fmt.Sprintf("name matches %d companies; add one by scrip_code", len(e.Candidates))
+}
+
+// companyMatch is a company found among the stored concalls
+type companyMatch struct {
+	ScripCode int    `bson:"_id" json:"scrip_code"`
+	Name      string `bson:"name" json:"name"`
+}
+
+type watchlistRequest struct {
+	Name string `json:"name"`
+}
+
+type watchlistCompanyRequest struct {
+	ScripCode int    `json:"scrip_code"`
+	Name      string `json:"name"`
+}
+
+// ListWatchlistsHandler returns the watchlists of the current user
+func (cf *concallFetcher) ListWatchlistsHandler(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	watchlists, err := cf.watchlistRepo.FindByOwner(ctx, middleware.CurrentPrincipal(c).Subject)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to list watchlists",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": watchlists})
+}
+
+// CreateWatchlistHandler creates an empty watchlist for the current user
+func (cf *concallFetcher) CreateWatchlistHandler(c *gin.Context) {
+	var req watchlistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid request body: %v", err)})
+		return
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name is required"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	watchlist := &domain.Watchlist{
+		Owner:     middleware.CurrentPrincipal(c).Subject,
+		Name:      name,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := cf.watchlistRepo.Create(ctx, watchlist); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to create watchlist",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, watchlist)
+}
+
+// GetWatchlistHandler returns one watchlist of the current user
+func (cf *concallFetcher) GetWatchlistHandler(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	watchlist, ok := cf.loadWatchlist(ctx, c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, watchlist)
+}
+
+// RenameWatchlistHandler changes the name of a watchlist
+func (cf *concallFetcher) RenameWatchlistHandler(c *gin.Context) {
+	var req watchlistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid request body: %v", err)})
+		return
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name is required"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	watchlist, ok := cf.loadWatchlist(ctx, c)
+	if !ok {
+		return
+	}
+	if err := cf.watchlistRepo.Rename(ctx, watchlist.ID, name); err != nil {
+		writeWatchlistError(c, "Failed to rename watchlist", err)
+		return
+	}
+
+	watchlist.Name = name
+	c.JSON(http.StatusOK, watchlist)
+}
+
+// DeleteWatchlistHandler removes a watchlist
+func (cf *concallFetcher) DeleteWatchlistHandler(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	watchlist, ok := cf.loadWatchlist(ctx, c)
+	if !ok {
+		return
+	}
+	if err := cf.watchlistRepo.Delete(ctx, watchlist.ID); err != nil {
+		writeWatchlistError(c, "Failed to delete watchlist", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"id": watchlist.ID.Hex(), "deleted": true})
+}
+
+// AddWatchlistCompanyHandler adds a company to a watchlist by scrip code or by
+// name. A name has to match a single company among the stored concalls.
+func (cf *concallFetcher) AddWatchlistCompanyHandler(c *gin.Context) {
+	var req watchlistCompanyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid request body: %v", err)})
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.ScripCode < 0 || (req.ScripCode == 0 && req.Name == "") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "either scrip_code or name is required"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	watchlist, ok := cf.loadWatchlist(ctx, c)
+	if !ok {
+		return
+	}
+
+	company, err := cf.resolveCompany(ctx, req.ScripCode, req.Name)
+	if err != nil {
+		var ambiguous *ambiguousCompanyError
+		switch {
+		case errors.Is(err, errCompanyNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.As(err, &ambiguous):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "candidates": ambiguous.Candidates})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to look up company",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	watchlist, err = cf.watchlistRepo.AddCompany(ctx, watchlist.ID, domain.WatchedCompany{
+		ScripCode: company.ScripCode,
+		Name:      company.Name,
+		AddedAt:   time.Now(),
+	})
+	if err != nil {
+		if errors.Is(err, domain.ErrWatchlistFull) {
+			c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("a watchlist holds at most %d companies", domain.MaxWatchlistCompanies)})
+			return
+		}
+		writeWatchlistError(c, "Failed to add company", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, watchlist)
+}
+
+// RemoveWatchlistCompanyHandler removes a company from a watchlist by scrip code
+func (cf *concallFetcher) RemoveWatchlistCompanyHandler(c *gin.Context) {
+	scripCode, err := strconv.Atoi(c.Param("scrip_code"))
+	if err != nil || scripCode <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid scrip code"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	watchlist, ok := cf.loadWatchlist(ctx, c)
+	if !ok {
+		return
+	}
+	watchlist, err = cf.watchlistRepo.RemoveCompany(ctx, watchlist.ID, scripCode)
+	if err != nil {
+		writeWatchlistError(c, "Failed to remove company", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, watchlist)
+}
+
+// WatchlistConcallsHandler returns the latest guidance of each company on a
+// watchlist, newest first, paginated like ListConcallHandler
+func (cf *concallFetcher) WatchlistConcallsHandler(c *gin.Context) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page <= 0 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "12"))
+	if err != nil || limit <= 0 {
+		limit = 12
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	watchlist, ok := cf.loadWatchlist(ctx, c)
+	if !ok {
+		return
+	}
+
+	results, totalCount, err := cf.latestGuidance(ctx, watchlist.ScripCodes(), page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to query MongoDB",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	totalPages := (totalCount + int64(limit) - 1) / int64(limit)
+
+	c.JSON(http.StatusOK, gin.H{
+		"meta": gin.H{
+			"watchlist":  watchlist.ID.Hex(),
+			"companies":  len(watchlist.Companies),
+			"page":       page,
+			"limit":      limit,
+			"total":      totalCount,
+			"totalPages": totalPages,
+		},
+		"data": results,
+	})
+}
+
+// latestGuidance returns a page of the most recent concall with guidance of
+// each company, newest first, and how many companies have one
+func (cf *concallFetcher) latestGuidance(ctx context.Context, scripCodes []int, page, limit int) ([]domain.ConcallLite, int64, error) {
+	results := []domain.ConcallLite{}
+	if len(scripCodes) == 0 {
+		return results, 0, nil
+	}
+
+	pipeline := []bson.M{
+		{"$match": bson.M{
+			"scrip_code": bson.M{"$in": scripCodes},
+			"guidance":   bson.M{"$ne": "NA"},
+		}},
+		{"$sort": bson.D{{Key: "scrip_code", Value: 1}, {Key: "date", Value: -1}}},
+		{"$group": bson.M{"_id": "$scrip_code", "doc": bson.M{"$first": "$$ROOT"}}},
+		{"$replaceRoot": bson.M{"newRoot": "$doc"}},
+		{"$sort": bson.D{{Key: "date", Value: -1}, {Key: "scrip_code", Value: 1}}},
+		{"$facet": bson.M{
+			"data": []bson.M{
+				{"$skip": (page - 1) * limit},
+				{"$limit": limit},
+			},
+			"total": []bson.M{{"$count": "count"}},
+		}},
+	}
+
+	cursor, err := cf.repo.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var facets []struct {
+		Data  []domain.ConcallLite `bson:"data"`
+		Total []struct {
+			Count int64 `bson:"count"`
+		} `bson:"total"`
+	}
+	if err := cursor.All(ctx, &facets); err != nil {
+		return nil, 0, fmt.Errorf("failed to decode latest guidance: %w", err)
+	}
+	if len(facets) == 0 || len(facets[0].Total) == 0 {
+		return results, 0, nil
+	}
+
+	results = facets[0].Data
+	for i := range results {
+		results[i].Name = strings.TrimSuffix(results[i].Name, "-$")
+	}
+	return results, facets[0].Total[0].Count, nil
+}
+
+// loadWatchlist fetches the watchlist named by the :id parameter, writing the
+// error response and returning false when it is missing or belongs to someone
+// else. Admins may read and change any watchlist.
+func (cf *concallFetcher) loadWatchlist(ctx context.Context, c *gin.Context) (*domain.Watchlist, bool) {
+	return cf.ownWatchlist(ctx, c, c.Param("id"))
+}
+
+// ownWatchlist is loadWatchlist for a watchlist ID given elsewhere in the request
+func (cf *concallFetcher) ownWatchlist(ctx context.Context, c *gin.Context, hexID string) (*domain.Watchlist, bool) {
+	id, err := primitive.ObjectIDFromHex(hexID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid watchlist id"})
+		return nil, false
+	}
+
+	watchlist, err := cf.watchlistRepo.FindByID(ctx, id)
+	if err != nil {
+		writeWatchlistError(c, "Failed to fetch watchlist", err)
+		return nil, false
+	}
+
+	principal := middleware.CurrentPrincipal(c)
+	if watchlist.Owner != principal.Subject && !principal.Role.Allows(domain.RoleAdmin) {
+		// Not telling other users which watchlists exist
+		c.JSON(http.StatusNotFound, gin.H{"error": "watchlist not found"})
+		return nil, false
+	}
+	return watchlist, true
+}
+
+func writeWatchlistError(c *gin.Context, message string, err error) {
+	if errors.Is(err, domain.ErrWatchlistNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "watchlist not found"})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"error":   message,
+		"details": err.Error(),
+	})
+}
+
+// resolveCompany finds a company among the stored concalls by scrip code or,
+// failing an exact case-insensitive match, by a unique partial name match
+func (cf *concallFetcher) resolveCompany(ctx context.Context, scripCode int, name string) (*companyMatch, error) {
+	if scripCode > 0 {
+		matches, err := cf.findCompanies(ctx, bson.M{"scrip_code": scripCode}, maxCompanyCandidates+1)
+		if err != nil {
+			return nil, err
+		}
+		if len(matches) == 0 {
+			return nil, errCompanyNotFound
+		}
+		return &matches[0], nil
+	}
+
+	// Summaries stored before scrip codes were recorded cannot be watched
+	escaped := regexp.QuoteMeta(name)
+	exact, err := cf.findCompanies(ctx, bson.M{
+		"scrip_code": bson.M{"$gt": 0},
+		"name":       bson.M{"$regex": "^" + escaped + `(-\$)?$`, "$options": "i"},
+	}, maxCompanyCandidates+1)
+	if err != nil {
+		return nil, err
+	}
+	if len(exact) == 1 {
+		return &exact[0], nil
+	}
+
+	matches := exact
+	if len(matches) == 0 {
+		matches, err = cf.findCompanies(ctx, bson.M{
+			"scrip_code": bson.M{"$gt": 0},
+			"name":       bson.M{"$regex": escaped, "$options": "i"},
+		}, maxCompanyCandidates+1)
+		if err != nil {
+			return nil, err
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, errCompanyNotFound
+	case 1:
+		return &matches[0], nil
+	}
+	if len(matches) > maxCompanyCandidates {
+		matches = matches[:maxCompanyCandidates]
+	}
+	return nil, &ambiguousCompanyError{Candidates: matches}
+}
+
+// findCompanies returns the companies with concalls matching match, with the
+// name of their latest concall, by name, up to limit of them unless it is 0
+func (cf *concallFetcher) findCompanies(ctx context.Context, match bson.M, limit int) ([]companyMatch, error) {
+	pipeline := []bson.M{
+		{"$match": match},
+		{"$sort": bson.M{"date": -1}},
+		{"$group": bson.M{"_id": "$scrip_code", "name": bson.M{"$first": "$name"}}},
+		{"$sort": bson.M{"name": 1}},
+	}
+	if limit > 0 {
+		pipeline = append(pipeline, bson.M{"$limit": limit})
+	}
+
+	cursor, err := cf.repo.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	matches := []companyMatch{}
+	if err := cursor.All(ctx, &matches); err != nil {
+		return nil, fmt.Errorf("failed to decode companies: %w", err)
+	}
+	for i := range matches {
+		matches[i].Name = strings.TrimSuffix(matches[i].Name, "-$")
+	}
+	return matches, nil
+}
+
+// ingestionTopics are the topics an ingestion event about a company goes to:
+// the ingestion topic, the company's topic and those of the watchlists following it
+func (cf *concallFetcher) ingestionTopics(scripCode int) []string {
+	topics := ws.IngestionTopics(scripCode)
+	if scripCode <= 0 || cf.watchlistRepo == nil {
+		return topics
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	ids, err := cf.watchlistRepo.FindIDsByScripCode(ctx, scripCode)
+	if err != nil {
+		log.Printf("⚠️ Failed to find watchlists following %d: %v", scripCode, err)
+		return topics
+	}
+	for _, id := range ids {
+		topics = append(topics, ws.WatchlistTopic(id.Hex()))
+	}
+	return topics
+}
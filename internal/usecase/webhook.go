@@ -0,0 +1,327 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"concall-analyser/internal/domain"
+	"concall-analyser/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type webhookRequest struct {
+	Name   *string               `json:"name"`
+	URL    *string               `json:"url"`
+	Secret *string               `json:"secret"`
+	Filter *domain.WebhookFilter `json:"filter"`
+	Active *bool                 `json:"active"`
+}
+
+// ListWebhooksHandler lists the registered webhooks
+func (cf *concallFetcher) ListWebhooksHandler(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	webhooks, err := cf.webhookRepo.List(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to list webhooks",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": webhooks})
+}
+
+// CreateWebhookHandler registers a webhook. A secret is generated when none is
+// given; it is only ever returned here.
+func (cf *concallFetcher) CreateWebhookHandler(c *gin.Context) {
+	var req webhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid request body: %v", err)})
+		return
+	}
+	if req.URL == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "url is required"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	webhook := &domain.Webhook{
+		Active:    true,
+		CreatedBy: middleware.CurrentPrincipal(c).Subject,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := cf.applyWebhookRequest(ctx, webhook, req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if webhook.Secret == "" {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate secret", "details": err.Error()})
+			return
+		}
+		webhook.Secret = hex.EncodeToString(secret)
+	}
+
+	if err := cf.webhookRepo.Create(ctx, webhook); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to create webhook",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	log.Printf("🪝 %s registered webhook %s for %s", webhook.CreatedBy, webhook.ID.Hex(), webhook.URL)
+	c.JSON(http.StatusCreated, gin.H{
+		"webhook": webhook,
+		"secret":  webhook.Secret,
+	})
+}
+
+// GetWebhookHandler returns one webhook
+func (cf *concallFetcher) GetWebhookHandler(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	webhook, ok := cf.loadWebhook(ctx, c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, webhook)
+}
+
+// UpdateWebhookHandler changes the fields of a webhook given in the body
+func (cf *concallFetcher) UpdateWebhookHandler(c *gin.Context) {
+	var req webhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid request body: %v", err)})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	webhook, ok := cf.loadWebhook(ctx, c)
+	if !ok {
+		return
+	}
+	if err := cf.applyWebhookRequest(ctx, webhook, req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	fields := bson.M{
+		"name":   webhook.Name,
+		"url":    webhook.URL,
+		"secret": webhook.Secret,
+		"filter": webhook.Filter,
+		"active": webhook.Active,
+	}
+	if err := cf.webhookRepo.Update(ctx, webhook.ID, fields); err != nil {
+		writeWebhookError(c, "Failed to update webhook", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, webhook)
+}
+
+// DeleteWebhookHandler removes a webhook. Pending retries to it are abandoned.
+func (cf *concallFetcher) DeleteWebhookHandler(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid webhook id"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := cf.webhookRepo.Delete(ctx, id); err != nil {
+		writeWebhookError(c, "Failed to delete webhook", err)
+		return
+	}
+
+	log.Printf("🪝 %s deleted webhook %s", middleware.CurrentPrincipal(c).Subject, id.Hex())
+	c.JSON(http.StatusOK, gin.H{"id": id.Hex(), "deleted": true})
+}
+
+// ListWebhookDeliveriesHandler lists the deliveries of a webhook with every
+// attempt made, newest first
+func (cf *concallFetcher) ListWebhookDeliveriesHandler(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid webhook id"})
+		return
+	}
+
+	filter := bson.M{}
+	if stateStr := c.Query("state"); stateStr != "" {
+		state := domain.DeliveryState(strings.ToLower(strings.TrimSpace(stateStr)))
+		switch state {
+		case domain.DeliveryPending, domain.DeliverySucceeded, domain.DeliveryFailed:
+			filter["state"] = state
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": fmt.Sprintf("invalid 'state' %q, expected pending, succeeded or failed", stateStr),
+			})
+			return
+		}
+	}
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page <= 0 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit <= 0 {
+		limit = 20
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	findOpts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit))
+
+	deliveries, err := cf.deliveryRepo.FindByWebhook(ctx, id, filter, findOpts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to query webhook deliveries",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	totalCount, err := cf.deliveryRepo.CountByWebhook(ctx, id, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to count webhook deliveries",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"meta": gin.H{
+			"page":       page,
+			"limit":      limit,
+			"total":      totalCount,
+			"totalPages": (totalCount + int64(limit) - 1) / int64(limit),
+		},
+		"data": deliveries,
+	})
+}
+
+// TestWebhookHandler sends a ping to a webhook once and returns the delivery,
+// whether or not the webhook is active
+func (cf *concallFetcher) TestWebhookHandler(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	webhook, ok := cf.loadWebhook(ctx, c)
+	if !ok {
+		return
+	}
+
+	delivery, err := cf.webhooks.Test(ctx, webhook)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to send test delivery",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, delivery)
+}
+
+// applyWebhookRequest validates the fields given in req and sets them on webhook
+func (cf *concallFetcher) applyWebhookRequest(ctx context.Context, webhook *domain.Webhook, req webhookRequest) error {
+	if req.Name != nil {
+		webhook.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.URL != nil {
+		target, err := url.Parse(strings.TrimSpace(*req.URL))
+		if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+			return fmt.Errorf("url must be an absolute http or https URL")
+		}
+		webhook.URL = target.String()
+	}
+	if req.Secret != nil {
+		webhook.Secret = *req.Secret
+	}
+	if req.Active != nil {
+		webhook.Active = *req.Active
+	}
+	if req.Filter != nil {
+		for _, code := range req.Filter.ScripCodes {
+			if code <= 0 {
+				return fmt.Errorf("invalid scrip code %d in filter", code)
+			}
+		}
+		if req.Filter.WatchlistID != "" {
+			id, err := primitive.ObjectIDFromHex(req.Filter.WatchlistID)
+			if err != nil {
+				return fmt.Errorf("invalid watchlist_id in filter")
+			}
+			if _, err := cf.watchlistRepo.FindByID(ctx, id); err != nil {
+				if errors.Is(err, domain.ErrWatchlistNotFound) {
+					return fmt.Errorf("watchlist %s does not exist", req.Filter.WatchlistID)
+				}
+				return err
+			}
+		}
+		webhook.Filter = *req.Filter
+	}
+	return nil
+}
+
+// loadWebhook fetches the webhook named by the :id parameter, writing the error
+// response and returning false when it cannot
+func (cf *concallFetcher) loadWebhook(ctx context.Context, c *gin.Context) (*domain.Webhook, bool) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid webhook id"})
+		return nil, false
+	}
+
+	webhook, err := cf.webhookRepo.FindByID(ctx, id)
+	if err != nil {
+		writeWebhookError(c, "Failed to fetch webhook", err)
+		return nil, false
+	}
+	return webhook, true
+}
+
+func writeWebhookError(c *gin.Context, message string, err error) {
+	if errors.Is(err, domain.ErrWebhookNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "webhook not found"})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"error":   message,
+		"details": err.Error(),
+	})
+}
@@ -0,0 +1,119 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// memoryBusBuffer is how many messages the in-memory bus holds for each hub
+const memoryBusBuffer = 1024
+
+// Message is a hub message on its way from a publisher to the hubs of every replica
+type Message struct {
+	Type      string
+	Topics    []string
+	Data      json.RawMessage
+	Timestamp time.Time
+}
+
+// Bus carries hub messages between replicas, so that clients see the same
+// updates whichever replica they are connected to
+type Bus interface {
+	// Publish hands msg to the hubs of every replica, this one included
+	Publish(ctx context.Context, msg Message) error
+	// Subscribe calls deliver with every message published on any replica
+	// until ctx ends or the bus fails
+	Subscribe(ctx context.Context, deliver func(Message)) error
+	// Name names the implementation for logs, e.g. "memory" or "mongo"
+	Name() string
+}
+
+// memoryBus only reaches the hubs of this process; it backs single-replica
+// deployments and tests, where several hubs sharing one bus stand in for replicas
+type memoryBus struct {
+	mu          sync.Mutex
+	subscribers map[*memorySubscriber]bool
+	// backlog holds what was published before any hub subscribed
+	backlog []Message
+}
+
+type memorySubscriber struct {
+	messages chan Message
+	done     chan struct{}
+}
+
+// NewMemoryBus creates a bus that delivers every message to each subscriber in this process
+func NewMemoryBus() Bus {
+	return &memoryBus{
+		subscribers: make(map[*memorySubscriber]bool),
+	}
+}
+
+// Publish waits while a subscriber's buffer is full, holding back the publisher
+// rather than losing the message
+func (b *memoryBus) Publish(ctx context.Context, msg Message) error {
+	b.mu.Lock()
+	if len(b.subscribers) == 0 {
+		if len(b.backlog) >= memoryBusBuffer {
+			b.mu.Unlock()
+			return fmt.Errorf("memory bus has no subscriber and %d messages waiting", len(b.backlog))
+		}
+		b.backlog = append(b.backlog, msg)
+		b.mu.Unlock()
+		return nil
+	}
+	subscribers := make([]*memorySubscriber, 0, len(b.subscribers))
+	for sub := range b.subscribers {
+		subscribers = append(subscribers, sub)
+	}
+	b.mu.Unlock()
+
+	for _, sub := range subscribers {
+		select {
+		case sub.messages <- msg:
+		case <-sub.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+func (b *memoryBus) Subscribe(ctx context.Context, deliver func(Message)) error {
+	sub := &memorySubscriber{
+		messages: make(chan Message, memoryBusBuffer),
+		done:     make(chan struct{}),
+	}
+
+	b.mu.Lock()
+	backlog := b.backlog
+	b.backlog = nil
+	b.subscribers[sub] = true
+	b.mu.Unlock()
+
+	defer func() {
+		b.mu.Lock()
+		delete(b.subscribers, sub)
+		b.mu.Unlock()
+		close(sub.done)
+	}()
+
+	for _, msg := range backlog {
+		deliver(msg)
+	}
+	for {
+		select {
+		case msg := <-sub.messages:
+			deliver(msg)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (b *memoryBus) Name() string {
+	return "memory"
+}
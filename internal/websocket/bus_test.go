@@ -0,0 +1,213 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// testWait bounds how long a test waits for something that should happen
+const testWait = 2 * time.Second
+
+// startHub runs a hub on bus for the rest of the test binary
+func startHub(t *testing.T, bus Bus, opts HubOptions) *Hub {
+	t.Helper()
+	hub := NewHub(bus, opts)
+	go hub.Run()
+	return hub
+}
+
+// waitForSubscribers waits until n hubs follow a memory bus, so that nothing
+// published afterwards is missed by any of them
+func waitForSubscribers(t *testing.T, bus Bus, n int) {
+	t.Helper()
+	memory := bus.(*memoryBus)
+	deadline := time.Now().Add(testWait)
+	for time.Now().Before(deadline) {
+		memory.mu.Lock()
+		count := len(memory.subscribers)
+		memory.mu.Unlock()
+		if count >= n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("%d hubs did not subscribe to the bus in time", n)
+}
+
+// connect registers a fake client without a connection and subscribes it to
+// topics, consuming the acknowledgement
+func connect(t *testing.T, hub *Hub, topics ...string) *Client {
+	t.Helper()
+	client := hub.newClient(nil)
+	hub.register <- client
+	hub.subscriptions <- subscription{client: client, request: ClientRequest{Action: ActionSubscribe, Topics: topics}}
+	if env := receive(t, client); env.Type != MessageSubscribed {
+		t.Fatalf("subscribe reply = %s, want %s", env.Type, MessageSubscribed)
+	}
+	return client
+}
+
+// receivedEnvelope is an envelope as a client decodes it
+type receivedEnvelope struct {
+	Type   string          `json:"type"`
+	Topics []string        `json:"topics"`
+	Data   json.RawMessage `json:"data"`
+}
+
+// receive reads the next queued message of a client
+func receive(t *testing.T, client *Client) receivedEnvelope {
+	t.Helper()
+	select {
+	case f, ok := <-client.send:
+		if !ok {
+			t.Fatal("client was disconnected")
+		}
+		return decode(t, f)
+	case <-time.After(testWait):
+		t.Fatal("no message arrived")
+	}
+	return receivedEnvelope{}
+}
+
+// receiveLatest waits for coalesced messages and returns the pending ones
+func receiveLatest(t *testing.T, client *Client) []receivedEnvelope {
+	t.Helper()
+	select {
+	case <-client.wake:
+	case <-time.After(testWait):
+		t.Fatal("no coalesced message arrived")
+	}
+	var envs []receivedEnvelope
+	for _, f := range client.takeLatest() {
+		envs = append(envs, decode(t, f))
+	}
+	return envs
+}
+
+func decode(t *testing.T, f frame) receivedEnvelope {
+	t.Helper()
+	var env receivedEnvelope
+	if err := json.Unmarshal(f.message, &env); err != nil {
+		t.Fatalf("undecodable message %q: %v", f.message, err)
+	}
+	return env
+}
+
+// expectSilence fails when a client gets anything within a short wait
+func expectSilence(t *testing.T, client *Client) {
+	t.Helper()
+	select {
+	case f := <-client.send:
+		t.Fatalf("unexpected message %s", f.message)
+	case <-client.wake:
+		t.Fatalf("unexpected coalesced messages %d", len(client.takeLatest()))
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestMemoryBusConnectsHubs(t *testing.T) {
+	bus := NewMemoryBus()
+	hubA := startHub(t, bus, HubOptions{})
+	hubB := startHub(t, bus, HubOptions{})
+	waitForSubscribers(t, bus, 2)
+
+	onA := connect(t, hubA, TopicIngestion)
+	onB := connect(t, hubB, TopicIngestion, CompanyTopic(500325))
+
+	hubA.Publish(IngestionTopics(500325), EventRunStarted, RunStarted{JobID: "a", Total: 1})
+	for name, client := range map[string]*Client{"hub A": onA, "hub B": onB} {
+		if env := receive(t, client); env.Type != EventRunStarted {
+			t.Errorf("client on %s got %s, want %s", name, env.Type, EventRunStarted)
+		}
+		// Published to two topics the client on hub B follows, still delivered once
+		expectSilence(t, client)
+	}
+
+	hubB.Publish([]string{TopicIngestion}, EventRunFinished, RunFinished{JobID: "b"})
+	for name, client := range map[string]*Client{"hub A": onA, "hub B": onB} {
+		if env := receive(t, client); env.Type != EventRunFinished {
+			t.Errorf("client on %s got %s, want %s", name, env.Type, EventRunFinished)
+		}
+		expectSilence(t, client)
+	}
+}
+
+func TestMemoryBusKeepsMessagesUntilSubscribed(t *testing.T) {
+	bus := NewMemoryBus()
+	if err := bus.Publish(context.Background(), Message{Type: EventRunStarted, Topics: []string{TopicIngestion}}); err != nil {
+		t.Fatalf("Publish() without subscribers error = %v", err)
+	}
+
+	delivered := make(chan Message, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go bus.Subscribe(ctx, func(msg Message) { delivered <- msg })
+
+	select {
+	case msg := <-delivered:
+		if msg.Type != EventRunStarted {
+			t.Errorf("delivered %s, want %s", msg.Type, EventRunStarted)
+		}
+	case <-time.After(testWait):
+		t.Fatal("message published before subscribing was lost")
+	}
+}
+
+// watchingBus stands in for a bus that derives some message types from the
+// database writes it watches, like the Mongo one: publishing those is a no-op
+// and write delivers them as a watched write would
+type watchingBus struct {
+	Bus
+	sourced map[string]bool
+}
+
+func (b *watchingBus) Publish(ctx context.Context, msg Message) error {
+	if b.sourced[msg.Type] {
+		return nil
+	}
+	return b.Bus.Publish(ctx, msg)
+}
+
+func (b *watchingBus) write(t *testing.T, msg Message) {
+	t.Helper()
+	if err := b.Bus.Publish(context.Background(), msg); err != nil {
+		t.Fatalf("watched write error = %v", err)
+	}
+}
+
+func TestSourcedTypesDeliveredOnce(t *testing.T) {
+	memory := NewMemoryBus()
+	bus := &watchingBus{Bus: memory, sourced: map[string]bool{
+		MessageAnalyticsUpdate: true,
+		EventConcallAdded:      true,
+	}}
+	hubA := startHub(t, bus, HubOptions{})
+	hubB := startHub(t, bus, HubOptions{})
+	waitForSubscribers(t, memory, 2)
+
+	onA := connect(t, hubA, TopicAnalytics, TopicIngestion)
+	onB := connect(t, hubB, TopicAnalytics, TopicIngestion)
+
+	// The replica that counted the visit and stored the concall also publishes them
+	hubA.BroadcastAnalyticsUpdate(42)
+	hubA.Publish([]string{TopicIngestion}, EventConcallAdded, ConcallAdded{})
+	added, _ := json.Marshal(ConcallAdded{})
+	visits, _ := json.Marshal(AnalyticsUpdate{TotalVisits: 42})
+	bus.write(t, Message{Type: EventConcallAdded, Topics: []string{TopicIngestion}, Data: added, Timestamp: time.Now()})
+	bus.write(t, Message{Type: MessageAnalyticsUpdate, Topics: []string{TopicAnalytics}, Data: visits, Timestamp: time.Now()})
+
+	for name, client := range map[string]*Client{"hub A": onA, "hub B": onB} {
+		if env := receive(t, client); env.Type != EventConcallAdded {
+			t.Errorf("client on %s got %s, want %s", name, env.Type, EventConcallAdded)
+		}
+		if envs := receiveLatest(t, client); len(envs) != 1 || envs[0].Type != MessageAnalyticsUpdate {
+			t.Errorf("client on %s got coalesced %+v, want one %s", name, envs, MessageAnalyticsUpdate)
+		}
+		expectSilence(t, client)
+	}
+	if failures := hubA.Stats().PublishFailures; failures != 0 {
+		t.Errorf("PublishFailures = %d, want 0", failures)
+	}
+}
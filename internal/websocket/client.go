@@ -1,9 +1,14 @@
 package websocket
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
 	"log"
 	"time"
 
+	"concall-analyser/internal/domain"
+
 	"github.com/gorilla/websocket"
 )
 
@@ -11,7 +16,7 @@ const (
 	writeWait     = 10 * time.Second
 	pongWait      = 60 * time.Second
 	pingPeriod    = (pongWait * 9) / 10
-	maxMessageSize = 512
+	maxMessageSize = 4096
 )
 
 func (c *Client) readPump() {
@@ -28,14 +33,31 @@ func (c *Client) readPump() {
 	})
 
 	for {
-		_, _, err := c.conn.ReadMessage()
+		_, data, err := c.conn.ReadMessage()
 		if err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 				log.Printf("WebSocket error: %v", err)
 			}
 			break
 		}
+
+		// Requests are applied by the hub, which owns the subscriptions
+		c.hub.subscriptions <- c.request(data)
+	}
+}
+
+// request decodes a client request. Watchlist topics are authorized here
+// rather than by the hub, whose loop must not wait on the database.
+func (c *Client) request(data []byte) subscription {
+	sub := subscription{client: c}
+	if err := json.Unmarshal(data, &sub.request); err != nil {
+		sub.err = fmt.Errorf("invalid request: %v", err)
+		return sub
 	}
+	if sub.request.Action == ActionSubscribe {
+		sub.err = c.hub.authorizeTopics(context.Background(), c.principal, sub.request.Topics)
+	}
+	return sub
 }
 
 func (c *Client) writePump() {
@@ -47,7 +69,7 @@ func (c *Client) writePump() {
 
 	for {
 		select {
-		case message, ok := <-c.send:
+		case f, ok := <-c.send:
 			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
 			if !ok {
 				c.conn.WriteMessage(websocket.CloseMessage, []byte{})
@@ -59,7 +81,7 @@ func (c *Client) writePump() {
 				log.Printf("Error creating writer: %v", err)
 				return
 			}
-			if _, err := w.Write(message); err != nil {
+			if _, err := w.Write(f.message); err != nil {
 				log.Printf("Error writing message: %v", err)
 				w.Close()
 				return
@@ -68,7 +90,7 @@ func (c *Client) writePump() {
 			n := len(c.send)
 			for i := 0; i < n; i++ {
 				w.Write([]byte{'\n'})
-				w.Write(<-c.send)
+				w.Write((<-c.send).message)
 			}
 
 			if err := w.Close(); err != nil {
@@ -77,6 +99,16 @@ func (c *Client) writePump() {
 			}
 			log.Printf("Message sent to client successfully")
 
+		case <-c.wake:
+			// Only the newest message of each coalesced type is written
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			for _, f := range c.takeLatest() {
+				if err := c.conn.WriteMessage(websocket.TextMessage, f.message); err != nil {
+					log.Printf("Error writing message: %v", err)
+					return
+				}
+			}
+
 		case <-ticker.C:
 			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
 			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
@@ -87,12 +119,10 @@ func (c *Client) writePump() {
 	}
 }
 
-func ServeWs(hub *Hub, conn *websocket.Conn) {
-	client := &Client{
-		hub:  hub,
-		conn: conn,
-		send: make(chan []byte, 256),
-	}
+// ServeWs serves a WebSocket client authenticated as principal
+func ServeWs(hub *Hub, conn *websocket.Conn, principal *domain.Principal) {
+	client := hub.newClient(conn)
+	client.principal = principal
 
 	client.hub.register <- client
 	go client.writePump()
@@ -0,0 +1,106 @@
+package websocket
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EnvelopeVersion is bumped whenever the envelope or a payload changes in a
+// way existing clients cannot read
+const EnvelopeVersion = 1
+
+// Envelope wraps every message the server sends:
+//
+//	{"v": 1, "type": "analytics_update", "topics": ["analytics"], "data": {"total_visits": 42}, "ts": "2025-01-02T15:04:05Z"}
+//
+// Type names the payload in Data, Topics are the topics it was published to.
+// Replies to client requests carry no topics.
+type Envelope struct {
+	Version   int         `json:"v"`
+	Type      string      `json:"type"`
+	Topics    []string    `json:"topics,omitempty"`
+	Data      interface{} `json:"data,omitempty"`
+	Timestamp time.Time   `json:"ts"`
+}
+
+// Client request actions
+const (
+	ActionSubscribe   = "subscribe"
+	ActionUnsubscribe = "unsubscribe"
+)
+
+// Types of the replies to client requests
+const (
+	MessageSubscribed   = "subscribed"
+	MessageUnsubscribed = "unsubscribed"
+	MessageError        = "error"
+	// MessageResync tells an event stream client that messages it missed are
+	// gone and it should reload its state
+	MessageResync = "resync"
+)
+
+// ClientRequest is what clients send to change their subscriptions:
+//
+//	{"action": "subscribe", "topics": ["analytics", "company:500325"], "id": "1"}
+//
+// ID is optional and echoed in the reply.
+type ClientRequest struct {
+	Action string   `json:"action"`
+	Topics []string `json:"topics"`
+	ID     string   `json:"id,omitempty"`
+}
+
+// SubscriptionAck acknowledges a request with every topic the client is
+// subscribed to after applying it
+type SubscriptionAck struct {
+	ID     string   `json:"id,omitempty"`
+	Topics []string `json:"topics"`
+}
+
+// ErrorReply rejects a client request
+type ErrorReply struct {
+	ID    string `json:"id,omitempty"`
+	Error string `json:"error"`
+}
+
+// Topics clients can subscribe to
+const (
+	// TopicAnalytics carries analytics_update messages
+	TopicAnalytics = "analytics"
+	// TopicIngestion carries every ingestion event
+	TopicIngestion = "ingestion"
+
+	companyTopicPrefix   = "company:"
+	watchlistTopicPrefix = "watchlist:"
+)
+
+// CompanyTopic carries the ingestion events of one company, by BSE scrip code
+func CompanyTopic(scripCode int) string {
+	return companyTopicPrefix + strconv.Itoa(scripCode)
+}
+
+// WatchlistTopic carries the ingestion events of the companies on a watchlist
+func WatchlistTopic(watchlistID string) string {
+	return watchlistTopicPrefix + watchlistID
+}
+
+// validateTopic checks that a topic requested by a client is one the server publishes to
+func validateTopic(topic string) error {
+	switch {
+	case topic == TopicAnalytics, topic == TopicIngestion:
+		return nil
+	case strings.HasPrefix(topic, companyTopicPrefix):
+		if code, err := strconv.Atoi(strings.TrimPrefix(topic, companyTopicPrefix)); err != nil || code <= 0 {
+			return fmt.Errorf("invalid topic %q: company topics take a numeric scrip code", topic)
+		}
+		return nil
+	case strings.HasPrefix(topic, watchlistTopicPrefix):
+		if strings.TrimPrefix(topic, watchlistTopicPrefix) == "" {
+			return fmt.Errorf("invalid topic %q: watchlist topics take a watchlist id", topic)
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown topic %q", topic)
+}
@@ -0,0 +1,83 @@
+package websocket
+
+import (
+	"time"
+
+	"concall-analyser/internal/domain"
+)
+
+// Types of the ingestion events published while a job works through announcements.
+// Every event goes to TopicIngestion; per-announcement events also go to the
+// company's topic.
+const (
+	EventRunStarted             = "run_started"
+	EventAnnouncementDownloaded = "announcement_downloaded"
+	EventAnnouncementSummarized = "announcement_summarized"
+	EventAnnouncementFailed     = "announcement_failed"
+	EventRunFinished            = "run_finished"
+	// EventConcallAdded is published for every new summary, whichever run or replica stored it
+	EventConcallAdded = "concall_added"
+)
+
+// IngestionTopics are the topics an event about one company goes to
+func IngestionTopics(scripCode int) []string {
+	topics := []string{TopicIngestion}
+	if scripCode > 0 {
+		topics = append(topics, CompanyTopic(scripCode))
+	}
+	return topics
+}
+
+// RunStarted is published when a job starts processing its announcements
+type RunStarted struct {
+	JobID     string    `json:"job_id"`
+	Total     int       `json:"total"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// AnnouncementDownloaded is published once an announcement's PDF is downloaded
+// and waits to be summarized
+type AnnouncementDownloaded struct {
+	JobID     string `json:"job_id"`
+	NewsID    string `json:"news_id"`
+	Name      string `json:"name"`
+	ScripCode int    `json:"scrip_code,omitempty"`
+	Done      int    `json:"done"`
+	Total     int    `json:"total"`
+}
+
+// AnnouncementSummarized is published once a summary is stored, carrying the
+// concall as list_concalls returns it
+type AnnouncementSummarized struct {
+	JobID   string             `json:"job_id"`
+	Concall domain.ConcallLite `json:"concall"`
+	Done    int                `json:"done"`
+	Total   int                `json:"total"`
+}
+
+// AnnouncementFailed is published when an announcement could not be processed
+type AnnouncementFailed struct {
+	JobID     string `json:"job_id"`
+	NewsID    string `json:"news_id"`
+	Name      string `json:"name"`
+	ScripCode int    `json:"scrip_code,omitempty"`
+	Error     string `json:"error"`
+	Done      int    `json:"done"`
+	Total     int    `json:"total"`
+}
+
+// RunFinished is published when a job reaches its terminal state, with the
+// totals of the whole job
+type RunFinished struct {
+	JobID      string             `json:"job_id"`
+	Kind       domain.JobKind     `json:"kind"`
+	State      domain.JobState    `json:"state"`
+	Totals     domain.JobProgress `json:"totals"`
+	Errors     []string           `json:"errors,omitempty"`
+	FinishedAt time.Time          `json:"finished_at"`
+}
+
+// ConcallAdded carries a newly stored concall as list_concalls returns it
+type ConcallAdded struct {
+	Concall domain.ConcallLite `json:"concall"`
+}
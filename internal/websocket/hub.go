@@ -1,107 +1,484 @@
 package websocket
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"log"
+	"sort"
+	"strconv"
 	"sync"
+	"sync/atomic"
+	"time"
+
+	"concall-analyser/internal/domain"
 
 	"github.com/gorilla/websocket"
 )
 
+const (
+	// maxTopicsPerClient bounds how many topics a single connection may follow
+	maxTopicsPerClient = 64
+	// publishTimeout bounds handing a message to the bus
+	publishTimeout = 5 * time.Second
+	// busRetryDelay is how long the hub waits before subscribing to a failed bus again
+	busRetryDelay = 5 * time.Second
+)
+
+// MessageAnalyticsUpdate carries the total visit count on TopicAnalytics
+const MessageAnalyticsUpdate = "analytics_update"
+
+// coalesced are the message types where only the newest one matters: a client
+// that has not written the previous one yet gets it replaced instead of queued
+var coalesced = map[string]bool{
+	MessageAnalyticsUpdate: true,
+}
+
+// Policies for clients whose send buffer is full
+const (
+	// SlowClientDrop skips the message for that client and keeps it connected
+	SlowClientDrop = "drop"
+	// SlowClientDisconnect closes the connection; the client has to reconnect and subscribe again
+	SlowClientDisconnect = "disconnect"
+)
+
+// HubOptions tunes the hub's buffers and how it treats clients that cannot keep up
+type HubOptions struct {
+	// ClientBuffer is how many messages are queued per client
+	ClientBuffer int
+	// BroadcastBuffer is how many messages wait to be routed by the hub
+	BroadcastBuffer int
+	// SlowClientPolicy is SlowClientDrop or SlowClientDisconnect
+	SlowClientPolicy string
+	// ReplayBuffer is how many recent messages are kept for event stream clients resuming with Last-Event-ID
+	ReplayBuffer int
+	// Watchlists finds who owns the watchlist behind a watchlist topic; without
+	// it only admins may subscribe to watchlist topics
+	Watchlists WatchlistFinder
+}
+
+// DefaultHubOptions are used for every option left at its zero value
+func DefaultHubOptions() HubOptions {
+	return HubOptions{
+		ClientBuffer:     256,
+		BroadcastBuffer:  1024,
+		SlowClientPolicy: SlowClientDrop,
+		ReplayBuffer:     500,
+	}
+}
+
+// HubStats counts what the hub did with the messages it routed since it started
+type HubStats struct {
+	Clients int64 `json:"clients"`
+	// Delivered messages were queued for a client
+	Delivered uint64 `json:"delivered"`
+	// Coalesced messages were replaced by a newer one before being written
+	Coalesced uint64 `json:"coalesced"`
+	// Dropped messages were skipped because a client's buffer was full
+	Dropped uint64 `json:"dropped"`
+	// Disconnected counts slow clients closed under SlowClientDisconnect
+	Disconnected uint64 `json:"disconnected"`
+	// PublishFailures counts messages the bus refused; they only reached this replica
+	PublishFailures uint64 `json:"publish_failures"`
+}
+
+// Hub routes messages to the clients subscribed to their topics. Published
+// messages go through the bus, so they reach the hubs of every replica.
+// Run is the only goroutine touching the client and topic maps; everything
+// else talks to it through the hub's channels.
 type Hub struct {
-	clients    map[*Client]bool
-	broadcast  chan []byte
-	register   chan *Client
-	unregister chan *Client
-	mu         sync.RWMutex
+	bus           Bus
+	opts          HubOptions
+	clients       map[*Client]bool
+	topics        map[string]map[*Client]bool
+	broadcast     chan outbound
+	register      chan *Client
+	unregister    chan *Client
+	subscriptions chan subscription
+	attach        chan attachment
+
+	// epoch tells message IDs of this hub apart from those of earlier processes
+	// and other replicas; seq numbers the routed messages
+	epoch  string
+	seq    uint64
+	replay *replayBuffer
+
+	clientCount     atomic.Int64
+	delivered       atomic.Uint64
+	coalesced       atomic.Uint64
+	dropped         atomic.Uint64
+	disconnected    atomic.Uint64
+	publishFailures atomic.Uint64
 }
 
 type Client struct {
-	hub  *Hub
+	hub *Hub
+	// conn is nil for event stream clients
 	conn *websocket.Conn
-	send chan []byte
+	send chan frame
+	// principal is who the client authenticated as, checked against watchlist topics
+	principal *domain.Principal
+	// topics is only touched by Hub.Run
+	topics map[string]bool
+
+	// latest holds the newest message of each coalesced type that is not
+	// written yet; wake tells writePump there is one
+	latestMu sync.Mutex
+	latest   map[string]frame
+	wake     chan struct{}
+}
+
+// frame is an encoded envelope and the ID the hub gave it. Replies to client
+// requests carry no ID.
+type frame struct {
+	id      string
+	message []byte
+}
+
+// outbound is an encoded envelope and the topics it goes to
+type outbound struct {
+	topics  []string
+	message []byte
+	// coalesceKey is set for messages that supersede earlier ones with the same key
+	coalesceKey string
+}
+
+// subscription is a request read from a client; err is set when it could not
+// be decoded or asks for topics the client may not follow
+type subscription struct {
+	client  *Client
+	request ClientRequest
+	err     error
 }
 
 type AnalyticsUpdate struct {
-	Type        string `json:"type"`
-	TotalVisits int64  `json:"total_visits"`
+	TotalVisits int64 `json:"total_visits"`
 }
 
-func NewHub() *Hub {
+// NewHub creates a hub fed by bus; a nil bus only reaches clients of this
+// process. Options left at zero take their DefaultHubOptions value.
+func NewHub(bus Bus, opts HubOptions) *Hub {
+	if bus == nil {
+		bus = NewMemoryBus()
+	}
+
+	defaults := DefaultHubOptions()
+	if opts.ClientBuffer <= 0 {
+		opts.ClientBuffer = defaults.ClientBuffer
+	}
+	if opts.BroadcastBuffer <= 0 {
+		opts.BroadcastBuffer = defaults.BroadcastBuffer
+	}
+	if opts.SlowClientPolicy == "" {
+		opts.SlowClientPolicy = defaults.SlowClientPolicy
+	}
+	if opts.ReplayBuffer <= 0 {
+		opts.ReplayBuffer = defaults.ReplayBuffer
+	}
+
 	return &Hub{
-		clients:    make(map[*Client]bool),
-		broadcast:  make(chan []byte),
-		register:   make(chan *Client),
-		unregister: make(chan *Client),
+		bus:           bus,
+		opts:          opts,
+		clients:       make(map[*Client]bool),
+		topics:        make(map[string]map[*Client]bool),
+		broadcast:     make(chan outbound, opts.BroadcastBuffer),
+		register:      make(chan *Client),
+		unregister:    make(chan *Client),
+		subscriptions: make(chan subscription),
+		attach:        make(chan attachment),
+		epoch:         strconv.FormatInt(time.Now().UnixNano(), 36),
+		replay:        newReplayBuffer(opts.ReplayBuffer),
+	}
+}
+
+// newClient creates a client with the hub's buffer size; it joins once registered
+func (h *Hub) newClient(conn *websocket.Conn) *Client {
+	return &Client{
+		hub:    h,
+		conn:   conn,
+		send:   make(chan frame, h.opts.ClientBuffer),
+		topics: make(map[string]bool),
+		latest: make(map[string]frame),
+		wake:   make(chan struct{}, 1),
 	}
 }
 
 func (h *Hub) Run() {
+	go h.consume()
+
 	for {
 		select {
 		case client := <-h.register:
-			h.mu.Lock()
 			h.clients[client] = true
-			h.mu.Unlock()
+			h.clientCount.Add(1)
 			log.Printf("Client connected. Total clients: %d", len(h.clients))
 
 		case client := <-h.unregister:
-			h.mu.Lock()
-			if _, ok := h.clients[client]; ok {
-				delete(h.clients, client)
-				close(client.send)
-			}
-			h.mu.Unlock()
+			h.removeClient(client)
 			log.Printf("Client disconnected. Total clients: %d", len(h.clients))
 
-		case message := <-h.broadcast:
-			h.mu.RLock()
-			for client := range h.clients {
-				select {
-				case client.send <- message:
-				default:
-					close(client.send)
-					delete(h.clients, client)
-				}
+		case sub := <-h.subscriptions:
+			h.applySubscription(sub)
+
+		case a := <-h.attach:
+			h.attachStream(a)
+
+		case out := <-h.broadcast:
+			h.route(out)
+		}
+	}
+}
+
+// route numbers a message, keeps it for replay and hands it to every client
+// subscribed to one of its topics
+func (h *Hub) route(out outbound) {
+	h.seq++
+	f := frame{id: h.messageID(h.seq), message: out.message}
+	h.replay.add(h.seq, out, f)
+
+	for client := range h.subscribersOf(out.topics) {
+		if out.coalesceKey != "" {
+			if client.offerLatest(out.coalesceKey, f) {
+				h.coalesced.Add(1)
 			}
-			h.mu.RUnlock()
+			h.delivered.Add(1)
+			continue
 		}
+		h.sendTo(client, f)
 	}
 }
 
-func (h *Hub) BroadcastAnalyticsUpdate(totalVisits int64) {
-	h.mu.RLock()
-	clientCount := len(h.clients)
-	h.mu.RUnlock()
+// sendTo queues a message for one client without blocking the hub, applying
+// the slow client policy when its buffer is full
+func (h *Hub) sendTo(client *Client, f frame) {
+	select {
+	case client.send <- f:
+		h.delivered.Add(1)
+	default:
+		if h.opts.SlowClientPolicy == SlowClientDisconnect {
+			h.disconnected.Add(1)
+			log.Printf("⚠️ Disconnecting slow client after %d queued messages", len(client.send))
+			h.removeClient(client)
+			return
+		}
+		h.dropped.Add(1)
+	}
+}
 
-	if clientCount == 0 {
-		log.Println("No clients connected, skipping broadcast")
+// removeClient drops a client and its subscriptions and closes its send channel
+func (h *Hub) removeClient(client *Client) {
+	if !h.clients[client] {
 		return
 	}
+	delete(h.clients, client)
+	h.clientCount.Add(-1)
+	for topic := range client.topics {
+		h.dropSubscriber(topic, client)
+	}
+	close(client.send)
+}
 
-	update := AnalyticsUpdate{
-		Type:        "analytics_update",
-		TotalVisits: totalVisits,
+func (h *Hub) dropSubscriber(topic string, client *Client) {
+	delete(h.topics[topic], client)
+	if len(h.topics[topic]) == 0 {
+		delete(h.topics, topic)
 	}
+}
+
+// subscribersOf returns every client subscribed to at least one of the topics,
+// so a message published to several topics reaches each client once
+func (h *Hub) subscribersOf(topics []string) map[*Client]bool {
+	subscribers := make(map[*Client]bool)
+	for _, topic := range topics {
+		for client := range h.topics[topic] {
+			subscribers[client] = true
+		}
+	}
+	return subscribers
+}
 
-	message, err := json.Marshal(update)
+// applySubscription applies a client request and acknowledges it
+func (h *Hub) applySubscription(sub subscription) {
+	client, req := sub.client, sub.request
+	if !h.clients[client] {
+		return
+	}
+
+	if sub.err != nil {
+		h.reply(client, MessageError, ErrorReply{ID: req.ID, Error: sub.err.Error()})
+		return
+	}
+	if len(req.Topics) == 0 {
+		h.reply(client, MessageError, ErrorReply{ID: req.ID, Error: "no topics given"})
+		return
+	}
+	for _, topic := range req.Topics {
+		if err := validateTopic(topic); err != nil {
+			h.reply(client, MessageError, ErrorReply{ID: req.ID, Error: err.Error()})
+			return
+		}
+	}
+
+	switch req.Action {
+	case ActionSubscribe:
+		added := 0
+		for _, topic := range req.Topics {
+			if !client.topics[topic] {
+				added++
+			}
+		}
+		if len(client.topics)+added > maxTopicsPerClient {
+			h.reply(client, MessageError, ErrorReply{
+				ID:    req.ID,
+				Error: fmt.Sprintf("at most %d topics per connection", maxTopicsPerClient),
+			})
+			return
+		}
+		for _, topic := range req.Topics {
+			client.topics[topic] = true
+			if h.topics[topic] == nil {
+				h.topics[topic] = make(map[*Client]bool)
+			}
+			h.topics[topic][client] = true
+		}
+		h.reply(client, MessageSubscribed, SubscriptionAck{ID: req.ID, Topics: client.subscribedTopics()})
+
+	case ActionUnsubscribe:
+		for _, topic := range req.Topics {
+			delete(client.topics, topic)
+			h.dropSubscriber(topic, client)
+		}
+		h.reply(client, MessageUnsubscribed, SubscriptionAck{ID: req.ID, Topics: client.subscribedTopics()})
+
+	default:
+		h.reply(client, MessageError, ErrorReply{ID: req.ID, Error: fmt.Sprintf("unknown action %q", req.Action)})
+	}
+}
+
+// reply sends a message to one client
+func (h *Hub) reply(client *Client, messageType string, data interface{}) {
+	message, err := json.Marshal(Envelope{
+		Version:   EnvelopeVersion,
+		Type:      messageType,
+		Data:      data,
+		Timestamp: time.Now(),
+	})
 	if err != nil {
-		log.Printf("Error marshaling analytics update: %v", err)
+		log.Printf("Error marshaling %s reply: %v", messageType, err)
 		return
 	}
+	h.sendTo(client, frame{message: message})
+}
 
-	log.Printf("Broadcasting message to %d clients: %s", clientCount, string(message))
+func (c *Client) subscribedTopics() []string {
+	topics := make([]string, 0, len(c.topics))
+	for topic := range c.topics {
+		topics = append(topics, topic)
+	}
+	sort.Strings(topics)
+	return topics
+}
+
+// offerLatest replaces the pending message for key and wakes writePump. It
+// reports whether an unwritten message was superseded.
+func (c *Client) offerLatest(key string, f frame) bool {
+	c.latestMu.Lock()
+	_, superseded := c.latest[key]
+	c.latest[key] = f
+	c.latestMu.Unlock()
 
 	select {
-	case h.broadcast <- message:
-		log.Printf("Message queued for broadcast")
+	case c.wake <- struct{}{}:
 	default:
-		log.Println("Broadcast channel is full, dropping message")
 	}
+	return superseded
+}
+
+// takeLatest returns the pending coalesced messages and clears them
+func (c *Client) takeLatest() []frame {
+	c.latestMu.Lock()
+	defer c.latestMu.Unlock()
+
+	frames := make([]frame, 0, len(c.latest))
+	for key, f := range c.latest {
+		frames = append(frames, f)
+		delete(c.latest, key)
+	}
+	return frames
+}
+
+// Publish sends a message of the given type to the clients of every replica
+// that are subscribed to any of the topics
+func (h *Hub) Publish(topics []string, messageType string, data interface{}) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("Error marshaling %s message: %v", messageType, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+	defer cancel()
+
+	msg := Message{Type: messageType, Topics: topics, Data: raw, Timestamp: time.Now()}
+	if err := h.bus.Publish(ctx, msg); err != nil {
+		// Clients of this replica still get it
+		h.publishFailures.Add(1)
+		log.Printf("Failed to publish %s message on the %s bus: %v", messageType, h.bus.Name(), err)
+		h.deliver(msg)
+	}
+}
+
+// consume hands the messages arriving on the bus to the clients of this replica
+func (h *Hub) consume() {
+	log.Printf("📡 Hub subscribed to the %s bus", h.bus.Name())
+	for {
+		err := h.bus.Subscribe(context.Background(), h.deliver)
+		log.Printf("⚠️ %s bus subscription ended, resubscribing in %s: %v", h.bus.Name(), busRetryDelay, err)
+		time.Sleep(busRetryDelay)
+	}
+}
+
+// deliver queues a message for the local clients subscribed to its topics. It
+// waits while the broadcast buffer is full, holding back the bus rather than
+// dropping messages for every client. Messages are routed even without
+// clients, so that event streams resuming later can replay them.
+func (h *Hub) deliver(msg Message) {
+	message, err := json.Marshal(Envelope{
+		Version:   EnvelopeVersion,
+		Type:      msg.Type,
+		Topics:    msg.Topics,
+		Data:      msg.Data,
+		Timestamp: msg.Timestamp,
+	})
+	if err != nil {
+		log.Printf("Error marshaling %s message: %v", msg.Type, err)
+		return
+	}
+
+	out := outbound{topics: msg.Topics, message: message}
+	if coalesced[msg.Type] {
+		out.coalesceKey = msg.Type
+	}
+	h.broadcast <- out
+}
+
+func (h *Hub) BroadcastAnalyticsUpdate(totalVisits int64) {
+	log.Printf("Broadcasting analytics update to subscribers: total_visits=%d", totalVisits)
+	h.Publish([]string{TopicAnalytics}, MessageAnalyticsUpdate, AnalyticsUpdate{TotalVisits: totalVisits})
 }
 
 func (h *Hub) GetClientCount() int {
-	h.mu.RLock()
-	defer h.mu.RUnlock()
-	return len(h.clients)
+	return int(h.clientCount.Load())
+}
+
+// Stats returns the hub's counters
+func (h *Hub) Stats() HubStats {
+	return HubStats{
+		Clients:         h.clientCount.Load(),
+		Delivered:       h.delivered.Load(),
+		Coalesced:       h.coalesced.Load(),
+		Dropped:         h.dropped.Load(),
+		Disconnected:    h.disconnected.Load(),
+		PublishFailures: h.publishFailures.Load(),
+	}
 }
@@ -0,0 +1,261 @@
+package websocket
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+)
+
+// connectMany connects n fake clients concurrently, each subscribed to topics
+func connectMany(t *testing.T, hub *Hub, n int, topics ...string) []*Client {
+	t.Helper()
+	clients := make([]*Client, n)
+	var wg sync.WaitGroup
+	for i := range clients {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			client := hub.newClient(nil)
+			hub.register <- client
+			hub.subscriptions <- subscription{client: client, request: ClientRequest{Action: ActionSubscribe, Topics: topics}}
+			select {
+			case <-client.send:
+			case <-time.After(testWait):
+				t.Errorf("client %d was not acknowledged", i)
+			}
+			clients[i] = client
+		}(i)
+	}
+	wg.Wait()
+	if t.Failed() {
+		t.FailNow()
+	}
+	return clients
+}
+
+// waitForStats polls the hub's counters until done accepts them
+func waitForStats(t *testing.T, hub *Hub, done func(HubStats) bool) HubStats {
+	t.Helper()
+	deadline := time.Now().Add(testWait)
+	for {
+		stats := hub.Stats()
+		if done(stats) {
+			return stats
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("hub stats stuck at %+v", stats)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestHubFanOutByTopic(t *testing.T) {
+	const perCompany = 1500
+
+	hub := startHub(t, NewMemoryBus(), HubOptions{})
+	reliance := connectMany(t, hub, perCompany, CompanyTopic(500325))
+	tcs := connectMany(t, hub, perCompany, CompanyTopic(532540))
+	everything := connectMany(t, hub, perCompany, TopicIngestion, CompanyTopic(500325))
+	if got := hub.GetClientCount(); got != 3*perCompany {
+		t.Fatalf("GetClientCount() = %d, want %d", got, 3*perCompany)
+	}
+	before := hub.Stats().Delivered
+
+	hub.Publish(IngestionTopics(500325), EventAnnouncementDownloaded, AnnouncementDownloaded{ScripCode: 500325})
+	hub.Publish(IngestionTopics(532540), EventAnnouncementDownloaded, AnnouncementDownloaded{ScripCode: 532540})
+
+	var wg sync.WaitGroup
+	check := func(clients []*Client, want ...int) {
+		for _, client := range clients {
+			wg.Add(1)
+			go func(client *Client) {
+				defer wg.Done()
+				for _, code := range want {
+					select {
+					case f := <-client.send:
+						var env struct {
+							Data AnnouncementDownloaded `json:"data"`
+						}
+						if err := json.Unmarshal(f.message, &env); err != nil || env.Data.ScripCode != code {
+							t.Errorf("got %s, want the event of %d", f.message, code)
+						}
+					case <-time.After(testWait):
+						t.Errorf("event of %d never arrived", code)
+						return
+					}
+				}
+				if extra := len(client.send); extra != 0 {
+					t.Errorf("%d unexpected messages queued", extra)
+				}
+			}(client)
+		}
+	}
+	check(reliance, 500325)
+	check(tcs, 532540)
+	// Subscribed to both topics of the first event, it still arrives once
+	check(everything, 500325, 532540)
+	wg.Wait()
+
+	if got := hub.Stats().Delivered - before; got != 4*perCompany {
+		t.Errorf("Delivered = %d, want %d", got, 4*perCompany)
+	}
+}
+
+func TestHubCoalescesAnalyticsUpdates(t *testing.T) {
+	const clients, updates = 2000, 50
+
+	hub := startHub(t, NewMemoryBus(), HubOptions{})
+	subscribed := connectMany(t, hub, clients, TopicAnalytics)
+	before := hub.Stats()
+
+	for visits := int64(1); visits <= updates; visits++ {
+		hub.BroadcastAnalyticsUpdate(visits)
+	}
+	stats := waitForStats(t, hub, func(s HubStats) bool {
+		return s.Delivered-before.Delivered == clients*updates
+	})
+
+	// Nobody wrote anything, so every update but the newest was replaced
+	if got := stats.Coalesced - before.Coalesced; got != clients*(updates-1) {
+		t.Errorf("Coalesced = %d, want %d", got, clients*(updates-1))
+	}
+	if stats.Dropped != before.Dropped {
+		t.Errorf("Dropped = %d, coalesced messages must not fill the buffer", stats.Dropped-before.Dropped)
+	}
+	for _, client := range subscribed {
+		frames := client.takeLatest()
+		if len(frames) != 1 {
+			t.Fatalf("client holds %d pending analytics updates, want 1", len(frames))
+		}
+		var env struct {
+			Data AnalyticsUpdate `json:"data"`
+		}
+		if err := json.Unmarshal(frames[0].message, &env); err != nil || env.Data.TotalVisits != updates {
+			t.Fatalf("pending update %s, want total_visits %d", frames[0].message, updates)
+		}
+		if len(client.send) != 0 {
+			t.Fatalf("analytics updates were queued instead of coalesced")
+		}
+	}
+}
+
+func TestHubDropsForSlowClients(t *testing.T) {
+	const clients, buffer, published = 1000, 4, 10
+
+	hub := startHub(t, NewMemoryBus(), HubOptions{ClientBuffer: buffer, SlowClientPolicy: SlowClientDrop})
+	slow := connectMany(t, hub, clients, TopicIngestion)
+	before := hub.Stats()
+
+	for i := 0; i < published; i++ {
+		hub.Publish([]string{TopicIngestion}, EventRunStarted, RunStarted{Total: i})
+	}
+	stats := waitForStats(t, hub, func(s HubStats) bool {
+		return (s.Delivered-before.Delivered)+(s.Dropped-before.Dropped) == clients*published
+	})
+
+	if got := stats.Delivered - before.Delivered; got != clients*buffer {
+		t.Errorf("Delivered = %d, want %d", got, clients*buffer)
+	}
+	if got := stats.Dropped - before.Dropped; got != clients*(published-buffer) {
+		t.Errorf("Dropped = %d, want %d", got, clients*(published-buffer))
+	}
+	if stats.Disconnected != 0 || stats.Clients != clients {
+		t.Errorf("Disconnected = %d, Clients = %d, want 0 and %d", stats.Disconnected, stats.Clients, clients)
+	}
+
+	// A client that catches up gets the oldest messages and new ones again
+	client := slow[0]
+	for i := 0; i < buffer; i++ {
+		var env struct {
+			Data RunStarted `json:"data"`
+		}
+		if err := json.Unmarshal((<-client.send).message, &env); err != nil || env.Data.Total != i {
+			t.Fatalf("message %d is not the %d-th published", env.Data.Total, i)
+		}
+	}
+	hub.Publish([]string{TopicIngestion}, EventRunFinished, RunFinished{})
+	if env := receive(t, client); env.Type != EventRunFinished {
+		t.Errorf("caught up client got %s, want %s", env.Type, EventRunFinished)
+	}
+}
+
+func TestHubDisconnectsSlowClients(t *testing.T) {
+	const clients, buffer = 1000, 4
+
+	hub := startHub(t, NewMemoryBus(), HubOptions{ClientBuffer: buffer, SlowClientPolicy: SlowClientDisconnect})
+	slow := connectMany(t, hub, clients, TopicIngestion)
+	bystanders := connectMany(t, hub, clients, TopicAnalytics)
+	before := hub.Stats()
+
+	for i := 0; i <= buffer; i++ {
+		hub.Publish([]string{TopicIngestion}, EventRunStarted, RunStarted{Total: i})
+	}
+	stats := waitForStats(t, hub, func(s HubStats) bool {
+		return s.Disconnected-before.Disconnected == clients
+	})
+
+	if got := stats.Delivered - before.Delivered; got != clients*buffer {
+		t.Errorf("Delivered = %d, want %d", got, clients*buffer)
+	}
+	if stats.Dropped != before.Dropped {
+		t.Errorf("Dropped = %d, want none under the disconnect policy", stats.Dropped-before.Dropped)
+	}
+	if stats.Clients != clients {
+		t.Errorf("Clients = %d, want the %d bystanders", stats.Clients, clients)
+	}
+
+	// Disconnected clients get what was queued, then their channel is closed
+	for _, client := range slow {
+		for i := 0; i < buffer; i++ {
+			if _, ok := <-client.send; !ok {
+				t.Fatalf("send closed after %d of %d queued messages", i, buffer)
+			}
+		}
+		if _, ok := <-client.send; ok {
+			t.Fatal("slow client's send channel is still open")
+		}
+	}
+
+	// Later messages on the topic neither reach nor count the removed clients
+	hub.Publish([]string{TopicIngestion}, EventRunFinished, RunFinished{})
+	hub.BroadcastAnalyticsUpdate(1)
+	waitForStats(t, hub, func(s HubStats) bool {
+		return s.Delivered-stats.Delivered == clients
+	})
+	for _, client := range bystanders[:10] {
+		if envs := receiveLatest(t, client); len(envs) != 1 || envs[0].Type != MessageAnalyticsUpdate {
+			t.Errorf("bystander got %+v, want one analytics update", envs)
+		}
+	}
+	if got := hub.Stats().Disconnected - before.Disconnected; got != clients {
+		t.Errorf("Disconnected = %d after more messages, want %d", got, clients)
+	}
+}
+
+func TestHubUnregisterWhileRouting(t *testing.T) {
+	const clients = 2000
+
+	hub := startHub(t, NewMemoryBus(), HubOptions{ClientBuffer: 1})
+	connected := connectMany(t, hub, clients, TopicIngestion, TopicAnalytics)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			hub.Publish([]string{TopicIngestion}, EventRunStarted, RunStarted{Total: i})
+			hub.BroadcastAnalyticsUpdate(int64(i))
+		}
+	}()
+	for _, client := range connected {
+		wg.Add(1)
+		go func(client *Client) {
+			defer wg.Done()
+			hub.unregister <- client
+		}(client)
+	}
+	wg.Wait()
+
+	waitForStats(t, hub, func(s HubStats) bool { return s.Clients == 0 })
+}
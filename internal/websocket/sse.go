@@ -0,0 +1,264 @@
+package websocket
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"concall-analyser/internal/domain"
+)
+
+const (
+	// sseRetry tells EventSource how long to wait before reconnecting
+	sseRetry = 3 * time.Second
+	// sseHeartbeat keeps proxies from closing an idle stream
+	sseHeartbeat = 25 * time.Second
+)
+
+// DefaultStreamTopics are followed by event stream clients that name none
+var DefaultStreamTopics = []string{TopicAnalytics, TopicIngestion}
+
+// attachment registers an event stream client with its topics, replaying what
+// it missed since lastEventID; done is closed once the replay is queued
+type attachment struct {
+	client      *Client
+	topics      []string
+	lastEventID string
+	done        chan struct{}
+}
+
+// replayEntry is a routed message kept for clients resuming a stream
+type replayEntry struct {
+	seq    uint64
+	topics []string
+	frame  frame
+}
+
+// replayBuffer keeps the last messages routed by the hub. Coalesced messages
+// are kept apart, newest per key, so visit counts do not push out the rest.
+type replayBuffer struct {
+	entries []replayEntry
+	next    int
+	full    bool
+	// evicted is the seq of the newest entry that fell out of the buffer
+	evicted uint64
+	latest  map[string]replayEntry
+}
+
+func newReplayBuffer(size int) *replayBuffer {
+	return &replayBuffer{
+		entries: make([]replayEntry, size),
+		latest:  make(map[string]replayEntry),
+	}
+}
+
+func (b *replayBuffer) add(seq uint64, out outbound, f frame) {
+	entry := replayEntry{seq: seq, topics: out.topics, frame: f}
+	if out.coalesceKey != "" {
+		b.latest[out.coalesceKey] = entry
+		return
+	}
+
+	if b.full {
+		b.evicted = b.entries[b.next].seq
+	}
+	b.entries[b.next] = entry
+	b.next = (b.next + 1) % len(b.entries)
+	if b.next == 0 {
+		b.full = true
+	}
+}
+
+// since returns the messages after seq on any of the topics, oldest first.
+// complete is false when some of them already fell out of the buffer.
+func (b *replayBuffer) since(seq uint64, topics map[string]bool) (entries []replayEntry, complete bool) {
+	matches := func(e replayEntry) bool {
+		if e.seq <= seq {
+			return false
+		}
+		for _, topic := range e.topics {
+			if topics[topic] {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, e := range b.entries {
+		if e.seq != 0 && matches(e) {
+			entries = append(entries, e)
+		}
+	}
+	for _, e := range b.latest {
+		if matches(e) {
+			entries = append(entries, e)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].seq < entries[j].seq })
+
+	return entries, seq >= b.evicted
+}
+
+// messageID is the event ID of the seq-th message routed by this hub
+func (h *Hub) messageID(seq uint64) string {
+	return h.epoch + "-" + strconv.FormatUint(seq, 10)
+}
+
+// parseMessageID returns the seq of an event ID issued by this hub
+func (h *Hub) parseMessageID(id string) (uint64, bool) {
+	epoch, seq, ok := strings.Cut(id, "-")
+	if !ok || epoch != h.epoch {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(seq, 10, 64)
+	if err != nil || n > h.seq {
+		return 0, false
+	}
+	return n, true
+}
+
+// attachStream registers an event stream client and queues the messages it
+// missed. A client whose last event is unknown or no longer buffered is told
+// to resync, i.e. to reload its state, instead.
+func (h *Hub) attachStream(a attachment) {
+	defer close(a.done)
+
+	client := a.client
+	h.clients[client] = true
+	h.clientCount.Add(1)
+	for _, topic := range a.topics {
+		client.topics[topic] = true
+		if h.topics[topic] == nil {
+			h.topics[topic] = make(map[*Client]bool)
+		}
+		h.topics[topic][client] = true
+	}
+
+	if a.lastEventID == "" {
+		return
+	}
+
+	seq, known := h.parseMessageID(a.lastEventID)
+	var missed []replayEntry
+	if known {
+		missed, known = h.replay.since(seq, client.topics)
+	}
+	if !known {
+		message, err := json.Marshal(Envelope{Version: EnvelopeVersion, Type: MessageResync, Timestamp: time.Now()})
+		if err == nil {
+			h.sendTo(client, frame{message: message})
+		}
+		return
+	}
+
+	for _, e := range missed {
+		h.sendTo(client, e.frame)
+	}
+	if len(missed) > 0 {
+		log.Printf("⏪ Replayed %d messages to a resuming event stream", len(missed))
+	}
+}
+
+// ServeSSE streams the messages on topics to an EventSource client
+// authenticated as principal until it goes away, first replaying those after
+// lastEventID. It returns an error without writing anything when the request
+// cannot be served.
+func ServeSSE(hub *Hub, w http.ResponseWriter, r *http.Request, principal *domain.Principal, topics []string, lastEventID string) error {
+	if len(topics) == 0 {
+		topics = DefaultStreamTopics
+	}
+	if len(topics) > maxTopicsPerClient {
+		return fmt.Errorf("at most %d topics per stream", maxTopicsPerClient)
+	}
+	for _, topic := range topics {
+		if err := validateTopic(topic); err != nil {
+			return err
+		}
+	}
+	if err := hub.authorizeTopics(r.Context(), principal, topics); err != nil {
+		return err
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return errors.New("streaming is not supported by this connection")
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "retry: %d\n\n", sseRetry.Milliseconds())
+	flusher.Flush()
+
+	// Room for a full replay on top of the usual queue
+	client := hub.newClient(nil)
+	client.send = make(chan frame, hub.opts.ClientBuffer+hub.opts.ReplayBuffer)
+
+	done := make(chan struct{})
+	hub.attach <- attachment{client: client, topics: topics, lastEventID: lastEventID, done: done}
+	<-done
+	defer func() {
+		hub.unregister <- client
+	}()
+
+	heartbeat := time.NewTicker(sseHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return nil
+
+		case f, ok := <-client.send:
+			if !ok {
+				// Dropped by the hub as a slow client
+				return nil
+			}
+			if err := writeEvent(w, f); err != nil {
+				return nil
+			}
+			for n := len(client.send); n > 0; n-- {
+				if err := writeEvent(w, <-client.send); err != nil {
+					return nil
+				}
+			}
+			flusher.Flush()
+
+		case <-client.wake:
+			for _, f := range client.takeLatest() {
+				if err := writeEvent(w, f); err != nil {
+					return nil
+				}
+			}
+			flusher.Flush()
+
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return nil
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// writeEvent writes one frame in the text/event-stream format
+func writeEvent(w http.ResponseWriter, f frame) error {
+	if f.message == nil {
+		return nil
+	}
+	if f.id != "" {
+		if _, err := fmt.Fprintf(w, "id: %s\n", f.id); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "data: %s\n\n", f.message)
+	return err
+}
@@ -0,0 +1,71 @@
+package websocket
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"concall-analyser/internal/domain"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// watchlistLookupTimeout bounds finding the owner of a watchlist a client subscribes to
+const watchlistLookupTimeout = 5 * time.Second
+
+// WatchlistFinder looks up the watchlists behind watchlist topics;
+// domain.WatchlistRepository satisfies it
+type WatchlistFinder interface {
+	FindByID(ctx context.Context, id primitive.ObjectID) (*domain.Watchlist, error)
+}
+
+// authorizeTopics checks that principal may follow every watchlist topic in
+// topics, which only the watchlist's owner and admins may. Other topics are
+// left to validateTopic.
+func (h *Hub) authorizeTopics(ctx context.Context, principal *domain.Principal, topics []string) error {
+	for _, topic := range topics {
+		watchlistID, ok := strings.CutPrefix(topic, watchlistTopicPrefix)
+		if !ok || watchlistID == "" {
+			continue
+		}
+		if err := h.authorizeWatchlist(ctx, principal, watchlistID); err != nil {
+			return fmt.Errorf("topic %q: %w", topic, err)
+		}
+	}
+	return nil
+}
+
+func (h *Hub) authorizeWatchlist(ctx context.Context, principal *domain.Principal, watchlistID string) error {
+	if principal == nil || principal.Method == "anonymous" {
+		return errors.New("watchlist topics require logging in")
+	}
+	if principal.Role.Allows(domain.RoleAdmin) {
+		return nil
+	}
+	if h.opts.Watchlists == nil {
+		return errors.New("watchlist topics are not available")
+	}
+
+	id, err := primitive.ObjectIDFromHex(watchlistID)
+	if err != nil {
+		return errors.New("invalid watchlist id")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, watchlistLookupTimeout)
+	defer cancel()
+
+	watchlist, err := h.opts.Watchlists.FindByID(ctx, id)
+	if errors.Is(err, domain.ErrWatchlistNotFound) {
+		return domain.ErrWatchlistNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to fetch watchlist: %w", err)
+	}
+	if watchlist.Owner != principal.Subject {
+		// Not telling other users which watchlists exist
+		return domain.ErrWatchlistNotFound
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package websocket
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"concall-analyser/internal/domain"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// fakeWatchlists finds watchlists by ID from a map
+type fakeWatchlists map[primitive.ObjectID]*domain.Watchlist
+
+func (f fakeWatchlists) FindByID(ctx context.Context, id primitive.ObjectID) (*domain.Watchlist, error) {
+	if watchlist, ok := f[id]; ok {
+		return watchlist, nil
+	}
+	return nil, domain.ErrWatchlistNotFound
+}
+
+// connectAs registers a fake client authenticated as principal
+func connectAs(hub *Hub, principal *domain.Principal) *Client {
+	client := hub.newClient(nil)
+	client.principal = principal
+	hub.register <- client
+	return client
+}
+
+// subscribeRaw sends a subscribe request the way readPump does and returns the reply
+func subscribeRaw(t *testing.T, client *Client, topic string) receivedEnvelope {
+	t.Helper()
+	data := fmt.Sprintf(`{"action": %q, "topics": [%q], "id": "1"}`, ActionSubscribe, topic)
+	client.hub.subscriptions <- client.request([]byte(data))
+	return receive(t, client)
+}
+
+func TestWatchlistTopicOwnership(t *testing.T) {
+	alicesID := primitive.NewObjectID()
+	alices := WatchlistTopic(alicesID.Hex())
+	watchlists := fakeWatchlists{alicesID: {ID: alicesID, Owner: "alice@example.com"}}
+	hub := startHub(t, NewMemoryBus(), HubOptions{Watchlists: watchlists})
+
+	alice := &domain.Principal{Subject: "alice@example.com", Role: domain.RoleViewer, Method: "session"}
+	bob := &domain.Principal{Subject: "bob@example.com", Role: domain.RoleAnalyst, Method: "session"}
+	admin := &domain.Principal{Subject: "root@example.com", Role: domain.RoleAdmin, Method: "session"}
+	anonymous := &domain.Principal{Subject: "anonymous", Role: domain.RoleAdmin, Method: "anonymous"}
+
+	tests := []struct {
+		name      string
+		principal *domain.Principal
+		topic     string
+		allowed   bool
+	}{
+		{name: "owner", principal: alice, topic: alices, allowed: true},
+		{name: "admin", principal: admin, topic: alices, allowed: true},
+		{name: "other user", principal: bob, topic: alices, allowed: false},
+		{name: "anonymous", principal: anonymous, topic: alices, allowed: false},
+		{name: "unauthenticated", principal: nil, topic: alices, allowed: false},
+		{name: "unknown watchlist", principal: alice, topic: WatchlistTopic(primitive.NewObjectID().Hex()), allowed: false},
+		{name: "malformed watchlist id", principal: alice, topic: WatchlistTopic("not-an-id"), allowed: false},
+		{name: "other topics", principal: bob, topic: CompanyTopic(500325), allowed: true},
+	}
+
+	clients := make(map[string]*Client)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := connectAs(hub, tt.principal)
+			clients[tt.name] = client
+
+			env := subscribeRaw(t, client, tt.topic)
+			if tt.allowed && env.Type != MessageSubscribed {
+				t.Fatalf("reply = %s %s, want %s", env.Type, env.Data, MessageSubscribed)
+			}
+			if !tt.allowed {
+				if env.Type != MessageError {
+					t.Fatalf("reply = %s, want %s", env.Type, MessageError)
+				}
+				if !strings.Contains(string(env.Data), `"id":"1"`) {
+					t.Errorf("error reply %s does not echo the request id", env.Data)
+				}
+			}
+		})
+	}
+
+	hub.Publish([]string{alices}, EventAnnouncementDownloaded, AnnouncementDownloaded{ScripCode: 500325})
+	for _, name := range []string{"owner", "admin"} {
+		if env := receive(t, clients[name]); env.Type != EventAnnouncementDownloaded {
+			t.Errorf("%s got %s, want %s", name, env.Type, EventAnnouncementDownloaded)
+		}
+	}
+	for _, name := range []string{"other user", "anonymous", "unauthenticated"} {
+		expectSilence(t, clients[name])
+	}
+}
+
+func TestWatchlistTopicsWithoutFinder(t *testing.T) {
+	hub := startHub(t, NewMemoryBus(), HubOptions{})
+	topic := WatchlistTopic(primitive.NewObjectID().Hex())
+
+	viewer := connectAs(hub, &domain.Principal{Subject: "alice@example.com", Role: domain.RoleViewer, Method: "session"})
+	if env := subscribeRaw(t, viewer, topic); env.Type != MessageError {
+		t.Errorf("viewer reply = %s, want %s", env.Type, MessageError)
+	}
+	admin := connectAs(hub, &domain.Principal{Subject: "ops", Role: domain.RoleAdmin, Method: "api_key"})
+	if env := subscribeRaw(t, admin, topic); env.Type != MessageSubscribed {
+		t.Errorf("admin reply = %s, want %s", env.Type, MessageSubscribed)
+	}
+}
+
+func TestServeSSEChecksWatchlistOwner(t *testing.T) {
+	watchlistID := primitive.NewObjectID()
+	topic := WatchlistTopic(watchlistID.Hex())
+	watchlists := fakeWatchlists{watchlistID: {ID: watchlistID, Owner: "alice@example.com"}}
+	hub := startHub(t, NewMemoryBus(), HubOptions{Watchlists: watchlists})
+
+	bob := &domain.Principal{Subject: "bob@example.com", Role: domain.RoleViewer, Method: "session"}
+	rec := httptest.NewRecorder()
+	err := ServeSSE(hub, rec, httptest.NewRequest("GET", "/api/events", nil), bob, []string{TopicIngestion, topic}, "")
+	if err == nil {
+		t.Fatal("ServeSSE() for another user's watchlist error = nil")
+	}
+	if rec.Body.Len() != 0 {
+		t.Errorf("rejected stream wrote %q", rec.Body.String())
+	}
+
+	// The owner's stream opens and ends with its request
+	alice := &domain.Principal{Subject: "alice@example.com", Role: domain.RoleViewer, Method: "session"}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	rec = httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/events", nil).WithContext(ctx)
+	if err := ServeSSE(hub, rec, req, alice, []string{topic}, ""); err != nil {
+		t.Fatalf("ServeSSE() for the owner error = %v", err)
+	}
+	if !strings.HasPrefix(rec.Body.String(), "retry:") {
+		t.Errorf("owner's stream wrote %q, want it to start with retry:", rec.Body.String())
+	}
+}